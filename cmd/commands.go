@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Usage de la ligne de commande
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage :
+  waitify-api [--config fichier.yaml|fichier.toml]                   Lancer le serveur
+  waitify-api [--config fichier.yaml|fichier.toml] config check      Afficher la configuration effective (secrets masqués)
+  waitify-api [--config fichier.yaml|fichier.toml] migrate           Appliquer les migrations en attente
+  waitify-api [--config fichier.yaml|fichier.toml] migrate status    Lister les migrations en attente
+  waitify-api [--config fichier.yaml|fichier.toml] migrate baseline  Marquer le schéma initial comme appliqué (base créée à la main)`)
+}
+
+// `config check` : afficher la configuration effective, code de sortie 1 si elle est invalide
+func configCheck(path string) int {
+	cfg, err := config.Read(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(string(out))
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintln(os.Stderr, "[config check] -> Configuration valide.")
+	return 0
+}
+
+/*
+`migrate [status|baseline]` : gestion du schéma de la base de données
+Code de sortie 1 en cas d'erreur (et pour `status` s'il reste des migrations à appliquer)
+*/
+func migrate(path string, args []string) int {
+	action := ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+	if len(args) > 1 || (action != "" && action != "status" && action != "baseline") {
+		usage()
+		return 2
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := utils.InitLogger(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := database.InitDB(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.CloseDB()
+
+	ctx := context.Background()
+	switch action {
+	case "status":
+		pending, err := database.PendingMigrations(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, migration := range pending {
+			fmt.Printf("%04d_%s\n", migration.Version, migration.Name)
+		}
+		if len(pending) > 0 {
+			fmt.Fprintf(os.Stderr, "[migrate status] -> %d migration(s) en attente.\n", len(pending))
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "[migrate status] -> Base de données à jour.")
+	case "baseline":
+		if err := database.Baseline(ctx, database.BaselineVersion); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "[migrate baseline] -> Migrations jusqu'à %04d marquées comme appliquées.\n", database.BaselineVersion)
+	default:
+		applied, err := database.Migrate(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "[migrate] -> %d migration(s) appliquée(s).\n", len(applied))
+	}
+	return 0
+}
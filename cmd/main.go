@@ -1,66 +1,321 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/StevenYAMBOS/waitify-api/internal/config"
 	"github.com/StevenYAMBOS/waitify-api/internal/database"
 	"github.com/StevenYAMBOS/waitify-api/internal/handlers"
+	"github.com/StevenYAMBOS/waitify-api/internal/mailer"
 	"github.com/StevenYAMBOS/waitify-api/internal/middlewares"
 	"github.com/StevenYAMBOS/waitify-api/internal/models"
-
-	// "github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
+	"github.com/StevenYAMBOS/waitify-api/internal/telemetry"
 	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/StevenYAMBOS/waitify-api/internal/workers"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
-	// Variables d'environnement
-	cfg, err := config.Load()
+	configPath := flag.String("config", "", "Fichier de configuration YAML ou TOML (optionnel)")
+	flag.Usage = usage
+	flag.Parse()
+
+	// Sous-commandes
+	switch args := flag.Args(); {
+	case len(args) == 2 && args[0] == "config" && args[1] == "check":
+		os.Exit(configCheck(*configPath))
+	case len(args) >= 1 && args[0] == "migrate":
+		os.Exit(migrate(*configPath, args[1:]))
+	case len(args) > 0:
+		usage()
+		os.Exit(2)
+	}
+
+	// Configuration (chargée une seule fois puis injectée)
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatal(`[main.go] -> Erreur lors du chargement des variables d'environnements.`, err)
+		fatal("Erreur lors du chargement de la configuration", err)
+	}
+
+	// Logs structurés
+	if err := utils.InitLogger(cfg); err != nil {
+		fatal("Erreur lors de l'initialisation des logs", err)
+	}
+
+	// Traces OpenTelemetry (avant la base de données pour instrumenter les requêtes SQL)
+	shutdownTracing, err := telemetry.InitTracing(context.Background(), cfg)
+	if err != nil {
+		fatal("Erreur lors de l'initialisation des traces", err)
 	}
 
 	// Initialisation base de données
-	database.InitDB()
+	if err := database.InitDB(cfg); err != nil {
+		fatal("Erreur lors de l'initialisation de la base de données", err)
+	}
+
+	// Migrations : appliquées au démarrage si demandé, sinon /readyz reste en échec tant qu'il en manque
+	if cfg.Database.AutoMigrate {
+		if _, err := database.Migrate(context.Background()); err != nil {
+			fatal("Erreur lors de l'application des migrations", err)
+		}
+	} else if pending, err := database.PendingMigrations(context.Background()); err != nil {
+		slog.Warn("Impossible de vérifier les migrations", "error", err)
+	} else if len(pending) > 0 {
+		slog.Warn("Migrations en attente, lancer `waitify-api migrate`", "pending", len(pending))
+	}
+
+	// Métriques Prometheus
+	if cfg.Metrics.Enabled {
+		if err := telemetry.RegisterDBMetrics(database.DB); err != nil {
+			fatal("Erreur lors de l'initialisation des métriques", err)
+		}
+	}
 
 	// Initialisation du JWT
-	utils.InitJWT()
+	utils.InitJWT(cfg)
 
 	// Port
 	port := cfg.Server.Port
 
 	// Initialisation GCP
-	models.GoogleConfig()
+	models.GoogleConfig(cfg)
+
+	// Emails (vérification, mot de passe oublié)
+	if err := mailer.Init(cfg); err != nil {
+		fatal("Erreur lors de l'initialisation des emails", err)
+	}
+	handlers.InitAuthLinks(cfg)
+	handlers.InitUsers(cfg)
+	handlers.InitBusinesses(cfg)
+
+	// Stockage des fichiers envoyés (photos de profil, logos)
+	if err := storage.Init(cfg); err != nil {
+		fatal("Erreur lors de l'initialisation du stockage", err)
+	}
+	handlers.InitUploads(cfg)
+	handlers.InitQRCodes(cfg)
+
+	// Politique de mot de passe et blocage des connexions après plusieurs échecs
+	if err := utils.InitPasswordPolicy(cfg); err != nil {
+		fatal("Erreur lors du chargement de la politique de mot de passe", err)
+	}
+	handlers.InitLoginThrottle(cfg)
+
+	// Chiffrement des secrets de double authentification
+	if err := utils.InitSecretBox(cfg); err != nil {
+		fatal("Erreur lors de l'initialisation du chiffrement des secrets", err)
+	}
 
 	// Routeur
 	r := http.NewServeMux()
 
-	// Health check
-	r.HandleFunc("/", handlers.HealthCheck)
+	// Routes inconnues : 404 (ou 405) au format JSON
+	r.HandleFunc("/", handlers.NotFoundHandler(r))
+
+	// Sondes Kubernetes / load balancer
+	r.HandleFunc("GET /healthz", handlers.HealthzHandler)
+	r.HandleFunc("GET /readyz", handlers.ReadyzHandler)
+	if cfg.Metrics.Enabled {
+		r.Handle("GET "+cfg.Metrics.Path, telemetry.MetricsHandler())
+	}
+
+	// Taille maximum du corps des requêtes, par route
+	jsonBody := middlewares.BodyLimitMiddleware(cfg.Server.MaxJSONBodyBytes)
+	upload := middlewares.BodyLimitMiddleware(cfg.Server.MaxUploadBytes)
+
+	// Idempotency-Key : une requête répétée (réseau instable, double appui) renvoie la réponse de la première
+	idempotent := middlewares.IdempotencyMiddleware(cfg.Idempotency.TTL)
+	// Routes d'authentification et de mot de passe : la requête n'est pas refaite, mais ses tokens ne sont ni conservés ni rejoués
+	processedOnce := middlewares.IdempotencyWithoutReplayMiddleware(cfg.Idempotency.TTL, cfg.JWT.Secret)
+
+	// Authentifié avec un email confirmé (création d'entreprise, QR code, ouverture de la file)
+	verified := func(next http.HandlerFunc) http.HandlerFunc {
+		return middlewares.AuthMiddleware(middlewares.VerifiedEmailMiddleware(middlewares.TwoFactorPolicyMiddleware(next)))
+	}
+
+	// Authentifié, avec la double authentification si l'entreprise {id} l'impose
+	business := func(next http.HandlerFunc) http.HandlerFunc {
+		return middlewares.AuthMiddleware(middlewares.TwoFactorPolicyMiddleware(next))
+	}
+
 	// Routes d'authentification
-	r.HandleFunc("GET /auth/test", middlewares.CORSMiddleware(handlers.TestHandler))
-	r.HandleFunc("GET /auth/google/login", middlewares.CORSMiddleware(handlers.GoogleLoginHandler))
-	r.HandleFunc("GET /auth/google/callback", middlewares.CORSMiddleware(handlers.GoogleCallback))
-	r.HandleFunc("POST /auth/register", middlewares.CORSMiddleware(handlers.RegisterHandler))
-	r.HandleFunc("POST /auth/login", middlewares.CORSMiddleware(handlers.LoginHandler))
+	r.HandleFunc("GET /auth/test", handlers.TestHandler)
+	r.HandleFunc("GET /auth/google/login", handlers.GoogleLoginHandler)
+	r.HandleFunc("GET /auth/google/callback", handlers.GoogleCallback)
+	r.HandleFunc("POST /auth/register", jsonBody(processedOnce(handlers.RegisterHandler)))
+	r.HandleFunc("POST /auth/login", jsonBody(processedOnce(handlers.LoginHandler)))
+	r.HandleFunc("GET /auth/verify", handlers.VerifyEmailHandler)
+	r.HandleFunc("POST /auth/verify/resend", jsonBody(processedOnce(handlers.ResendVerificationHandler)))
+	r.HandleFunc("POST /auth/password/forgot", jsonBody(processedOnce(handlers.ForgotPasswordHandler)))
+	r.HandleFunc("POST /auth/password/reset", jsonBody(processedOnce(handlers.ResetPasswordHandler)))
+	r.HandleFunc("POST /auth/2fa/verify", jsonBody(processedOnce(handlers.TwoFactorVerifyHandler)))
+	r.HandleFunc("POST /auth/2fa/setup", middlewares.AuthMiddleware(processedOnce(handlers.TwoFactorSetupHandler)))
+	r.HandleFunc("POST /auth/2fa/enable", middlewares.AuthMiddleware(jsonBody(processedOnce(handlers.TwoFactorEnableHandler))))
+	r.HandleFunc("POST /auth/2fa/disable", middlewares.AuthMiddleware(jsonBody(processedOnce(handlers.TwoFactorDisableHandler))))
+	r.HandleFunc("POST /auth/2fa/recovery-codes", middlewares.AuthMiddleware(jsonBody(processedOnce(handlers.TwoFactorRecoveryCodesHandler))))
+
+	// Routes utilisateur (GET /user/profile : ancienne route du profil)
+	r.HandleFunc("GET /user/profile", middlewares.AuthMiddleware(handlers.ProfileHandler))
+	r.HandleFunc("GET /users/me", middlewares.AuthMiddleware(handlers.ProfileHandler))
+	r.HandleFunc("PATCH /users/me", middlewares.AuthMiddleware(jsonBody(idempotent(handlers.UpdateProfileHandler))))
+	r.HandleFunc("POST /users/me/password", middlewares.AuthMiddleware(jsonBody(processedOnce(handlers.ChangePasswordHandler))))
+	r.HandleFunc("POST /users/me/avatar", middlewares.AuthMiddleware(upload(idempotent(handlers.UploadAvatarHandler))))
+	r.HandleFunc("DELETE /users/me/avatar", middlewares.AuthMiddleware(idempotent(handlers.DeleteAvatarHandler)))
+	r.HandleFunc("DELETE /users/me", middlewares.AuthMiddleware(jsonBody(processedOnce(handlers.DeleteAccountHandler))))
 
-	// Routes utilisateur
-	r.HandleFunc("GET /user/profile", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.ProfileHandler)))
+	// Fichiers du stockage local (liens signés), inutile avec S3
+	if uploads := handlers.UploadsHandler(); uploads != nil {
+		r.HandleFunc("GET /uploads/{key...}", uploads)
+	}
 
 	// Routes entreprises
-	r.HandleFunc("GET /business/{id}", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.GetBusinessHandler)))
-	r.HandleFunc("GET /businesses/user/{id}", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.GetBusinessesHandler)))
-	r.HandleFunc("POST /business", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.AddBusinessHandler)))
-	r.HandleFunc("POST /business/{id}/qrcode/generate", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.GenerateQRCodeHandler)))
-	r.HandleFunc("PATCH /business/{id}", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.UpdateBusinessHandler)))
-	r.HandleFunc("PUT /businesses/{id}/queue/status", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.ActivateQueueHandler)))
-	r.HandleFunc("DELETE /business/{id}", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.DeleteBusinessHandler)))
+	r.HandleFunc("GET /business-types", handlers.BusinessTypesHandler)
+	r.HandleFunc("GET /business/{id}", business(handlers.GetBusinessHandler))
+	r.HandleFunc("GET /businesses/user/{id}", middlewares.AuthMiddleware(handlers.GetBusinessesHandler))
+	r.HandleFunc("POST /business", verified(upload(idempotent(handlers.AddBusinessHandler))))
+	r.HandleFunc("GET /businesses/{id}/qrcode", verified(handlers.BusinessQRCodeHandler))
+	r.HandleFunc("POST /business/{id}/qrcode/generate", verified(jsonBody(handlers.GenerateQRCodeHandler))) // ancienne route, "content" ignoré
+	r.HandleFunc("GET /businesses/{id}/qrcode/tokens", business(handlers.ListQRTokensHandler))
+	r.HandleFunc("POST /businesses/{id}/qrcode/tokens", verified(jsonBody(idempotent(handlers.CreateQRTokenHandler))))
+	r.HandleFunc("DELETE /businesses/{id}/qrcode/tokens/{token_id}", business(idempotent(handlers.RevokeQRTokenHandler)))
+	r.HandleFunc("POST /businesses/{id}/qrcode/rotate", verified(jsonBody(idempotent(handlers.RotateQRTokenHandler))))
+	r.HandleFunc("PATCH /business/{id}", business(jsonBody(idempotent(handlers.UpdateBusinessHandler))))
+	r.HandleFunc("PUT /businesses/{id}/queue/status", verified(jsonBody(idempotent(handlers.ActivateQueueHandler))))
+	r.HandleFunc("GET /businesses/{id}/queue", business(handlers.QueueDashboardHandler))
+	r.HandleFunc("POST /businesses/{id}/queue/entries/{entry_id}/move", verified(jsonBody(idempotent(handlers.MoveQueueEntryHandler))))
+	r.HandleFunc("POST /businesses/{id}/queue/entries/{entry_id}/move-to-end", verified(jsonBody(idempotent(handlers.MoveQueueEntryToEndHandler))))
+	r.HandleFunc("POST /businesses/{id}/queue/entries/{entry_id}/remove", verified(jsonBody(idempotent(handlers.RemoveQueueEntryHandler))))
+	r.HandleFunc("GET /businesses/{id}/display", business(handlers.GetDisplayTokenHandler))
+	r.HandleFunc("POST /businesses/{id}/display", verified(idempotent(handlers.RotateDisplayTokenHandler)))
+	r.HandleFunc("DELETE /businesses/{id}/display", business(idempotent(handlers.DeleteDisplayTokenHandler)))
+	r.HandleFunc("PUT /businesses/{id}/security", business(jsonBody(idempotent(handlers.BusinessTwoFactorPolicyHandler))))
+	r.HandleFunc("POST /businesses/{id}/logo", business(upload(idempotent(handlers.UploadBusinessLogoHandler))))
+	r.HandleFunc("DELETE /businesses/{id}/logo", business(idempotent(handlers.DeleteBusinessLogoHandler)))
+	r.HandleFunc("DELETE /business/{id}", business(idempotent(handlers.DeleteBusinessHandler)))
+	r.HandleFunc("POST /businesses/{id}/restore", business(idempotent(handlers.RestoreBusinessHandler)))
 
 	// Routes files d'attentes
-	r.HandleFunc("POST /queue/join", middlewares.CORSMiddleware(middlewares.AuthMiddleware(handlers.JoinQueueHandler)))
+	r.HandleFunc("GET /queue/info/{token}", handlers.QueueTokenHandler)
+	r.HandleFunc("POST /queue/join", middlewares.AuthMiddleware(jsonBody(idempotent(handlers.JoinQueueHandler))))
+
+	// Écran d'affichage en magasin (lien en lecture seule)
+	r.HandleFunc("GET /display/{token}", handlers.DisplayHandler)
+	r.HandleFunc("GET /display/{token}/screen", handlers.DisplayScreenHandler)
+
+	// Adresse IP réelle du client derrière le load balancer
+	clientIP := middlewares.ClientIPMiddleware(cfg.Server.TrustProxyHeaders)
+
+	// Headers de sécurité (HSTS, CSP...)
+	security := middlewares.SecurityHeadersMiddleware(cfg.Server.HSTSMaxAge, cfg.Server.TrustProxyHeaders)
+
+	// CORS : une seule fois autour du routeur, politique publique pour les routes de la page client et de l'écran d'affichage
+	cors := middlewares.NewCORSMiddleware(r, cfg.CORS.Public, cfg.CORS.Merchant, "/queue/", "/display/")
+
+	// Arrêt propre sur SIGINT / SIGTERM : le contexte est annulé et les tâches de fond s'arrêtent
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Tâches de fond
+	workers.Start(ctx, workers.Worker{Name: "auth_cleanup", Interval: time.Hour, Run: handlers.CleanupAuthData})
+	workers.Start(ctx, workers.Worker{Name: "account_purge", Interval: time.Hour, Run: handlers.PurgeDeletedAccounts})
+	workers.Start(ctx, workers.Worker{Name: "business_purge", Interval: time.Hour, Run: handlers.PurgeDeletedBusinesses})
+	workers.Start(ctx, workers.Worker{Name: "storage_cleanup", Interval: 6 * time.Hour, Run: handlers.CleanupOrphanUploads})
+	workers.Start(ctx, workers.Worker{Name: "ticket_counters_cleanup", Interval: 6 * time.Hour, Run: handlers.CleanupTicketCounters})
+	workers.Start(ctx, workers.Worker{Name: "idempotency_cleanup", Interval: time.Hour, Run: middlewares.CleanupIdempotencyKeys})
+
+	// Serveur HTTP
+	server := &http.Server{
+		Addr:              port,
+		Handler:           tracingHandler(cfg, middlewares.RequestIDMiddleware(clientIP(middlewares.LanguageMiddleware(middlewares.AccessLogMiddleware(middlewares.MetricsMiddleware(security(cors(r)))))))),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	// Flux SSE des écrans d'affichage : fermés dès le début de l'arrêt
+	server.RegisterOnShutdown(handlers.CloseDisplayStreams)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		var err error
+		if cfg.Server.TLSCertFile != "" && cfg.Server.TLSKeyFile != "" {
+			slog.Info("Serveur lancé (TLS)", "addr", port)
+			err = server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			slog.Info("Serveur lancé", "addr", port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			database.CloseDB()
+			fatal("Erreur lors du lancement du serveur", err)
+		}
+	case <-ctx.Done():
+		slog.Info("Signal d'arrêt reçu, fin des requêtes en cours...")
+	}
+	stop()
+
+	// Laisser les requêtes en cours se terminer
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Arrêt forcé du serveur", "error", err)
+	}
+
+	// Attendre l'arrêt des tâches de fond
+	if err := workers.Wait(shutdownCtx); err != nil {
+		slog.Warn("Tâches de fond non terminées", "error", err)
+	}
+
+	// Laisser partir les emails en cours d'envoi
+	if err := mailer.Wait(shutdownCtx); err != nil {
+		slog.Warn("Emails non envoyés", "error", err)
+	}
+
+	// Envoyer les derniers spans au collecteur
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("Traces non exportées", "error", err)
+	}
+
+	database.CloseDB()
+	slog.Info("Serveur arrêté.")
+}
+
+// Span par requête HTTP (contexte de trace entrant respecté), sauf pour les sondes et le scrape des métriques
+func tracingHandler(cfg *config.Config, next http.Handler) http.Handler {
+	if !cfg.Tracing.Enabled {
+		return next
+	}
+
+	return otelhttp.NewHandler(next, "http.request",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != cfg.Metrics.Path && r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+		}),
+	)
+}
 
-	fmt.Print("[main.go] -> Serveur lançé : http://localhost", port)
-	http.ListenAndServe(port, r)
+// Erreur au démarrage : on journalise puis on quitte
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
 }
@@ -1,109 +1,560 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+/*
+Configuration de l'API
+Ordre de priorité (du plus faible au plus fort) :
+1. Valeurs par défaut
+2. Fichier YAML ou TOML optionnel (flag `--config` ou variable `CONFIG_FILE`)
+3. Fichier `.env` puis variables d'environnement
+*/
 type Config struct {
 	Server struct {
-		Port         string
-		Host         string
-		ReadTimeout  time.Duration
-		WriteTimeout time.Duration
-	}
+		Port              string        `yaml:"port" toml:"port"`
+		Host              string        `yaml:"host" toml:"host"`
+		ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+		WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+		IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+		ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+		MaxHeaderBytes    int           `yaml:"max_header_bytes" toml:"max_header_bytes"`
+		MaxJSONBodyBytes  int64         `yaml:"max_json_body_bytes" toml:"max_json_body_bytes"` // corps JSON
+		MaxUploadBytes    int64         `yaml:"max_upload_bytes" toml:"max_upload_bytes"`       // formulaires multipart (logo...)
+		HSTSMaxAge        time.Duration `yaml:"hsts_max_age" toml:"hsts_max_age"`               // 0 : pas de HSTS
+		TrustProxyHeaders bool          `yaml:"trust_proxy_headers" toml:"trust_proxy_headers"` // adresse IP du client lue dans X-Forwarded-For (derrière un load balancer uniquement)
+		TLSCertFile       string        `yaml:"tls_cert_file" toml:"tls_cert_file"`
+		TLSKeyFile        string        `yaml:"tls_key_file" toml:"tls_key_file"`
+	} `yaml:"server" toml:"server"`
 
 	Database struct {
-		Host     string
-		Port     string
-		User     string
-		Password string
-		DBName   string
-		SSLMode  string
-	}
+		URL             string        `yaml:"url" toml:"url"`
+		Host            string        `yaml:"host" toml:"host"`
+		Port            string        `yaml:"port" toml:"port"`
+		User            string        `yaml:"user" toml:"user"`
+		Password        string        `yaml:"password" toml:"password"`
+		DBName          string        `yaml:"name" toml:"name"`
+		SSLMode         string        `yaml:"sslmode" toml:"sslmode"`
+		MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+		MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+		ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+		AutoMigrate     bool          `yaml:"auto_migrate" toml:"auto_migrate"` // appliquer les migrations au démarrage
+	} `yaml:"database" toml:"database"`
 
 	JWT struct {
-		Secret        string
-		TokenExpiry   time.Duration
-		RefreshExpiry time.Duration
-	}
+		Secret        string        `yaml:"secret" toml:"secret"`
+		TokenExpiry   time.Duration `yaml:"token_expiry" toml:"token_expiry"`
+		RefreshExpiry time.Duration `yaml:"refresh_expiry" toml:"refresh_expiry"`
+	} `yaml:"jwt" toml:"jwt"`
+
 	GCP struct {
-		ClientID     string
-		ClientSecret string
-		RedirectURL  string
-		Scopes       []string
-		Endpoint     string
-	}
+		ClientID     string   `yaml:"client_id" toml:"client_id"`
+		ClientSecret string   `yaml:"client_secret" toml:"client_secret"`
+		RedirectURL  string   `yaml:"redirect_url" toml:"redirect_url"`
+		Scopes       []string `yaml:"scopes" toml:"scopes"`
+		Endpoint     string   `yaml:"endpoint" toml:"endpoint"`
+	} `yaml:"gcp" toml:"gcp"`
+
 	AWSS3 struct {
-		AWSS3Region        string
-		AWSS3Bucket        string
-		AWSS3UsersDir      string
-		AWSS3BusinessesDir string
-	}
+		AWSS3Region        string `yaml:"region" toml:"region"`
+		AWSS3Bucket        string `yaml:"bucket" toml:"bucket"`
+		AWSS3UsersDir      string `yaml:"users_dir" toml:"users_dir"`           // aussi utilisé par le stockage local
+		AWSS3BusinessesDir string `yaml:"businesses_dir" toml:"businesses_dir"` // aussi utilisé par le stockage local
+		AWSS3Endpoint      string `yaml:"endpoint" toml:"endpoint"`             // compatible S3 (MinIO, Scaleway...), vide pour AWS
+		AWSS3PathStyle     bool   `yaml:"path_style" toml:"path_style"`         // URLs https://endpoint/bucket/clé au lieu de https://bucket.endpoint/clé
+	} `yaml:"aws_s3" toml:"aws_s3"`
+
 	AWSIAM struct {
-		AWSIAMAccessKey string
-		AWSIAMSecretKey string
-	}
+		AWSIAMAccessKey string `yaml:"access_key" toml:"access_key"`
+		AWSIAMSecretKey string `yaml:"secret_key" toml:"secret_key"`
+	} `yaml:"aws_iam" toml:"aws_iam"`
+
+	// Liens envoyés par email (vérification, réinitialisation du mot de passe)
+	App struct {
+		FrontendURL string `yaml:"frontend_url" toml:"frontend_url"` // ex : https://app.waitify.fr
+		QueueURL    string `yaml:"queue_url" toml:"queue_url"`       // page client encodée dans les QR codes, suivie du qr_code_token (ex : https://waitify.fr/q/)
+	} `yaml:"app" toml:"app"`
+
+	// Tokens envoyés par email, politique de mot de passe et protection de la connexion
+	Auth struct {
+		EmailVerificationTTL  time.Duration `yaml:"email_verification_ttl" toml:"email_verification_ttl"`
+		PasswordResetTTL      time.Duration `yaml:"password_reset_ttl" toml:"password_reset_ttl"`
+		PasswordMinLength     int           `yaml:"password_min_length" toml:"password_min_length"`
+		PasswordMinClasses    int           `yaml:"password_min_classes" toml:"password_min_classes"` // minuscules, majuscules, chiffres, symboles (0 à 4)
+		PasswordCheckBreached bool          `yaml:"password_check_breached" toml:"password_check_breached"`
+		PasswordBreachedFile  string        `yaml:"password_breached_file" toml:"password_breached_file"` // complète la liste embarquée
+		LoginMaxAttempts      int           `yaml:"login_max_attempts" toml:"login_max_attempts"`         // échecs par compte avant blocage
+		LoginIPMaxAttempts    int           `yaml:"login_ip_max_attempts" toml:"login_ip_max_attempts"`   // échecs par adresse IP avant blocage
+		LoginBackoffBase      time.Duration `yaml:"login_backoff_base" toml:"login_backoff_base"`         // premier blocage, doublé à chaque nouvel échec
+		LoginBackoffMax       time.Duration `yaml:"login_backoff_max" toml:"login_backoff_max"`
+		LoginFailureWindow    time.Duration `yaml:"login_failure_window" toml:"login_failure_window"`         // compteur remis à zéro après cette durée sans échec
+		TwoFactorChallengeTTL time.Duration `yaml:"two_factor_challenge_ttl" toml:"two_factor_challenge_ttl"` // délai pour saisir le code après le mot de passe
+		TOTPEncryptionKey     string        `yaml:"totp_encryption_key" toml:"totp_encryption_key"`           // 32 octets en base64, sinon dérivée de JWT_SECRET
+		AccountDeletionGrace  time.Duration `yaml:"account_deletion_grace" toml:"account_deletion_grace"`     // délai avant la suppression définitive d'un compte
+		ReauthenticationTTL   time.Duration `yaml:"reauthentication_ttl" toml:"reauthentication_ttl"`         // compte sans mot de passe : âge maximum de la connexion pour une action sensible
+	} `yaml:"auth" toml:"auth"`
+
+	// Entreprises supprimées : restaurables pendant la durée de conservation, puis purgées
+	Businesses struct {
+		Retention time.Duration `yaml:"retention" toml:"retention"`
+		Timezone  string        `yaml:"timezone" toml:"timezone"` // fuseau horaire des commerces (IANA) : numéros de ticket et compteurs du jour
+	} `yaml:"businesses" toml:"businesses"`
+
+	// Requêtes rejouées avec le même en-tête Idempotency-Key : réponse conservée pendant TTL
+	Idempotency struct {
+		TTL time.Duration `yaml:"ttl" toml:"ttl"`
+	} `yaml:"idempotency" toml:"idempotency"`
+
+	// Fichiers envoyés par les utilisateurs (photos de profil, logos des entreprises)
+	Storage struct {
+		Driver         string        `yaml:"driver" toml:"driver"`                     // local, s3 (cf. aws_s3 et aws_iam)
+		LocalDir       string        `yaml:"local_dir" toml:"local_dir"`               // driver "local"
+		LocalURL       string        `yaml:"local_url" toml:"local_url"`               // driver "local" : URL publique de la route /uploads de l'API
+		URLExpiry      time.Duration `yaml:"url_expiry" toml:"url_expiry"`             // validité minimum des liens de téléchargement signés
+		AvatarMaxBytes int64         `yaml:"avatar_max_bytes" toml:"avatar_max_bytes"` // taille maximum du fichier envoyé
+		AvatarSize     int           `yaml:"avatar_size" toml:"avatar_size"`           // côté maximum (pixels) après redimensionnement
+		LogoMaxBytes   int64         `yaml:"logo_max_bytes" toml:"logo_max_bytes"`
+		LogoSize       int           `yaml:"logo_size" toml:"logo_size"`
+	} `yaml:"storage" toml:"storage"`
 
-	Environment string
+	Mail struct {
+		Driver       string `yaml:"driver" toml:"driver"` // smtp, file, log
+		From         string `yaml:"from" toml:"from"`
+		SMTPHost     string `yaml:"smtp_host" toml:"smtp_host"`
+		SMTPPort     int    `yaml:"smtp_port" toml:"smtp_port"`
+		SMTPUser     string `yaml:"smtp_user" toml:"smtp_user"`
+		SMTPPassword string `yaml:"smtp_password" toml:"smtp_password"`
+		FileDir      string `yaml:"file_dir" toml:"file_dir"` // driver "file" : un fichier .eml par email
+	} `yaml:"mail" toml:"mail"`
+
+	Log struct {
+		Level  string `yaml:"level" toml:"level"`   // debug, info, warn, error
+		Format string `yaml:"format" toml:"format"` // json, text
+	} `yaml:"log" toml:"log"`
+
+	// CORS : une politique pour les routes publiques (page client) et une pour l'espace commerçant
+	CORS struct {
+		Public   CORSPolicy `yaml:"public" toml:"public"`
+		Merchant CORSPolicy `yaml:"merchant" toml:"merchant"`
+	} `yaml:"cors" toml:"cors"`
+
+	Metrics struct {
+		Enabled bool   `yaml:"enabled" toml:"enabled"`
+		Path    string `yaml:"path" toml:"path"`
+	} `yaml:"metrics" toml:"metrics"`
+
+	Tracing struct {
+		Enabled      bool    `yaml:"enabled" toml:"enabled"`
+		ServiceName  string  `yaml:"service_name" toml:"service_name"`
+		OTLPEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint"` // ex : http://localhost:4318
+		Insecure     bool    `yaml:"insecure" toml:"insecure"`           // OTLP en HTTP sans TLS
+		SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio"`   // entre 0 et 1
+	} `yaml:"tracing" toml:"tracing"`
+
+	Environment string `yaml:"environment" toml:"environment"`
+}
+
+// Politique CORS d'un groupe de routes
+type CORSPolicy struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" toml:"allowed_origins"` // "https://app.waitify.fr", "https://*.waitify.fr" ou "*"
+	AllowCredentials bool          `yaml:"allow_credentials" toml:"allow_credentials"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" toml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" toml:"exposed_headers"`
+	MaxAge           time.Duration `yaml:"max_age" toml:"max_age"` // cache du preflight côté navigateur
 }
 
-func Load() (*Config, error) {
-	godotenv.Load()
+// Environnements acceptés
+var environments = []string{"development", "staging", "production"}
 
+// Valeurs par défaut
+func defaults() *Config {
 	cfg := &Config{}
 
 	// Serveur
-	cfg.Server.Port = os.Getenv("SERVER_PORT")
-	cfg.Server.Host = os.Getenv("SERVER_HOST")
+	cfg.Server.Port = ":3000"
 	cfg.Server.ReadTimeout = time.Second * 15
+	cfg.Server.ReadHeaderTimeout = time.Second * 5
 	cfg.Server.WriteTimeout = time.Second * 15
+	cfg.Server.IdleTimeout = time.Second * 60
+	cfg.Server.ShutdownTimeout = time.Second * 20
+	cfg.Server.MaxHeaderBytes = 1 << 20    // 1 Mo
+	cfg.Server.MaxJSONBodyBytes = 64 << 10 // 64 Ko
+	cfg.Server.MaxUploadBytes = 5 << 20    // 5 Mo
+	cfg.Server.HSTSMaxAge = time.Hour * 24 * 365
 
 	// Base de données
-	cfg.Database.Host = os.Getenv("DB_HOST")
-	cfg.Database.Port = os.Getenv("DB_PORT")
-	cfg.Database.User = os.Getenv("DB_USER")
-	cfg.Database.Password = os.Getenv("DB_PASSWORD")
-	cfg.Database.DBName = os.Getenv("DB_NAME")
-	cfg.Database.SSLMode = os.Getenv("DB_SSLMODE")
+	cfg.Database.Port = "5432"
+	cfg.Database.SSLMode = "require"
+	cfg.Database.MaxOpenConns = 25
+	cfg.Database.MaxIdleConns = 5
+	cfg.Database.ConnMaxLifetime = 5 * time.Minute
 
 	// JWT
-	cfg.JWT.Secret = os.Getenv("JWT_SECRET")
 	cfg.JWT.TokenExpiry = time.Hour * 24    // 24 heures
 	cfg.JWT.RefreshExpiry = time.Hour * 168 // 7 jours
 
 	// Google Cloud Platform
-	cfg.GCP.ClientID = os.Getenv("GCP_CLIENT_ID")
-	cfg.GCP.ClientSecret = os.Getenv("GCP_CLIENT_SECRET")
-	cfg.GCP.RedirectURL = os.Getenv("GCP_CLIENT_CALLBACK")
+	cfg.GCP.Scopes = []string{"https://www.googleapis.com/auth/userinfo.email",
+		"https://www.googleapis.com/auth/userinfo.profile"}
 
-	// AWS S3 bucket
-	cfg.AWSS3.AWSS3Region = os.Getenv("AWS_S3_REGION")
-	cfg.AWSS3.AWSS3Bucket = os.Getenv("AWS_S3_BUCKET")
-	cfg.AWSS3.AWSS3UsersDir = os.Getenv("AWS_S3_BUCKET_USERS")
-	cfg.AWSS3.AWSS3BusinessesDir = os.Getenv("AWS_S3_BUCKET_BUSINESSES")
+	// Liens envoyés par email
+	cfg.App.FrontendURL = "http://localhost:5173"
+	cfg.App.QueueURL = "http://localhost:5173/q/"
+	cfg.Auth.EmailVerificationTTL = time.Hour * 48
+	cfg.Auth.PasswordResetTTL = time.Hour
 
-	// AWS S3 bucket
-	cfg.AWSIAM.AWSIAMAccessKey = os.Getenv("AWS_IAM_ACCESS_KEY")
-	cfg.AWSIAM.AWSIAMSecretKey = os.Getenv("AWS_IAM_SECRET_KEY")
+	// Mots de passe et tentatives de connexion
+	cfg.Auth.PasswordMinLength = 10
+	cfg.Auth.PasswordMinClasses = 3
+	cfg.Auth.PasswordCheckBreached = true
+	cfg.Auth.LoginMaxAttempts = 5
+	cfg.Auth.LoginIPMaxAttempts = 20
+	cfg.Auth.LoginBackoffBase = time.Minute
+	cfg.Auth.LoginBackoffMax = time.Hour
+	cfg.Auth.LoginFailureWindow = time.Hour
+	cfg.Auth.TwoFactorChallengeTTL = 5 * time.Minute
+	cfg.Auth.AccountDeletionGrace = time.Hour * 24 * 30 // 30 jours
+	cfg.Auth.ReauthenticationTTL = 5 * time.Minute
+	cfg.Businesses.Retention = time.Hour * 24 * 30
+	cfg.Businesses.Timezone = "Europe/Paris"
+	cfg.Idempotency.TTL = time.Hour * 24
+
+	// Fichiers envoyés par les utilisateurs
+	cfg.Storage.Driver = "local"
+	cfg.Storage.LocalDir = "tmp/uploads"
+	cfg.Storage.LocalURL = "http://localhost:3000/uploads"
+	cfg.Storage.URLExpiry = time.Hour
+	cfg.Storage.AvatarMaxBytes = 5 << 20 // 5 Mo (photos de téléphone), réduite à AvatarSize
+	cfg.Storage.AvatarSize = 512
+	cfg.Storage.LogoMaxBytes = 5 << 20
+	cfg.Storage.LogoSize = 1024
+	cfg.AWSS3.AWSS3UsersDir = "users"
+	cfg.AWSS3.AWSS3BusinessesDir = "businesses"
+
+	// Emails : écrits dans les logs tant qu'aucun serveur SMTP n'est configuré
+	cfg.Mail.Driver = "log"
+	cfg.Mail.From = "Waitify <no-reply@waitify.fr>"
+	cfg.Mail.SMTPPort = 587
+	cfg.Mail.FileDir = "tmp/mails"
+
+	// Logs
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "json"
+
+	// CORS : page client ouverte à tous sans cookies, espace commerçant fermé tant qu'aucune origine n'est autorisée
+	corsHeaders := []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "X-Request-ID"}
+	corsExposed := []string{"Content-Language", "ETag", "Idempotent-Replayed", "X-Request-ID"}
+	cfg.CORS.Public.AllowedOrigins = []string{"*"}
+	cfg.CORS.Public.AllowedHeaders = corsHeaders
+	cfg.CORS.Public.ExposedHeaders = corsExposed
+	cfg.CORS.Public.MaxAge = 10 * time.Minute
+	cfg.CORS.Merchant.AllowCredentials = true
+	cfg.CORS.Merchant.AllowedHeaders = corsHeaders
+	cfg.CORS.Merchant.ExposedHeaders = corsExposed
+	cfg.CORS.Merchant.MaxAge = 10 * time.Minute
+
+	// Métriques Prometheus
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Path = "/metrics"
+
+	// Traces OpenTelemetry (désactivées tant qu'aucun collecteur n'est configuré)
+	cfg.Tracing.ServiceName = "waitify-api"
+	cfg.Tracing.SampleRatio = 1
 
 	// Environnement de développement
-	cfg.Environment = os.Getenv("ENV")
+	cfg.Environment = "development"
+
+	return cfg
+}
+
+// Charger, puis valider la configuration. `path` est le fichier YAML/TOML optionnel.
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
-// func getEnv(key, defaultValue string) string {
-// 	if value := os.Getenv(key); value != "" {
-// 		return value
-// 	}
-// 	return defaultValue
-// }
+// Charger la configuration sans la valider (commande `config check`)
+func Read(path string) (*Config, error) {
+	// Fichier .env optionnel, mais invalide = erreur
+	if _, err := os.Stat(".env"); err == nil {
+		if err := godotenv.Load(); err != nil {
+			return nil, fmt.Errorf("[envConfig.go -> Load()] -> Fichier .env invalide : %w", err)
+		}
+	}
+
+	cfg := defaults()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Fichier de configuration YAML ou TOML (selon l'extension)
+func (cfg *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("[envConfig.go -> loadFile()] -> Impossible de lire le fichier de configuration %q : %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, cfg)
+	default:
+		return fmt.Errorf("[envConfig.go -> loadFile()] -> Format de fichier de configuration non supporté %q (yaml, yml ou toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("[envConfig.go -> loadFile()] -> Fichier de configuration %q invalide : %w", path, err)
+	}
+	return nil
+}
+
+// Variables d'environnement (prioritaires sur le fichier)
+func (cfg *Config) loadEnv() error {
+	var errs []error
+
+	// Serveur
+	cfg.Server.Port = getEnv("SERVER_PORT", cfg.Server.Port)
+	cfg.Server.Host = getEnv("SERVER_HOST", cfg.Server.Host)
+	cfg.Server.ReadTimeout = getEnvDuration("SERVER_READ_TIMEOUT", cfg.Server.ReadTimeout, &errs)
+	cfg.Server.ReadHeaderTimeout = getEnvDuration("SERVER_READ_HEADER_TIMEOUT", cfg.Server.ReadHeaderTimeout, &errs)
+	cfg.Server.WriteTimeout = getEnvDuration("SERVER_WRITE_TIMEOUT", cfg.Server.WriteTimeout, &errs)
+	cfg.Server.IdleTimeout = getEnvDuration("SERVER_IDLE_TIMEOUT", cfg.Server.IdleTimeout, &errs)
+	cfg.Server.ShutdownTimeout = getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", cfg.Server.ShutdownTimeout, &errs)
+	cfg.Server.MaxHeaderBytes = getEnvInt("SERVER_MAX_HEADER_BYTES", cfg.Server.MaxHeaderBytes, &errs)
+	cfg.Server.MaxJSONBodyBytes = int64(getEnvInt("SERVER_MAX_JSON_BODY_BYTES", int(cfg.Server.MaxJSONBodyBytes), &errs))
+	cfg.Server.MaxUploadBytes = int64(getEnvInt("SERVER_MAX_UPLOAD_BYTES", int(cfg.Server.MaxUploadBytes), &errs))
+	cfg.Server.HSTSMaxAge = getEnvDuration("SERVER_HSTS_MAX_AGE", cfg.Server.HSTSMaxAge, &errs)
+	cfg.Server.TrustProxyHeaders = getEnvBool("SERVER_TRUST_PROXY_HEADERS", cfg.Server.TrustProxyHeaders, &errs)
+	cfg.Server.TLSCertFile = getEnv("SERVER_TLS_CERT_FILE", cfg.Server.TLSCertFile)
+	cfg.Server.TLSKeyFile = getEnv("SERVER_TLS_KEY_FILE", cfg.Server.TLSKeyFile)
 
+	// Base de données
+	cfg.Database.URL = getEnv("DB_URL", cfg.Database.URL)
+	cfg.Database.Host = getEnv("DB_HOST", cfg.Database.Host)
+	cfg.Database.Port = getEnv("DB_PORT", cfg.Database.Port)
+	cfg.Database.User = getEnv("DB_USER", cfg.Database.User)
+	cfg.Database.Password = getEnv("DB_PASSWORD", cfg.Database.Password)
+	cfg.Database.DBName = getEnv("DB_NAME", cfg.Database.DBName)
+	cfg.Database.SSLMode = getEnv("DB_SSLMODE", cfg.Database.SSLMode)
+	cfg.Database.MaxOpenConns = getEnvInt("DB_MAX_OPEN_CONNS", cfg.Database.MaxOpenConns, &errs)
+	cfg.Database.MaxIdleConns = getEnvInt("DB_MAX_IDLE_CONNS", cfg.Database.MaxIdleConns, &errs)
+	cfg.Database.ConnMaxLifetime = getEnvDuration("DB_CONN_MAX_LIFETIME", cfg.Database.ConnMaxLifetime, &errs)
+	cfg.Database.AutoMigrate = getEnvBool("DB_AUTO_MIGRATE", cfg.Database.AutoMigrate, &errs)
+
+	// JWT
+	cfg.JWT.Secret = getEnv("JWT_SECRET", cfg.JWT.Secret)
+	cfg.JWT.TokenExpiry = getEnvDuration("JWT_TOKEN_EXPIRY", cfg.JWT.TokenExpiry, &errs)
+	cfg.JWT.RefreshExpiry = getEnvDuration("JWT_REFRESH_EXPIRY", cfg.JWT.RefreshExpiry, &errs)
+
+	// Google Cloud Platform
+	cfg.GCP.ClientID = getEnv("GCP_CLIENT_ID", cfg.GCP.ClientID)
+	cfg.GCP.ClientSecret = getEnv("GCP_CLIENT_SECRET", cfg.GCP.ClientSecret)
+	cfg.GCP.RedirectURL = getEnv("GCP_CLIENT_CALLBACK", cfg.GCP.RedirectURL)
+
+	// AWS S3 bucket
+	cfg.AWSS3.AWSS3Region = getEnv("AWS_S3_REGION", cfg.AWSS3.AWSS3Region)
+	cfg.AWSS3.AWSS3Bucket = getEnv("AWS_S3_BUCKET", cfg.AWSS3.AWSS3Bucket)
+	cfg.AWSS3.AWSS3UsersDir = getEnv("AWS_S3_BUCKET_USERS", cfg.AWSS3.AWSS3UsersDir)
+	cfg.AWSS3.AWSS3BusinessesDir = getEnv("AWS_S3_BUCKET_BUSINESSES", cfg.AWSS3.AWSS3BusinessesDir)
+	cfg.AWSS3.AWSS3Endpoint = getEnv("AWS_S3_ENDPOINT", cfg.AWSS3.AWSS3Endpoint)
+	cfg.AWSS3.AWSS3PathStyle = getEnvBool("AWS_S3_PATH_STYLE", cfg.AWSS3.AWSS3PathStyle, &errs)
+
+	// AWS IAM
+	cfg.AWSIAM.AWSIAMAccessKey = getEnv("AWS_IAM_ACCESS_KEY", cfg.AWSIAM.AWSIAMAccessKey)
+	cfg.AWSIAM.AWSIAMSecretKey = getEnv("AWS_IAM_SECRET_KEY", cfg.AWSIAM.AWSIAMSecretKey)
+
+	// Liens et tokens envoyés par email
+	cfg.App.FrontendURL = getEnv("APP_FRONTEND_URL", cfg.App.FrontendURL)
+	cfg.App.QueueURL = getEnv("APP_QUEUE_URL", cfg.App.QueueURL)
+	cfg.Auth.EmailVerificationTTL = getEnvDuration("AUTH_EMAIL_VERIFICATION_TTL", cfg.Auth.EmailVerificationTTL, &errs)
+	cfg.Auth.PasswordResetTTL = getEnvDuration("AUTH_PASSWORD_RESET_TTL", cfg.Auth.PasswordResetTTL, &errs)
+
+	// Mots de passe et tentatives de connexion
+	cfg.Auth.PasswordMinLength = getEnvInt("AUTH_PASSWORD_MIN_LENGTH", cfg.Auth.PasswordMinLength, &errs)
+	cfg.Auth.PasswordMinClasses = getEnvInt("AUTH_PASSWORD_MIN_CLASSES", cfg.Auth.PasswordMinClasses, &errs)
+	cfg.Auth.PasswordCheckBreached = getEnvBool("AUTH_PASSWORD_CHECK_BREACHED", cfg.Auth.PasswordCheckBreached, &errs)
+	cfg.Auth.PasswordBreachedFile = getEnv("AUTH_PASSWORD_BREACHED_FILE", cfg.Auth.PasswordBreachedFile)
+	cfg.Auth.LoginMaxAttempts = getEnvInt("AUTH_LOGIN_MAX_ATTEMPTS", cfg.Auth.LoginMaxAttempts, &errs)
+	cfg.Auth.LoginIPMaxAttempts = getEnvInt("AUTH_LOGIN_IP_MAX_ATTEMPTS", cfg.Auth.LoginIPMaxAttempts, &errs)
+	cfg.Auth.LoginBackoffBase = getEnvDuration("AUTH_LOGIN_BACKOFF_BASE", cfg.Auth.LoginBackoffBase, &errs)
+	cfg.Auth.LoginBackoffMax = getEnvDuration("AUTH_LOGIN_BACKOFF_MAX", cfg.Auth.LoginBackoffMax, &errs)
+	cfg.Auth.LoginFailureWindow = getEnvDuration("AUTH_LOGIN_FAILURE_WINDOW", cfg.Auth.LoginFailureWindow, &errs)
+
+	// Double authentification
+	cfg.Auth.TwoFactorChallengeTTL = getEnvDuration("AUTH_2FA_CHALLENGE_TTL", cfg.Auth.TwoFactorChallengeTTL, &errs)
+	cfg.Auth.TOTPEncryptionKey = getEnv("AUTH_TOTP_ENCRYPTION_KEY", cfg.Auth.TOTPEncryptionKey)
+
+	// Comptes
+	cfg.Auth.AccountDeletionGrace = getEnvDuration("AUTH_ACCOUNT_DELETION_GRACE", cfg.Auth.AccountDeletionGrace, &errs)
+	cfg.Auth.ReauthenticationTTL = getEnvDuration("AUTH_REAUTHENTICATION_TTL", cfg.Auth.ReauthenticationTTL, &errs)
+	cfg.Businesses.Retention = getEnvDuration("BUSINESS_RETENTION", cfg.Businesses.Retention, &errs)
+	cfg.Businesses.Timezone = getEnv("BUSINESS_TIMEZONE", cfg.Businesses.Timezone)
+	cfg.Idempotency.TTL = getEnvDuration("IDEMPOTENCY_TTL", cfg.Idempotency.TTL, &errs)
+
+	// Fichiers envoyés par les utilisateurs
+	cfg.Storage.Driver = getEnv("STORAGE_DRIVER", cfg.Storage.Driver)
+	cfg.Storage.LocalDir = getEnv("STORAGE_LOCAL_DIR", cfg.Storage.LocalDir)
+	cfg.Storage.LocalURL = getEnv("STORAGE_LOCAL_URL", cfg.Storage.LocalURL)
+	cfg.Storage.URLExpiry = getEnvDuration("STORAGE_URL_EXPIRY", cfg.Storage.URLExpiry, &errs)
+	cfg.Storage.AvatarMaxBytes = int64(getEnvInt("STORAGE_AVATAR_MAX_BYTES", int(cfg.Storage.AvatarMaxBytes), &errs))
+	cfg.Storage.AvatarSize = getEnvInt("STORAGE_AVATAR_SIZE", cfg.Storage.AvatarSize, &errs)
+	cfg.Storage.LogoMaxBytes = int64(getEnvInt("STORAGE_LOGO_MAX_BYTES", int(cfg.Storage.LogoMaxBytes), &errs))
+	cfg.Storage.LogoSize = getEnvInt("STORAGE_LOGO_SIZE", cfg.Storage.LogoSize, &errs)
+
+	// Emails
+	cfg.Mail.Driver = getEnv("MAIL_DRIVER", cfg.Mail.Driver)
+	cfg.Mail.From = getEnv("MAIL_FROM", cfg.Mail.From)
+	cfg.Mail.SMTPHost = getEnv("SMTP_HOST", cfg.Mail.SMTPHost)
+	cfg.Mail.SMTPPort = getEnvInt("SMTP_PORT", cfg.Mail.SMTPPort, &errs)
+	cfg.Mail.SMTPUser = getEnv("SMTP_USER", cfg.Mail.SMTPUser)
+	cfg.Mail.SMTPPassword = getEnv("SMTP_PASSWORD", cfg.Mail.SMTPPassword)
+	cfg.Mail.FileDir = getEnv("MAIL_FILE_DIR", cfg.Mail.FileDir)
+
+	// Logs
+	cfg.Log.Level = getEnv("LOG_LEVEL", cfg.Log.Level)
+	cfg.Log.Format = getEnv("LOG_FORMAT", cfg.Log.Format)
+
+	// CORS
+	cfg.CORS.Public.AllowedOrigins = getEnvList("CORS_PUBLIC_ORIGINS", cfg.CORS.Public.AllowedOrigins)
+	cfg.CORS.Public.AllowCredentials = getEnvBool("CORS_PUBLIC_CREDENTIALS", cfg.CORS.Public.AllowCredentials, &errs)
+	cfg.CORS.Merchant.AllowedOrigins = getEnvList("CORS_MERCHANT_ORIGINS", cfg.CORS.Merchant.AllowedOrigins)
+	cfg.CORS.Merchant.AllowCredentials = getEnvBool("CORS_MERCHANT_CREDENTIALS", cfg.CORS.Merchant.AllowCredentials, &errs)
+
+	// Métriques
+	cfg.Metrics.Enabled = getEnvBool("METRICS_ENABLED", cfg.Metrics.Enabled, &errs)
+	cfg.Metrics.Path = getEnv("METRICS_PATH", cfg.Metrics.Path)
+
+	// Traces (noms standards OpenTelemetry quand ils existent)
+	cfg.Tracing.Enabled = getEnvBool("TRACING_ENABLED", cfg.Tracing.Enabled, &errs)
+	cfg.Tracing.ServiceName = getEnv("OTEL_SERVICE_NAME", cfg.Tracing.ServiceName)
+	cfg.Tracing.OTLPEndpoint = getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", cfg.Tracing.OTLPEndpoint)
+	cfg.Tracing.Insecure = getEnvBool("OTEL_EXPORTER_OTLP_INSECURE", cfg.Tracing.Insecure, &errs)
+	cfg.Tracing.SampleRatio = getEnvFloat("TRACING_SAMPLE_RATIO", cfg.Tracing.SampleRatio, &errs)
+
+	// Environnement de développement
+	cfg.Environment = getEnv("ENV", cfg.Environment)
+
+	return errors.Join(errs...)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Liste séparée par des virgules ("a, b, c")
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvInt(key string, defaultValue int, errs *[]error) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s : entier attendu, reçu %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvBool(key string, defaultValue bool, errs *[]error) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s : booléen attendu (true, false), reçu %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvFloat(key string, defaultValue float64, errs *[]error) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s : nombre attendu, reçu %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration, errs *[]error) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s : durée attendue (ex : 15s, 5m), reçu %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// Chaîne de connexion PostgreSQL : DB_URL si fournie, sinon construite à partir des champs
 func (c *Config) GetDSN() string {
+	if c.Database.URL != "" {
+		return c.Database.URL
+	}
+
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
 		c.Database.Port,
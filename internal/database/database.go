@@ -1,38 +1,63 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"log"
-	"os"
-
-	// "os"
-	"time"
+	"log/slog"
 
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var DB *sql.DB
 
-func InitDB() {
-
-	connectionString := fmt.Sprintf("%s", os.Getenv("DB_URL"))
-
+func InitDB(cfg *config.Config) error {
 	var err error
-	DB, err = sql.Open("postgres", connectionString)
+	// Driver instrumenté : un span par requête SQL, rattaché au span de la requête HTTP (méthodes ...Context)
+	DB, err = otelsql.Open("postgres", cfg.GetDSN(),
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			// Pas de traces orphelines (scrapes Prometheus, tâches de fond sans span parent)
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
 	if err != nil {
-		log.Fatal(`[database.go -> InitDB()] Erreur lors de la connexion à la base de données : `, err)
+		return fmt.Errorf("[database.go -> InitDB()] Erreur lors de la connexion à la base de données : %w", err)
 	}
 
 	// Configuration du pool de connexion
-	DB.SetMaxOpenConns(25)
-	DB.SetMaxIdleConns(5)
-	DB.SetConnMaxLifetime(5 * time.Minute)
+	DB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	DB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	DB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
 	err = DB.Ping()
 	if err != nil {
-		log.Fatal(`[database.go -> InitDB()] Erreur lors de la tentative de ping à la base de données : `, err)
+		return fmt.Errorf("[database.go -> InitDB()] Erreur lors de la tentative de ping à la base de données : %w", err)
+	}
+
+	slog.Info("Connexion à la base de données établie !")
+	return nil
+}
+
+// Fermer le pool de connexions (arrêt du serveur)
+func CloseDB() {
+	if DB == nil {
+		return
+	}
+
+	if err := DB.Close(); err != nil {
+		slog.Error("Erreur lors de la fermeture de la base de données", "error", err)
+		return
 	}
 
-	log.Println(`[database.go -> InitDB()] Connexion à la base de données établie !`)
+	slog.Info("Connexion à la base de données fermée.")
 }
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Migrations SQL embarquées dans le binaire (dossier migrations/, fichiers "0001_description.sql")
+Chaque migration est appliquée dans sa propre transaction et enregistrée dans la table schema_migrations
+Une migration appliquée ne doit plus jamais être modifiée : on en ajoute une nouvelle
+*/
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Verrou consultatif : une seule instance applique les migrations à la fois
+const migrationLockID = 7_348_201
+
+// Dernière migration correspondant au schéma documenté avant l'arrivée des migrations (cf. `migrate baseline`)
+const BaselineVersion = 1
+
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	sql     string
+}
+
+// Lire et trier les migrations embarquées
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("[migrations.go -> loadMigrations()] -> %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := map[int]string{}
+	for _, entry := range entries {
+		versionText, name, found := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		version, err := strconv.Atoi(versionText)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("[migrations.go -> loadMigrations()] -> Nom de migration invalide %q (attendu : 0001_description.sql)", entry.Name())
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("[migrations.go -> loadMigrations()] -> Version %d en double (%s, %s)", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("[migrations.go -> loadMigrations()] -> %w", err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, sql: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)
+	`)
+	return err
+}
+
+// Versions déjà appliquées
+func appliedVersions(ctx context.Context, querier interface {
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+}) (map[int]bool, error) {
+	rows, err := querier.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// Appliquer toutes les migrations en attente, retourne celles qui ont été appliquées
+func Migrate(ctx context.Context) ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[migrations.go -> Migrate()] -> %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("[migrations.go -> Migrate()] -> Impossible de prendre le verrou des migrations : %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, fmt.Errorf("[migrations.go -> Migrate()] -> Impossible de créer la table schema_migrations : %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("[migrations.go -> Migrate()] -> %w", err)
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		if err := applyMigration(ctx, conn, migration); err != nil {
+			return done, fmt.Errorf("[migrations.go -> Migrate()] -> Migration %04d_%s : %w", migration.Version, migration.Name, err)
+		}
+		slog.Info("Migration appliquée", "version", migration.Version, "name", migration.Name)
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.sql); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+/*
+Marquer les migrations jusqu'à `version` comme appliquées sans les exécuter
+Pour une base créée à la main à partir de DATABASE.md avant l'arrivée des migrations
+*/
+func Baseline(ctx context.Context, version int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("[migrations.go -> Baseline()] -> %w", err)
+	}
+	defer conn.Close()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return fmt.Errorf("[migrations.go -> Baseline()] -> Impossible de créer la table schema_migrations : %w", err)
+	}
+
+	for _, migration := range migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING", migration.Version, migration.Name); err != nil {
+			return fmt.Errorf("[migrations.go -> Baseline()] -> %w", err)
+		}
+	}
+	return nil
+}
+
+// Migrations embarquées qui n'ont pas encore été appliquées sur la base
+func PendingMigrations(ctx context.Context) ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := DB.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("[migrations.go -> PendingMigrations()] -> %w", err)
+	}
+	if !exists {
+		return migrations, nil
+	}
+
+	applied, err := appliedVersions(ctx, DB)
+	if err != nil {
+		return nil, fmt.Errorf("[migrations.go -> PendingMigrations()] -> %w", err)
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,9 @@
+-- Langue par défaut du commerce et langue de chaque entrée de file (SMS et page client)
+-- Idempotente : certaines bases ont déjà reçu ces colonnes à la main
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS default_language VARCHAR(5) NOT NULL DEFAULT 'fr';
+ALTER TABLE businesses DROP CONSTRAINT IF EXISTS check_default_language;
+ALTER TABLE businesses ADD CONSTRAINT check_default_language CHECK (default_language IN ('fr', 'en', 'es'));
+
+ALTER TABLE queue_entries ADD COLUMN IF NOT EXISTS language VARCHAR(5) NOT NULL DEFAULT 'fr';
+ALTER TABLE queue_entries DROP CONSTRAINT IF EXISTS check_language_valid;
+ALTER TABLE queue_entries ADD CONSTRAINT check_language_valid CHECK (language IN ('fr', 'en', 'es'));
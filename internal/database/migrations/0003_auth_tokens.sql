@@ -0,0 +1,20 @@
+-- Vérification de l'adresse email et réinitialisation du mot de passe
+-- Les comptes existants sont considérés comme vérifiés (créés avant la vérification obligatoire)
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+UPDATE users SET email_verified_at = COALESCE(created_at, NOW());
+
+-- Tokens à usage unique envoyés par email : seule l'empreinte SHA-256 est stockée
+CREATE TABLE auth_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    UserId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_auth_tokens_user_purpose ON auth_tokens(UserId, purpose) WHERE used_at IS NULL;
+CREATE INDEX idx_auth_tokens_expires ON auth_tokens(expires_at);
+
+ALTER TABLE auth_tokens ADD CONSTRAINT check_auth_token_purpose CHECK (purpose IN ('email_verification', 'password_reset'));
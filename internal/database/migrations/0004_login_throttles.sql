@@ -0,0 +1,10 @@
+-- Échecs de connexion par compte ("account:<email>") et par adresse IP ("ip:<adresse>")
+-- Partagé entre toutes les instances de l'API : le blocage s'applique quel que soit le serveur qui reçoit la requête
+CREATE TABLE login_throttles (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_login_throttles_last_failure ON login_throttles(last_failure_at);
@@ -0,0 +1,18 @@
+-- Double authentification TOTP
+-- totp_secret est chiffré par l'API (AES-GCM) : il est enregistré dès l'enrôlement, mais la 2FA n'est active qu'avec totp_enabled_at
+ALTER TABLE users ADD COLUMN totp_secret TEXT;
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT;
+
+-- Codes de secours à usage unique (empreinte SHA-256)
+CREATE TABLE recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    UserId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (UserId, code_hash)
+);
+
+-- Le propriétaire impose la double authentification pour accéder à l'entreprise
+ALTER TABLE businesses ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+-- Suppression de compte différée : le compte et ses entreprises sont désactivés, puis supprimés après le délai de grâce
+-- Une entreprise désactivée avec le compte porte la même date (NOW() est fixe dans une transaction) : elle est restaurée avec lui
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE businesses ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_deleted ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_businesses_deleted ON businesses(deleted_at) WHERE deleted_at IS NOT NULL;
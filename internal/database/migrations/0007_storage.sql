@@ -0,0 +1,5 @@
+-- Fichiers envoyés : la base stocke la clé de l'objet (ex : users/<id>/<nom>.jpg), les liens signés sont générés à la lecture
+-- Les photos enregistrées avant le stockage S3 étaient référencées par leur chemin public "/uploads/<clé>"
+UPDATE users SET profile_picture = substr(profile_picture, length('/uploads/') + 1) WHERE profile_picture LIKE '/uploads/%';
+
+ALTER TABLE businesses ADD COLUMN logo VARCHAR(255);
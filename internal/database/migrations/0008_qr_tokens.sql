@@ -0,0 +1,23 @@
+-- Tokens des QR codes : historique, rotation avec délai de grâce, révocation et plusieurs codes actifs par entreprise (un par entrée...)
+-- businesses.qr_code_token reste le token principal (affiche par défaut), il figure aussi dans cette table
+CREATE TABLE qr_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    BusinessId UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    token VARCHAR(255) NOT NULL UNIQUE,
+    label VARCHAR(100),
+    scan_count INTEGER NOT NULL DEFAULT 0,
+    last_scanned_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    replaced_by UUID REFERENCES qr_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_qr_tokens_business ON qr_tokens(BusinessId, created_at);
+
+INSERT INTO qr_tokens (BusinessId, token, created_at)
+SELECT id, qr_code_token, created_at FROM businesses;
+
+-- Attribution des inscriptions au QR code scanné
+ALTER TABLE queue_entries ADD COLUMN qr_token_id UUID REFERENCES qr_tokens(id) ON DELETE SET NULL;
+CREATE INDEX idx_queue_entries_qr_token ON queue_entries(qr_token_id) WHERE qr_token_id IS NOT NULL;
@@ -0,0 +1,21 @@
+-- Suppression d'une entreprise : désactivée (is_active = false, deleted_at), restaurable pendant BUSINESS_RETENTION, puis purgée
+-- Avant la purge, l'activité est agrégée par mois dans business_usage_archives (facturation, statistiques) :
+-- les files, SMS et statistiques quotidiennes de l'entreprise sont supprimés avec elle (ON DELETE CASCADE)
+CREATE TABLE business_usage_archives (
+    BusinessId UUID NOT NULL,
+    UserId UUID,
+    business_name VARCHAR(255) NOT NULL,
+    business_type VARCHAR(100) NOT NULL,
+    month DATE NOT NULL,
+    clients_registered INTEGER NOT NULL DEFAULT 0,
+    clients_served INTEGER NOT NULL DEFAULT 0,
+    clients_missed INTEGER NOT NULL DEFAULT 0,
+    clients_cancelled INTEGER NOT NULL DEFAULT 0,
+    average_wait_time INTEGER,
+    sms_sent INTEGER NOT NULL DEFAULT 0,
+    sms_cost_cents INTEGER NOT NULL DEFAULT 0,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (BusinessId, month)
+);
+
+CREATE INDEX idx_business_usage_archives_user_month ON business_usage_archives(UserId, month);
@@ -0,0 +1,40 @@
+-- Gestion de la file par le commerçant : réordonner, renvoyer en fin de file, retirer un client
+-- L'ordre de la file est celui de `position` (modifiable) : le recalcul après un changement de statut
+-- resserre les positions sans revenir à l'ordre d'inscription
+CREATE OR REPLACE FUNCTION recalculate_queue_positions()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE queue_entries
+    SET position = new_position
+    FROM (
+        SELECT id, ROW_NUMBER() OVER (ORDER BY position, created_at) as new_position
+        FROM queue_entries
+        WHERE BusinessId = COALESCE(NEW.BusinessId, OLD.BusinessId)
+        AND status = 'waiting'
+    ) AS positioned
+    WHERE queue_entries.id = positioned.id
+    AND queue_entries.position IS DISTINCT FROM positioned.new_position;
+
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ language 'plpgsql';
+
+-- Historique des actions du commerçant sur la file, avec leur motif
+CREATE TABLE queue_entry_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    QueueEntryId UUID NOT NULL REFERENCES queue_entries(id) ON DELETE CASCADE,
+    BusinessId UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    UserId UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL,
+    reason VARCHAR(20),
+    note VARCHAR(255),
+    from_position INTEGER,
+    to_position INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_queue_entry_events_business ON queue_entry_events(BusinessId, created_at);
+CREATE INDEX idx_queue_entry_events_entry ON queue_entry_events(QueueEntryId);
+
+ALTER TABLE queue_entry_events ADD CONSTRAINT check_queue_event_action CHECK (action IN ('move', 'move_to_end', 'remove'));
+ALTER TABLE queue_entry_events ADD CONSTRAINT check_queue_event_reason CHECK (reason IS NULL OR reason IN ('no_show', 'left', 'duplicate', 'behaviour', 'other'));
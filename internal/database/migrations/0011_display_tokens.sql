@@ -0,0 +1,3 @@
+-- Écran d'affichage en magasin (GET /display/{token}) : lien en lecture seule, créé à la demande du commerçant
+-- NULL : écran désactivé. Un nouveau token rend l'ancien lien invalide
+ALTER TABLE businesses ADD COLUMN display_token VARCHAR(64) UNIQUE;
@@ -0,0 +1,47 @@
+-- Numéros de ticket lisibles (A001, B014, 27) : séquence par commerce, remise à zéro chaque jour
+-- Préfixe optionnel (1 à 3 lettres majuscules) : celui du QR code scanné (service, entrée), sinon celui du commerce
+ALTER TABLE businesses ADD COLUMN ticket_prefix VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE businesses ADD CONSTRAINT check_business_ticket_prefix CHECK (ticket_prefix ~ '^[A-Z]{0,3}$');
+
+-- NULL : préfixe du commerce
+ALTER TABLE qr_tokens ADD COLUMN ticket_prefix VARCHAR(3);
+ALTER TABLE qr_tokens ADD CONSTRAINT check_qr_token_ticket_prefix CHECK (ticket_prefix IS NULL OR ticket_prefix ~ '^[A-Z]{0,3}$');
+
+-- Dernier numéro attribué par commerce, jour et préfixe (incrémenté atomiquement à l'inscription)
+CREATE TABLE ticket_counters (
+    BusinessId UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    prefix VARCHAR(3) NOT NULL DEFAULT '',
+    last_number INTEGER NOT NULL,
+    PRIMARY KEY (BusinessId, day, prefix)
+);
+
+ALTER TABLE queue_entries ADD COLUMN ticket_date DATE;
+ALTER TABLE queue_entries ADD COLUMN ticket_prefix VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE queue_entries ADD COLUMN ticket_number INTEGER;
+
+-- Numéros des inscriptions existantes : ordre d'inscription du jour (sans toucher à updated_at)
+ALTER TABLE queue_entries DISABLE TRIGGER update_queue_entries_updated_at;
+
+UPDATE queue_entries
+SET ticket_date = numbered.day, ticket_number = numbered.number
+FROM (
+    SELECT id,
+           COALESCE(created_at, NOW())::date AS day,
+           ROW_NUMBER() OVER (PARTITION BY BusinessId, COALESCE(created_at, NOW())::date ORDER BY created_at, id) AS number
+    FROM queue_entries
+) AS numbered
+WHERE queue_entries.id = numbered.id;
+
+ALTER TABLE queue_entries ENABLE TRIGGER update_queue_entries_updated_at;
+
+-- Compteurs de tous les jours numérotés, pas seulement CURRENT_DATE : le jour des tickets est celui de BUSINESS_TIMEZONE,
+-- qui peut différer du fuseau de la base. La prochaine inscription repart du plus grand numéro existant de son jour
+-- (les compteurs des jours passés sont supprimés par ticket_counters_cleanup)
+INSERT INTO ticket_counters (BusinessId, day, prefix, last_number)
+SELECT BusinessId, ticket_date, ticket_prefix, MAX(ticket_number)
+FROM queue_entries
+WHERE ticket_date IS NOT NULL AND ticket_number IS NOT NULL
+GROUP BY BusinessId, ticket_date, ticket_prefix;
+
+CREATE UNIQUE INDEX idx_queue_entries_ticket ON queue_entries(BusinessId, ticket_date, ticket_prefix, ticket_number);
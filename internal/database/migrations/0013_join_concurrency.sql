@@ -0,0 +1,15 @@
+-- Inscriptions simultanées : un seul client en attente par numéro de téléphone et par commerce
+-- Doublons existants : l'inscription la plus ancienne est gardée, les autres sont annulées
+UPDATE queue_entries
+SET status = 'cancelled'
+WHERE status = 'waiting'
+AND id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY BusinessId, phone ORDER BY created_at, id) AS rank
+        FROM queue_entries
+        WHERE status = 'waiting'
+    ) AS duplicates
+    WHERE rank > 1
+);
+
+CREATE UNIQUE INDEX idx_queue_entries_waiting_phone ON queue_entries(BusinessId, phone) WHERE status = 'waiting';
@@ -0,0 +1,17 @@
+-- Requêtes rejouées (réseau instable, double appui) : en-tête Idempotency-Key sur les routes qui modifient des données
+-- La réponse de la première requête est conservée et renvoyée telle quelle jusqu'à expires_at
+CREATE TABLE idempotency_keys (
+    UserId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (UserId, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys(expires_at);
@@ -0,0 +1,11 @@
+-- Origine de l'inscription, conservée même si le QR code est supprimé ensuite (qr_token_id passe à NULL)
+-- qr : QR code scanné en magasin, remote : lien partagé, site
+ALTER TABLE queue_entries ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'remote';
+ALTER TABLE queue_entries ADD CONSTRAINT check_queue_entry_source CHECK (source IN ('qr', 'remote'));
+
+-- Inscriptions existantes : QR code encore connu (sans toucher à updated_at)
+ALTER TABLE queue_entries DISABLE TRIGGER update_queue_entries_updated_at;
+
+UPDATE queue_entries SET source = 'qr' WHERE qr_token_id IS NOT NULL;
+
+ALTER TABLE queue_entries ENABLE TRIGGER update_queue_entries_updated_at;
@@ -0,0 +1,11 @@
+-- Idempotency-Key sur les routes d'authentification (sans compte connecté) : une clé est propre à un compte ou à une adresse IP
+-- scope : identifiant du compte, ou "ip:" suivi de l'empreinte SHA-256 de l'adresse IP du client (UserId NULL)
+ALTER TABLE idempotency_keys ADD COLUMN scope VARCHAR(100);
+UPDATE idempotency_keys SET scope = UserId::text;
+ALTER TABLE idempotency_keys ALTER COLUMN scope SET NOT NULL;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ALTER COLUMN UserId DROP NOT NULL;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (scope, idempotency_key);
+
+CREATE INDEX idx_idempotency_keys_user ON idempotency_keys(UserId) WHERE UserId IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Bases où 0012 a déjà été appliquée : ses compteurs n'étaient initialisés que pour CURRENT_DATE (fuseau de la base).
+-- Avec un autre BUSINESS_TIMEZONE, le jour des tickets peut être la veille ou le lendemain : la prochaine inscription
+-- reprenait un numéro existant (idx_queue_entries_ticket, 409). Compteurs recalés sur le plus grand numéro de chaque jour
+INSERT INTO ticket_counters (BusinessId, day, prefix, last_number)
+SELECT BusinessId, ticket_date, ticket_prefix, MAX(ticket_number)
+FROM queue_entries
+WHERE ticket_date IS NOT NULL AND ticket_number IS NOT NULL
+GROUP BY BusinessId, ticket_date, ticket_prefix
+ON CONFLICT (BusinessId, day, prefix) DO UPDATE SET last_number = GREATEST(ticket_counters.last_number, EXCLUDED.last_number);
@@ -0,0 +1,3 @@
+-- Date du dernier changement (ou réinitialisation) du mot de passe, à la seconde comme le claim "iat" des JWT
+-- AuthMiddleware refuse les tokens émis avant : les autres sessions sont déconnectées
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMP WITH TIME ZONE;
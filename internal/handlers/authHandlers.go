@@ -5,12 +5,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"html/template"
-	"io"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/mailer"
 	"github.com/StevenYAMBOS/waitify-api/internal/models"
 	"github.com/StevenYAMBOS/waitify-api/internal/utils"
 	"github.com/google/uuid"
@@ -20,80 +22,89 @@ import (
 // Inscription
 func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Mauvaise requête HTTP (mauvaise méthode).`, http.StatusMethodNotAllowed)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
 	var registerRequest models.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&registerRequest); err != nil {
-		log.Println(`[authHandler.go -> RegisterHandler()] -> Mauvais corps de requête : `, err)
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Corps de la requête invalide.`, http.StatusBadRequest)
+	if !utils.DecodeJSON(w, r, &registerRequest) {
 		return
 	}
 
 	// Validation Email
 	if registerRequest.Email == "" {
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Email requis pour s'inscrire.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.email_required",
+			models.FieldError{Field: "email", Code: "required", Message: "auth.email_required"})
 		return
 	}
 
 	if err := registerRequest.Validate(); err != nil {
-		log.Println("Erreur format email : ", err)
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Erreur format de l'email.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.email_invalid",
+			models.FieldError{Field: "email", Code: "invalid_format", Message: "auth.email_invalid"})
 		return
 	}
 
 	// Validation mot de passe
 	if registerRequest.Password == "" {
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Mot de passe requis pour s'inscrire.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.password_required",
+			models.FieldError{Field: "password", Code: "required", Message: "auth.password_required"})
+		return
+	}
+
+	if err := utils.ValidatePassword(registerRequest.Password); err != nil {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.password_invalid", utils.PasswordFieldError(err))
 		return
 	}
 
-	if err := registerRequest.ValidatePassword(); err != nil {
-		log.Println("Erreur format du mot de passe : ", err)
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Erreur format du mot de passe.`, http.StatusBadRequest)
+	// Photo de profil externe (les photos envoyées passent par POST /users/me/avatar)
+	if err := models.ValidateProfilePictureURL(registerRequest.ProfilePicture); err != nil {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "user.profile_picture_invalid",
+			models.FieldError{Field: "profile_picture", Code: "invalid_format", Message: "user.profile_picture_invalid"})
 		return
 	}
 
 	// Vérifier si l'utilisateur existe
 	var exists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)",
+	err := database.DB.QueryRowContext(r.Context(), "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)",
 		registerRequest.Email).Scan(&exists)
 	if err != nil {
-		log.Println("Erreur vérification si l'utilisateur existe : ", err)
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> Erreur vérification si l'utilisateur existe.`, http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "authHandler.go -> RegisterHandler()", err)
 		return
 	}
 	if exists {
-		log.Println("Erreur email est déjà associé à un compte : ", err)
-		http.Error(w, `[authHandler.go -> RegisterHandler()] -> ERREUR. Cet email est déjà associé à un compte.`, http.StatusConflict)
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeEmailTaken, "auth.email_taken",
+			models.FieldError{Field: "email", Code: "already_exists", Message: "auth.email_taken"})
 		return
 	}
 
 	// Hasher le mot de passe
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerRequest.Password), bcrypt.DefaultCost)
+	hashedPassword, err := utils.HashPassword(registerRequest.Password)
 	if err != nil {
-		log.Println("[authHandler.go -> RegisterHandler()] -> Erreur lors du hashage du mot de passe.", err)
-		http.Error(w, "[authHandler.go -> RegisterHandler()] -> Erreur lors du hashage du mot de passe.", http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "authHandler.go -> RegisterHandler()", err)
 		return
 	}
 
 	// Insertion dans la base de données
 	var user models.User
-	err = database.DB.QueryRow(
-		"INSERT INTO users (id, email, password, profile_picture, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, email, password, profile_picture, created_at, updated_at",
-		uuid.New().String(), registerRequest.Email, string(hashedPassword), registerRequest.ProfilePicture, time.Now(), time.Now(),
-	).Scan(&user.ID, &user.Email, &user.Password, &user.ProfilePicture, &user.CreatedAt, &user.UpdatedAt)
+	err = database.DB.QueryRowContext(r.Context(),
+		"INSERT INTO users (id, email, password, profile_picture, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, email, password, profile_picture, email_verified_at, created_at, updated_at",
+		uuid.New().String(), registerRequest.Email, hashedPassword, registerRequest.ProfilePicture, time.Now(), time.Now(),
+	).Scan(&user.ID, &user.Email, &user.Password, &user.ProfilePicture, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
-		log.Println("Erreur insertion dans la base de données : ", err)
-		http.Error(w, "[authHandler.go -> RegisterHandler()] -> Erreur lors de la création de l'utilisateur.", http.StatusInternalServerError)
+		utils.WriteDBError(w, r, "authHandler.go -> RegisterHandler()", err)
 		return
 	}
 
+	// Email de confirmation : un échec n'empêche pas l'inscription (POST /auth/verify/resend)
+	if err := sendVerificationEmail(r, user.ID, user.Email); err != nil {
+		slog.ErrorContext(r.Context(), "Email de confirmation non envoyé", "user_id", user.ID, "error", err)
+	}
+
 	// Génération du token
-	token, err := utils.GenerateToken(user.ID, user.Email)
+	token, err := utils.GenerateToken(user.ID, user.Email, utils.AMRPassword)
 	if err != nil {
-		http.Error(w, "[authHandler.go -> RegisterHandler()] -> Erreur lors de la génération du token", http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "authHandler.go -> RegisterHandler()", err)
 		return
 	}
 
@@ -102,61 +113,102 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		User:  user,
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusCreated, response)
 }
 
 // Connexion
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> Mauvaise requête HTTP (mauvaise méthode).`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
 	// Décode JSON de la requête
 	var loginRequest models.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&loginRequest); err != nil {
-		log.Println(`[authHandler.go -> LoginHandler()] -> Mauvais corps de requête : `, err)
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> Mauvais corps de requête.`, http.StatusBadRequest)
+	if !utils.DecodeJSON(w, r, &loginRequest) {
 		return
 	}
 
 	// Validation des inputs
 	if loginRequest.Email == "" || loginRequest.Password == "" {
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> L'email et le mot de passe sont requis.`, http.StatusBadRequest)
+		var details []models.FieldError
+		if loginRequest.Email == "" {
+			details = append(details, models.FieldError{Field: "email", Code: "required", Message: "auth.email_required"})
+		}
+		if loginRequest.Password == "" {
+			details = append(details, models.FieldError{Field: "password", Code: "required", Message: "auth.password_required"})
+		}
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.credentials_required", details...)
+		return
+	}
+
+	// Compte ou adresse IP bloqué après trop d'échecs : on ne vérifie même pas le mot de passe
+	accountKey := accountThrottleKey(loginRequest.Email)
+	ipKey := ipThrottleKey(utils.ClientIP(r))
+	remaining, err := loginLockRemaining(r.Context(), accountKey, ipKey)
+	if err != nil {
+		utils.WriteInternalError(w, r, "authHandler.go -> LoginHandler()", err)
+		return
+	}
+	if remaining > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		utils.WriteError(w, r, http.StatusTooManyRequests, models.ErrCodeTooManyAttempts, "auth.too_many_attempts")
 		return
 	}
 
 	// Récupérer les informations de l'utilisateur
 	var user models.User
-	err := database.DB.QueryRow("SELECT id, email, password, created_at, updated_at FROM users WHERE email = $1", loginRequest.Email).
-		Scan(&user.ID, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	var password sql.NullString
+	err = database.DB.QueryRowContext(r.Context(), "SELECT id, email, password, email_verified_at, totp_enabled_at IS NOT NULL, created_at, updated_at FROM users WHERE email = $1", loginRequest.Email).
+		Scan(&user.ID, &user.Email, &password, &user.EmailVerifiedAt, &user.TwoFactorEnabled, &user.CreatedAt, &user.UpdatedAt)
+	user.Password = password.String
 
-	if err == sql.ErrNoRows {
-		log.Println(`[authHandler.go -> LoginHandler()] -> Mauvaises informations de connexion : `, err)
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> Mauvaises informations de connexion.`, http.StatusUnauthorized)
+	if err != nil && err != sql.ErrNoRows {
+		utils.WriteInternalError(w, r, "authHandler.go -> LoginHandler()", err)
 		return
 	}
-	if err != nil {
-		log.Println(`[authHandler.go -> LoginHandler()] -> Erreur base de données : `, err)
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> Erreur base de données.`, http.StatusInternalServerError)
+
+	// Vérification du mot de passe (même réponse et même temps de calcul que pour un email inconnu)
+	if err == sql.ErrNoRows || !password.Valid {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(loginRequest.Password))
+		failLogin(w, r, "", accountKey, ipKey)
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Password)); err != nil {
+		failLogin(w, r, user.Email, accountKey, ipKey)
 		return
 	}
 
-	// Vérification du mot de passe
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Password))
-	if err != nil {
-		log.Println(`[authHandler.go -> LoginHandler()] -> Erreur mot de passe incorrect : `, err)
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> Mot de passe incorrect.`, http.StatusUnauthorized)
+	/*
+		Double authentification : le token de session n'est remis qu'avec le code (POST /auth/2fa/verify)
+		Le compteur d'échecs n'est remis à zéro qu'à ce moment, sinon le mot de passe permettrait de tenter des codes sans limite
+	*/
+	if user.TwoFactorEnabled {
+		challenge, err := utils.GenerateChallengeToken(user.ID, user.Email)
+		if err != nil {
+			utils.WriteInternalError(w, r, "authHandler.go -> LoginHandler()", err)
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, models.LoginChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresIn:         int(utils.ChallengeTokenExpiry().Seconds()),
+		})
+		return
+	}
+
+	if err := resetLoginFailures(r.Context(), accountKey); err != nil {
+		slog.WarnContext(r.Context(), "Compteur d'échecs de connexion non remis à zéro", "error", err)
+	}
+	if err := recordSuccessfulLogin(r.Context(), user.ID); err != nil {
+		utils.WriteInternalError(w, r, "authHandler.go -> LoginHandler()", err)
 		return
 	}
 
 	// Générer le token JWT
-	token, err := utils.GenerateToken(user.ID, user.Email)
+	token, err := utils.GenerateToken(user.ID, user.Email, utils.AMRPassword)
 	if err != nil {
-		log.Println(`[authHandler.go -> LoginHandler()] -> Erreur lors de la génération du token : `, err)
-		http.Error(w, `[authHandler.go -> LoginHandler()] -> Erreur lors de la génération du token.`, http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "authHandler.go -> LoginHandler()", err)
 		return
 	}
 
@@ -165,7 +217,35 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		User:  user,
 	}
 
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// Hash comparé quand le compte n'existe pas, pour que le temps de réponse ne le révèle pas
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("waitify-dummy-password"), 12)
+
+/*
+Échec de connexion : 401, enregistré pour le compte et l'adresse IP
+Quand le compte vient d'être bloqué, son propriétaire est prévenu par email (lien vers "mot de passe oublié")
+*/
+func failLogin(w http.ResponseWriter, r *http.Request, email, accountKey, ipKey string) {
+	lock, err := recordLoginFailure(r.Context(), accountKey, ipKey)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Échec de connexion non enregistré", "error", err)
+	}
+
+	if lock > 0 {
+		slog.WarnContext(r.Context(), "Connexions bloquées après plusieurs échecs", "account", accountKey, "ip", ipKey, "duration", lock.String())
+		if email != "" {
+			mailer.SendAsync(r.Context(), mailer.Message{
+				To:      email,
+				Subject: utils.T(r, "mail.login_locked.subject"),
+				Text:    utils.T(r, "mail.login_locked.body", formatDuration(r, lock), frontendURL+forgotPasswordPage),
+				Type:    "login_locked",
+			})
+		}
+	}
+
+	utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidCredentials, "auth.invalid_credentials")
 }
 
 // Test connexion Google
@@ -173,11 +253,11 @@ func TestHandler(w http.ResponseWriter, r *http.Request) {
 	// Parsing an HTML document present in the current directory.
 	t, err := template.ParseFiles("index.html")
 	if err != nil {
-		log.Print(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "authHandler.go -> TestHandler()", err)
 		return
 	}
 	// serving the parsed HTML document
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	t.Execute(w, nil)
 }
 
@@ -196,17 +276,18 @@ func GoogleLoginHandler(w http.ResponseWriter, r *http.Request) {
 func GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	state := r.URL.Query().Get("state")
 	if state != "randomstate" {
-		http.Error(w, `[authHandlers.go -> GoogleCallback()] -> Les états ne matchent pas. "randomstate" manquant !`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusBadRequest, models.ErrCodeBadRequest, "auth.oauth_state_invalid")
+		return
 	}
 	code := r.URL.Query().Get("code")
 
-	googleConnection := models.GoogleConfig()
+	googleConnection := models.AppConfig.GoogleLoginConfig
 
 	// Exchanging the code for an access token
 	token, err := googleConnection.Exchange(context.Background(), code)
 	if err != nil {
-		log.Println(`[authHandler.go -> GoogleCallback()] -> Erreur lors de l'échange code <-> token : `, err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.WarnContext(r.Context(), "Erreur lors de l'échange code <-> token Google", "error", err)
+		utils.WriteError(w, r, http.StatusBadRequest, models.ErrCodeBadRequest, "auth.oauth_code_invalid")
 		return
 	}
 
@@ -217,7 +298,7 @@ func GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	// Récupérer les informations publiques de l'utilisateur depuis l'API GCP
 	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		utils.WriteInternalError(w, r, "authHandler.go -> GoogleCallback()", err)
 		return
 	}
 
@@ -229,7 +310,7 @@ func GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	// Lire le corps JSON en le décodant
 	err = json.NewDecoder(resp.Body).Decode(&v)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "authHandler.go -> GoogleCallback()", err)
 		return
 	}
 
@@ -239,87 +320,41 @@ func GoogleCallback(w http.ResponseWriter, r *http.Request) {
 		User:  v,
 	}
 
-	// Vérifier si l'utilisateur existe
-	var exists bool
-
 	// Est-ce que l'utilisateur existe ?
-	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)",
-		v.Email).Scan(&exists)
-	if err != nil {
-		log.Println("Erreur vérification si l'utilisateur existe : ", err)
-		http.Error(w, `[authHandler.go -> GoogleCallback()] -> Erreur vérification si l'utilisateur existe.`, http.StatusInternalServerError)
+	var existingID uuid.UUID
+	err = database.DB.QueryRowContext(r.Context(), "SELECT id FROM users WHERE email = $1", v.Email).Scan(&existingID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.WriteInternalError(w, r, "authHandler.go -> GoogleCallback()", err)
 		return
 	}
-	if exists {
+	if err == nil {
 		// Si l'utilisateur existe on renvoie le token de connexion
-		log.Println("Utilisateur connecté avec succès.")
-		w.WriteHeader(http.StatusAccepted)
-		json.NewEncoder(w).Encode(response)
-		return
-	} else if !exists { // Sinon
-		// Insertion dans la base de données
-		var user models.User
-		googleErr := database.DB.QueryRow(
-			"INSERT INTO users (id, google_id, email, first_name, last_name, profile_picture, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, google_id, email, first_name, last_name, profile_picture, created_at, updated_at",
-			uuid.New().String(), v.ID, v.Email, v.GivenName, v.FamilyName, v.Picture, time.Now(), time.Now(),
-		).Scan(&user.ID, &user.Google_id, &user.Email, &user.FirstName, &user.LastName, &user.ProfilePicture, &user.CreatedAt, &user.UpdatedAt)
-		log.Println("Utilisateur créé avec succès.")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(response)
-
-		if googleErr != nil {
-			log.Println("UTILISATEUR :", v)
-			log.Println("[authHandler.go -> GoogleCallback()] -> Erreur insertion des données de l'utilisateur dans la base de données, vérifier le format des données envoyées : ", googleErr)
-			http.Error(w, googleErr.Error(), http.StatusInternalServerError)
+		if err := recordSuccessfulLogin(r.Context(), existingID); err != nil {
+			utils.WriteInternalError(w, r, "authHandler.go -> GoogleCallback()", err)
 			return
 		}
-	}
-}
-
-// Health check
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	log.Println("Health check !")
-	io.WriteString(w, "Health check!\n")
-}
-
-// Récupérer les informations de l'utilisateur
-func ProfileHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, `[authHandler.go -> ProfileHandler()] -> Mauvaise requête HTTP (mauvaise méthode).`, http.StatusBadRequest)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-
-	// Vérification de l'autorisation
-	tokenString := r.Header.Get("Authorization")
-	if tokenString == "" {
-		http.Error(w, `[authHandler.go -> ProfileHandler()] -> Header d'autorisation "Authorization" requis.`, http.StatusUnauthorized)
+		slog.InfoContext(r.Context(), "Utilisateur Google connecté avec succès.")
+		utils.WriteJSON(w, http.StatusAccepted, response)
 		return
 	}
 
-	// Retirer le préfixe "Bearer "
-	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-		tokenString = tokenString[7:]
-	}
-
-	// Validation du token
-	claims, err := utils.ValidateToken(tokenString)
-	if err != nil {
-		log.Println(`[authHandler.go -> ProfileHandler()] -> Token invalide : `, err)
-		http.Error(w, `[authHandler.go -> ProfileHandler()] -> Token invalide.`, http.StatusUnauthorized)
-		return
+	// Sinon insertion dans la base de données (email déjà confirmé auprès de Google)
+	var emailVerifiedAt *time.Time
+	if v.VerifiedEmail {
+		now := time.Now()
+		emailVerifiedAt = &now
 	}
 
-	// Récupéreration de l'utilisateur
 	var user models.User
-	err = database.DB.QueryRow("SELECT id, email, created_at, updated_at FROM users WHERE id = $1", claims.UserID).
-		Scan(&user.ID, &user.Email, &user.CreatedAt, &user.UpdatedAt)
-
+	err = database.DB.QueryRowContext(r.Context(),
+		"INSERT INTO users (id, google_id, email, first_name, last_name, profile_picture, email_verified_at, created_at, updated_at, last_login) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $8) RETURNING id, google_id, email, first_name, last_name, profile_picture, created_at, updated_at",
+		uuid.New().String(), v.ID, v.Email, v.GivenName, v.FamilyName, v.Picture, emailVerifiedAt, time.Now(), time.Now(),
+	).Scan(&user.ID, &user.Google_id, &user.Email, &user.FirstName, &user.LastName, &user.ProfilePicture, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
-		log.Println(`[authHandler.go -> ProfileHandler()] -> Utilisateur non trouvé, erreur : `, err)
-		http.Error(w, `[authHandler.go -> ProfileHandler()] -> Utilisateur non trouvé`, http.StatusNotFound)
+		utils.WriteDBError(w, r, "authHandler.go -> GoogleCallback()", err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(user)
+	slog.InfoContext(r.Context(), "Utilisateur Google créé avec succès.", "user_id", user.ID)
+	utils.WriteJSON(w, http.StatusCreated, response)
 }
@@ -1,40 +1,84 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
 	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/i18n"
 	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
 	"github.com/StevenYAMBOS/waitify-api/internal/utils"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-// Récupérer les informations d'une entreprise
-func GetBusinessHandler(w http.ResponseWriter, r *http.Request) {
-	// Réponse JSON
-	w.Header().Set("Content-Type", "application/json")
+var (
+	// Durée pendant laquelle une entreprise supprimée peut être restaurée, avant la purge
+	businessRetention time.Duration
+
+	// Fuseau horaire des commerces (BUSINESS_TIMEZONE) : la journée des tickets et des compteurs commence à minuit, heure locale
+	businessLocation = time.Local
+)
+
+func InitBusinesses(cfg *config.Config) {
+	businessRetention = cfg.Businesses.Retention
+	if location, err := time.LoadLocation(cfg.Businesses.Timezone); err == nil {
+		businessLocation = location
+	}
+}
 
+// Début de la journée en cours des commerces (minuit dans BUSINESS_TIMEZONE), indépendant du fuseau de la base de données
+func businessDayStart(now time.Time) time.Time {
+	local := now.In(businessLocation)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, businessLocation)
+}
+
+// Récupérer les informations d'une entreprise (ETag : version à renvoyer dans If-Match pour la modifier)
+func GetBusinessHandler(w http.ResponseWriter, r *http.Request) {
 	// Méthode HTTP
 	if r.Method != http.MethodGet {
-		http.Error(w, `Mauvaise requête HTTP.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
-	// Décode JSON de la requête
-	var business models.Business
+	// Récupération dans la base de données
+	business, err := fetchBusiness(r.Context(), r.PathValue("id"))
+	if err != nil {
+		utils.WriteDBError(w, r, "businessHandler.go -> GetBusinessHandler()", err)
+		return
+	}
 
-	// Récupérer l'ID de l'entreprise depuis l'URL
-	IDParam := r.PathValue("id")
+	response := models.AddBusinessResponse{
+		Response: utils.T(r, "business.fetched"),
+		Business: business,
+	}
 
-	// Récupération dans la base de données
-	err := database.DB.QueryRow(`
-		SELECT id, UserId, name, business_type, phone_number, address, city, zip_code, country, qr_code_token, is_queue_active, is_queue_paused, created_at, updated_at
-		FROM businesses WHERE id = $1
-`, IDParam).Scan(
+	w.Header().Set("ETag", utils.ETag(business.UpdatedAt))
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// Entreprise complète, paramètres de la file compris (lien signé pour le logo)
+func fetchBusiness(ctx context.Context, businessID any) (models.Business, error) {
+	var business models.Business
+	var openingHours []byte
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT id, UserId, name, business_type, COALESCE(phone_number, ''), COALESCE(address, ''), COALESCE(city, ''),
+			COALESCE(zip_code, ''), COALESCE(country, ''), qr_code_token,
+			COALESCE(average_service_time, 300), COALESCE(is_queue_active, false), COALESCE(is_queue_paused, false),
+			COALESCE(max_queue_size, 50), opening_hours, COALESCE(custom_message, ''),
+			COALESCE(sms_notifications_enabled, true), COALESCE(auto_advance_enabled, true), COALESCE(client_timeout_minutes, 5),
+			default_language, ticket_prefix, COALESCE(logo, ''), created_at, updated_at, deleted_at
+		FROM businesses WHERE id = $1`, businessID).Scan(
 		&business.ID,
 		&business.UserID,
 		&business.Name,
@@ -45,52 +89,105 @@ func GetBusinessHandler(w http.ResponseWriter, r *http.Request) {
 		&business.ZipCode,
 		&business.Country,
 		&business.QRCodeToken,
+		&business.AverageServiceTime,
 		&business.IsQueueActive,
 		&business.IsQueuePaused,
+		&business.MaxQueueSize,
+		&openingHours,
+		&business.CustomMessage,
+		&business.SmsNotificationsEnabled,
+		&business.AutoAdvanceEnabled,
+		&business.ClientTimeoutMinutes,
+		&business.DefaultLanguage,
+		&business.TicketPrefix,
+		&business.Logo,
 		&business.CreatedAt,
 		&business.UpdatedAt,
+		&business.DeletedAt,
 	)
 	if err != nil {
-		log.Println(`Erreur lors de la récupération des informations de l'entreprise : `, err)
-		http.Error(w, "Erreur lors de la récupération de l'entreprise : "+err.Error(), http.StatusInternalServerError)
-		return
+		return business, err
 	}
-
-	response := models.AddBusinessResponse{
-		Response: "Informations de l'entreprise récupérées avec succès.",
-		Business: business,
+	if len(openingHours) > 0 {
+		if err := json.Unmarshal(openingHours, &business.OpeningHours); err != nil {
+			return business, fmt.Errorf("[businessHandler.go -> fetchBusiness()] -> Horaires d'ouverture illisibles : %w", err)
+		}
 	}
+	business.Logo = storage.PublicURL(ctx, business.Logo)
+	return business, nil
+}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+// Tris et filtres de la liste des entreprises d'un utilisateur
+var businessListSpec = utils.ListSpec{
+	Sorts: map[string]utils.ListSort{
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+		"updated_at": {Column: "updated_at", Type: "timestamptz"},
+		"name":       {Column: "lower(name)", Type: "text"},
+		"city":       {Column: "lower(COALESCE(city, ''))", Type: "text"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+	Filters: map[string]utils.ListFilter{
+		"status": {
+			Values:     []string{"active", "archived"},
+			Conditions: map[string]string{"active": "deleted_at IS NULL", "archived": "deleted_at IS NOT NULL"},
+			Default:    "active",
+		},
+		"city":          {Column: "lower(city)", Lower: true},
+		"business_type": {Column: "business_type", Values: businessTypeCodes()},
+		"created_at":    {Column: "created_at", Kind: utils.FilterRange},
+	},
 }
 
-// Récupérer toutes les entreprises d'un utilisateur
-func GetBusinessesHandler(w http.ResponseWriter, r *http.Request) {
-	// Réponse JSON
-	w.Header().Set("Content-Type", "application/json")
+func businessTypeCodes() []string {
+	codes := make([]string, len(models.BusinessTypes))
+	for i, businessType := range models.BusinessTypes {
+		codes[i] = businessType.Code
+	}
+	return codes
+}
 
+/*
+Récupérer les entreprises d'un utilisateur, paginées (cf. utils.ParseList)
+Filtres : status (active par défaut, archived : supprimées, encore restaurables), city, business_type, created_at_from / created_at_to
+*/
+func GetBusinessesHandler(w http.ResponseWriter, r *http.Request) {
 	// Méthode HTTP
 	if r.Method != http.MethodGet {
-		log.Println(`Mauvaise requête HTTP.`)
-		http.Error(w, `Mauvaise requête HTTP.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
-	// Récupérer l'ID de l'utilisateur depuis l'URL
+	list, ok := utils.ParseList(w, r, businessListSpec)
+	if !ok {
+		return
+	}
+
+	// Récupérer l'ID de l'utilisateur depuis l'URL : seules ses propres entreprises sont visibles
 	IDParam := r.PathValue("id")
+	claims := utils.ClaimsFromContext(r.Context())
+	if userID, err := uuid.Parse(IDParam); err != nil || userID != claims.UserID {
+		utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "business.owner_only")
+		return
+	}
 
 	// Récupération dans la base de données
-	rows, err := database.DB.Query("SELECT id, UserId, name, business_type, phone_number, address, city, zip_code, country, qr_code_token, created_at, updated_at FROM businesses WHERE UserId=$1", IDParam)
+	query, args := list.Query(`
+		SELECT id, UserId, name, business_type, phone_number, address, city, zip_code, country, qr_code_token, COALESCE(logo, ''),
+			created_at, updated_at, deleted_at, `+list.SortValue()+`
+		FROM businesses WHERE UserId = $1`, claims.UserID)
+	rows, err := database.DB.QueryContext(r.Context(), query, args...)
 	if err != nil {
-		log.Println(`Erreur lors de la récupération des entreprises de l'utilisateur : `, err)
-		http.Error(w, `Erreur lors de la récupération des entreprises de l'utilisateur : `+err.Error(), http.StatusBadRequest)
+		utils.WriteDBError(w, r, "businessHandler.go -> GetBusinessesHandler()", err)
+		return
 	}
 	defer rows.Close()
 
-	businesses := []models.Business{}
+	response := models.ListResponse[models.Business]{Data: []models.Business{}}
 
 	for rows.Next() {
 		var business models.Business
+		var sortValue string
 		if err := rows.Scan(&business.ID,
 			&business.UserID,
 			&business.Name,
@@ -101,137 +198,171 @@ func GetBusinessesHandler(w http.ResponseWriter, r *http.Request) {
 			&business.ZipCode,
 			&business.Country,
 			&business.QRCodeToken,
+			&business.Logo,
 			&business.CreatedAt,
 			&business.UpdatedAt,
+			&business.DeletedAt,
+			&sortValue,
 		); err != nil {
-			log.Println(`Erreur lors du scan : `, err)
-			log.Fatal(err)
+			utils.WriteInternalError(w, r, "businessHandler.go -> GetBusinessesHandler()", err)
+			return
+		}
+		if !list.Row(sortValue, business.ID.String()) {
+			break
 		}
-		businesses = append(businesses, business)
+		business.Logo = storage.PublicURL(r.Context(), business.Logo)
+		response.Data = append(response.Data, business)
 	}
 	if err := rows.Err(); err != nil {
-		log.Println(`Erreur après le scan : `, err)
-		log.Fatal(err)
+		utils.WriteInternalError(w, r, "businessHandler.go -> GetBusinessesHandler()", err)
+		return
+	}
+	rows.Close()
+
+	response.Pagination = list.Page()
+	if list.First() && !response.Pagination.HasMore {
+		total := len(response.Data)
+		response.Pagination.Total = &total
+	} else if list.First() {
+		var total int
+		query, args := list.CountQuery("SELECT COUNT(*) FROM businesses WHERE UserId = $1", IDParam)
+		if err := database.DB.QueryRowContext(r.Context(), query, args...).Scan(&total); err != nil {
+			utils.WriteInternalError(w, r, "businessHandler.go -> GetBusinessesHandler()", err)
+			return
+		}
+		response.Pagination.Total = &total
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// Types de commerce avec leur libellé dans la langue de la requête et leurs paramètres par défaut (route publique)
+func BusinessTypesHandler(w http.ResponseWriter, r *http.Request) {
+	response := models.BusinessTypesResponse{BusinessTypes: make([]models.BusinessType, len(models.BusinessTypes))}
+	for i, businessType := range models.BusinessTypes {
+		businessType.Label = utils.T(r, "business_type."+businessType.Code)
+		response.BusinessTypes[i] = businessType
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(businesses)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	utils.WriteJSON(w, http.StatusOK, response)
 }
 
 // Créer une entreprise + QR Code
 func AddBusinessHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `Mauvaise requête HTTP (mauvaise méthode).`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
-	err := r.ParseMultipartForm(32 << 10) // 32 MB
+	// 1 Mo gardé en mémoire, le reste sur disque ; la taille totale est plafonnée par BodyLimitMiddleware
+	err := r.ParseMultipartForm(1 << 20)
 	if err != nil {
-		log.Fatal(err)
+		utils.WriteBodyError(w, r, err)
+		return
 	}
 
-	var size, content string = r.FormValue("size"), r.FormValue("content")
-	var codeData []byte
-
+	// Propriétaire : l'utilisateur connecté (un champ "UserId" du formulaire est ignoré)
+	UserID := utils.ClaimsFromContext(r.Context()).UserID
 	name := r.FormValue("name")
-	UserID := r.FormValue("UserId")
 	businessType := r.FormValue("business_type")
 	phoneNumber := r.FormValue("phone_number")
 	address := r.FormValue("address")
 	city := r.FormValue("city")
 	zipCode := r.FormValue("zip_code")
 	country := r.FormValue("country")
+	defaultLanguage := r.FormValue("default_language")
 
 	/* -------------- Vérifications -------------- */
 
 	// Nom de l'entreprise
 	if name == "" {
-		http.Error(w, `Le nom de l'entreprise doit avoir au moins 1 caractère.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.name_required",
+			models.FieldError{Field: "name", Code: "required", Message: "business.name_required"})
 		return
 	}
 
 	// Numéro de téléphone
 	if err := models.ValidateBusinessPhoneNumber(phoneNumber); err != nil {
-		log.Println("Erreur format numéro de téléphone : ", err)
-		http.Error(w, `Erreur format numéro de téléphone.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.phone_invalid",
+			models.FieldError{Field: "phone_number", Code: "invalid_format", Message: "business.phone_invalid"})
 		return
 	}
 
-	// Validation du type
-	if err := models.ValidateBusinessType(businessType); err != nil {
-		http.Error(w, `Erreur format du type de commerce : `+err.Error()+"Requête reçue: "+businessType, http.StatusBadRequest)
+	// Validation du type (ses paramètres de file d'attente sont appliqués à la création)
+	typeDefaults, ok := models.LookupBusinessType(businessType)
+	if !ok {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.type_invalid",
+			models.FieldError{Field: "business_type", Code: "invalid_choice", Message: "business.type_invalid"})
 		return
 	}
 
 	// Validation de l'adresse
 	if address == "" || len(address) >= 100 {
-		http.Error(w, `L'adresse de l'entreprise doit être comprise 1 et 100 caractères.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.address_length",
+			models.FieldError{Field: "address", Code: "invalid_length", Message: "business.address_length"})
 		return
 	}
 
 	// Validation de la ville
 	if city == "" || len(city) >= 100 {
-		http.Error(w, `La ville de l'entreprise doit être comprise 1 et 100 caractères.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.city_length",
+			models.FieldError{Field: "city", Code: "invalid_length", Message: "business.city_length"})
 		return
 	}
 
 	// Validation nom de l'entreprise
 	if zipCode == "" || len(zipCode) >= 100 {
-		http.Error(w, `Le code postal de l'entreprise doit être comprise 1 et 100 caractères.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.zip_code_length",
+			models.FieldError{Field: "zip_code", Code: "invalid_length", Message: "business.zip_code_length"})
 		return
 	}
 
-	// Vérifier si l'utilisateur existe
-	var exists bool
-	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)",
-		UserID).Scan(&exists)
-	if err != nil {
-		http.Error(w, `Erreur vérification si l'utilisateur existe : `+err.Error(), http.StatusInternalServerError)
-		return
+	// Langue des SMS et de la page client
+	if defaultLanguage == "" {
+		defaultLanguage = i18n.DefaultLanguage
 	}
-	if !exists {
-		log.Println("L'utilisateur n'existe pas : ", err)
-		http.Error(w, `ERREUR. L'utilisateur n'existe pas ! `+err.Error(), http.StatusConflict)
+	if !i18n.IsSupported(defaultLanguage) {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "business.language_invalid",
+			models.FieldError{Field: "default_language", Code: "invalid_choice", Message: "business.language_invalid"})
 		return
 	}
 
-	// Insertion en base de données
-	rows, err := database.DB.Query("INSERT INTO businesses (id, UserId, name, business_type, phone_number, address, city, zip_code, country, qr_code_token, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)", uuid.New().String(), UserID, name, businessType, phoneNumber, address, city, zipCode, country, uuid.New().String(), time.Now(), time.Now())
-	if err != nil {
-		http.Error(w, "Erreur lors de la création de l'entreprise : "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
 	/* -------------- Génération du QR Code -------------- */
 
-	if content == "" {
-		w.WriteHeader(400)
-		json.NewEncoder(w).Encode(
-			"Impossible de déterminer le contenu souhaité du code QR.",
-		)
+	// Le QR Code pointe toujours vers la page client de l'entreprise (APP_QUEUE_URL + token), le champ "content" est ignoré
+	size, ok := qrCodeSize(w, r)
+	if !ok {
 		return
 	}
+	qrCodeToken := uuid.New().String()
 
-	qrCodeSize, err := strconv.Atoi(size)
-	if err != nil || size == "" {
-		w.WriteHeader(400)
-		json.NewEncoder(w).Encode("Impossible de déterminer la taille souhaitée du code QR.")
+	// Insertion en base de données (le token principal est aussi enregistré dans l'historique des QR codes)
+	_, err = database.DB.ExecContext(r.Context(), `
+		WITH business AS (
+			INSERT INTO businesses (id, UserId, name, business_type, phone_number, address, city, zip_code, country, qr_code_token, default_language,
+				average_service_time, client_timeout_minutes, max_queue_size, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			RETURNING id, qr_code_token
+		)
+		INSERT INTO qr_tokens (BusinessId, token) SELECT id, qr_code_token FROM business`,
+		uuid.New().String(), UserID, name, businessType, phoneNumber, address, city, zipCode, country, qrCodeToken, defaultLanguage,
+		typeDefaults.AverageServiceTime, typeDefaults.ClientTimeoutMinutes, typeDefaults.MaxQueueSize, time.Now(), time.Now())
+	if err != nil {
+		utils.WriteDBError(w, r, "businessHandler.go -> AddBusinessHandler()", err)
 		return
 	}
 
-	qrCode := utils.QRCode{Content: content, Size: qrCodeSize}
-	codeData, err = qrCode.Generate()
+	qrCode := utils.QRCode{Content: queueLink(qrCodeToken), Size: size}
+	codeData, err := qrCode.Generate()
 	if err != nil {
-		w.WriteHeader(400)
-		json.NewEncoder(w).Encode(
-			fmt.Sprintf("Impossible de générer le code QR. %v", err),
-		)
+		utils.WriteInternalError(w, r, "businessHandler.go -> AddBusinessHandler()", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "image/png")
-	w.Write(codeData)
 	w.WriteHeader(http.StatusCreated)
+	w.Write(codeData)
 }
 
 /*
@@ -289,7 +420,7 @@ func AddBusinessHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Vérifier si l'utilisateur existe
 	var exists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)",
+	err := database.DB.QueryRowContext(r.Context(), "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)",
 		business.UserID).Scan(&exists)
 	if err != nil {
 		http.Error(w, `[businessHandler.go -> AddBusinessHandler()] -> Erreur vérification si l'utilisateur existe : `+err.Error(), http.StatusInternalServerError)
@@ -302,7 +433,7 @@ func AddBusinessHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Insertion dans la base de données
-	err = database.DB.QueryRow(
+	err = database.DB.QueryRowContext(r.Context(),
 		"INSERT INTO businesses (id, UserId, name, business_type, phone_number, address, city, zip_code, country, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, UserId, name, business_type, phone_number, address, city, zip_code, country, created_at, updated_at",
 		uuid.New().String(), business.UserID, business.Name, business.BusinessType, business.PhoneNumber, business.Address, business.City, business.ZipCode, business.Country, time.Now(), time.Now(),
 	).Scan(&business.ID, &business.UserID, &business.Name, &business.BusinessType, &business.PhoneNumber, &business.Address, &business.City, &business.ZipCode, &business.Country, &business.CreatedAt, &business.UpdatedAt)
@@ -322,218 +453,398 @@ func AddBusinessHandler(w http.ResponseWriter, r *http.Request) {
 }
 */
 
-// Mettre à jour l'entreprise
+/*
+Mettre à jour l'entreprise (propriétaire uniquement)
+Seuls les champs présents sont modifiés : informations, paramètres de la file, horaires, message personnalisé
+Les champs en lecture seule (id, UserId, qr_code_token, is_queue_active, is_queue_paused, is_active, created_at) sont ignorés
+Concurrence optimiste : la modification est refusée (412) si l'entreprise a changé depuis la lecture,
+version transmise dans If-Match (ETag de GET /business/{id}) ou à défaut dans le champ updated_at
+*/
 func UpdateBusinessHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPatch {
-		http.Error(w, `Mauvaise requête HTTP (mauvaise méthode).`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
 	// Décode JSON de la requête
 	var business *models.UpdatedBusiness
 
-	if err := json.NewDecoder(r.Body).Decode(&business); err != nil {
-		log.Println(`Mauvais corps de requête : `, err)
-		http.Error(w, `Mauvais corps de requête.`, http.StatusBadRequest)
+	if !utils.DecodeJSON(w, r, &business) {
 		return
 	}
-
-	// Récupérer l'ID de l'entreprise depuis l'URL
-	IDParam := r.PathValue("id")
-	log.Println(`ID : `, IDParam)
-
-	// Vérifier si l'entreprise existe
-	var businessExists bool
-	errBusinesses := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM businesses WHERE id = $1)",
-		IDParam).Scan(&businessExists)
-	if errBusinesses != nil {
-		http.Error(w, `Erreur vérification de l'existance de l'entreprise : `+errBusinesses.Error(), http.StatusInternalServerError)
+	if business == nil {
+		utils.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidBody, "error.invalid_body")
 		return
 	}
-	if !businessExists {
-		log.Println("L'entreprise avec cet 'id' n'existe pas : ", errBusinesses)
-		http.Error(w, `ERREUR. L'entreprise n'existe pas ! `+errBusinesses.Error(), http.StatusConflict)
+
+	businessID, ok := requireBusinessOwner(w, r, "businessHandler.go -> UpdateBusinessHandler()")
+	if !ok {
 		return
 	}
 
-	// Récupération dans la base de données
-	/*
-		var fetchedBusiness models.UpdatedBusiness
-			err := database.DB.QueryRow(`
-									SELECT id, UserId, name, business_type, phone_number, address, city, zip_code, country, created_at, updated_at
-									FROM businesses WHERE id = $1
-							`, IDParam).Scan(
-				&business.ID,
-				&business.UserID,
-				&business.Name,
-				&business.BusinessType,
-				&business.PhoneNumber,
-				&business.Address,
-				&business.City,
-				&business.ZipCode,
-				&business.Country,
-				&business.CreatedAt,
-				&business.UpdatedAt,
-			)
-			if err != nil {
-				log.Println(`Erreur lors de la récupération des informations de l'entreprise : `, err)
-				http.Error(w, "Erreur lors de la récupération de l'entreprise : "+err.Error(), http.StatusInternalServerError)
-				return
-			}
+	versions, conditional := utils.IfMatchVersions(r)
+	if !conditional && business.UpdatedAt != nil {
+		versions, conditional = []time.Time{*business.UpdatedAt}, true
+	}
 
-			log.Println(`Informations récupérées : `, business.PhoneNumber)
-	*/
+	/* -------------- Vérifications -------------- */
 
-	// Vérification des champs
-	/*
-		// updatedFields := make(map[string]*string)
-			if business.Name != "" {
-				fmt.Println("Le champ 'Name' n'est pas vide !")
-			}
-				if business.BusinessType != "" {
-					updatedFields["business_type"] = business.BusinessType
-				}
-				if business.PhoneNumber != "" {
-					updatedFields["phone_number"] = business.PhoneNumber
-				}
-				if business.Address != "" {
-					updatedFields["address"] = business.Address
-				}
-				if business.City != "" {
-					updatedFields["city"] = business.City
-				}
-				if business.ZipCode != "" {
-					updatedFields["zip_code"] = business.ZipCode
-				}
-				if business.Country != "" {
-					updatedFields["country"] = business.Country
-				}
-				updatedFields["updated_at"] = time.Now()
-	*/
+	var details []models.FieldError
+	var sets []string
+	args := []any{businessID}
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	text := func(field, column, message string, value *string, minLength, maxLength int) {
+		trimmed := strings.TrimSpace(*value)
+		if length := utf8.RuneCountInString(trimmed); length < minLength || length > maxLength {
+			details = append(details, models.FieldError{Field: field, Code: "invalid_length", Message: message, Args: []any{minLength, maxLength}})
+		}
+		set(column, nullIfEmpty(trimmed))
+	}
+	number := func(field, column string, value *int, minValue, maxValue int) {
+		if *value < minValue || *value > maxValue {
+			details = append(details, models.FieldError{Field: field, Code: "out_of_range", Message: "business.setting_range", Args: []any{minValue, maxValue}})
+		}
+		set(column, *value)
+	}
 
-	// Insertion dans la base de données
-	updt, err := database.DB.Exec(`UPDATE businesses SET name=$2, business_type=$3, phone_number=$4, address=$5, city=$6, zip_code=$7, country=$8, updated_at=$9 WHERE id=$1 RETURNING *;`, IDParam, &business.Name, &business.BusinessType, &business.PhoneNumber, &business.Address, &business.City, &business.ZipCode, &business.Country, time.Now())
-	if err != nil {
-		http.Error(w, "Erreur lors de la modification de l'entreprise : "+err.Error(), http.StatusInternalServerError)
+	if business.Name != nil {
+		text("name", "name", "business.name_length", business.Name, 1, models.BusinessNameMaxLength)
+	}
+	if business.BusinessType != nil {
+		if err := models.ValidateBusinessType(*business.BusinessType); err != nil {
+			details = append(details, models.FieldError{Field: "business_type", Code: "invalid_choice", Message: "business.type_invalid"})
+		}
+		set("business_type", *business.BusinessType)
+	}
+	if business.PhoneNumber != nil {
+		if err := models.ValidateBusinessPhoneNumber(*business.PhoneNumber); err != nil {
+			details = append(details, models.FieldError{Field: "phone_number", Code: "invalid_format", Message: "business.phone_invalid"})
+		}
+		set("phone_number", *business.PhoneNumber)
+	}
+	if business.Address != nil {
+		text("address", "address", "business.field_length", business.Address, 1, 99)
+	}
+	if business.City != nil {
+		text("city", "city", "business.field_length", business.City, 1, 99)
+	}
+	if business.ZipCode != nil {
+		text("zip_code", "zip_code", "business.field_length", business.ZipCode, 1, 10)
+	}
+	if business.Country != nil {
+		text("country", "country", "business.field_length", business.Country, 1, models.BusinessCountryMaxLength)
+	}
+	if business.DefaultLanguage != nil {
+		if !i18n.IsSupported(*business.DefaultLanguage) {
+			details = append(details, models.FieldError{Field: "default_language", Code: "invalid_choice", Message: "business.language_invalid"})
+		}
+		set("default_language", *business.DefaultLanguage)
+	}
+	if business.TicketPrefix != nil {
+		if err := models.ValidateTicketPrefix(*business.TicketPrefix); err != nil {
+			details = append(details, models.FieldError{Field: "ticket_prefix", Code: "invalid_format", Message: "business.ticket_prefix_invalid"})
+		}
+		set("ticket_prefix", *business.TicketPrefix)
+	}
+	if business.AverageServiceTime != nil {
+		number("average_service_time", "average_service_time", business.AverageServiceTime, models.BusinessMinServiceTime, models.BusinessMaxServiceTime)
+	}
+	if business.MaxQueueSize != nil {
+		number("max_queue_size", "max_queue_size", business.MaxQueueSize, models.BusinessMinQueueSize, models.BusinessMaxQueueSize)
+	}
+	if business.ClientTimeoutMinutes != nil {
+		number("client_timeout_minutes", "client_timeout_minutes", business.ClientTimeoutMinutes, models.BusinessMinClientTimeout, models.BusinessMaxClientTimeout)
+	}
+	if business.CustomMessage != nil {
+		text("custom_message", "custom_message", "business.custom_message_length", business.CustomMessage, 0, models.BusinessCustomMessageMaxLength)
+	}
+	if business.OpeningHours != nil {
+		if err := business.OpeningHours.Validate(); err != nil {
+			slog.InfoContext(r.Context(), "Horaires d'ouverture invalides", "business_id", businessID, "error", err)
+			details = append(details, models.FieldError{Field: "opening_hours", Code: "invalid_format", Message: "business.opening_hours_invalid"})
+		}
+		hours, err := json.Marshal(business.OpeningHours)
+		if err != nil {
+			utils.WriteInternalError(w, r, "businessHandler.go -> UpdateBusinessHandler()", err)
+			return
+		}
+		set("opening_hours", string(hours))
+	}
+	if business.SmsNotificationsEnabled != nil {
+		set("sms_notifications_enabled", *business.SmsNotificationsEnabled)
+	}
+	if business.AutoAdvanceEnabled != nil {
+		set("auto_advance_enabled", *business.AutoAdvanceEnabled)
+	}
+
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
 		return
 	}
 
-	// check how many rows affected
-	rowsAffected, err := updt.RowsAffected()
+	/* -------------- Mise à jour -------------- */
 
+	stale := false
+	if len(sets) > 0 {
+		query := "UPDATE businesses SET " + strings.Join(sets, ", ") + ", updated_at = NOW() WHERE id = $1"
+		if conditional {
+			formatted := make([]string, len(versions))
+			for i, version := range versions {
+				formatted[i] = version.UTC().Format(time.RFC3339Nano)
+			}
+			args = append(args, pq.Array(formatted))
+			query += fmt.Sprintf(" AND updated_at = ANY($%d::timestamptz[])", len(args))
+		}
+
+		result, err := database.DB.ExecContext(r.Context(), query, args...)
+		if err != nil {
+			utils.WriteDBError(w, r, "businessHandler.go -> UpdateBusinessHandler()", err)
+			return
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			utils.WriteInternalError(w, r, "businessHandler.go -> UpdateBusinessHandler()", err)
+			return
+		}
+		// L'entreprise existe (requireBusinessOwner) : aucune ligne modifiée = version périmée
+		stale = updated == 0
+	}
+
+	updatedBusiness, err := fetchBusiness(r.Context(), businessID)
 	if err != nil {
-		log.Fatalf("Erreur lors de la vérification du nombre de lignes modifiées. %v", err)
+		utils.WriteDBError(w, r, "businessHandler.go -> UpdateBusinessHandler()", err)
+		return
 	}
-	log.Println(`Nombre de lignes modifiées : `, rowsAffected)
+	w.Header().Set("ETag", utils.ETag(updatedBusiness.UpdatedAt))
 
-	errFetch := database.DB.QueryRow(`
-									SELECT id, UserId, name, business_type, phone_number, address, city, zip_code, country, created_at, updated_at
-									FROM businesses WHERE id = $1
-							`, IDParam).Scan(
-		&business.ID,
-		&business.UserID,
-		&business.Name,
-		&business.BusinessType,
-		&business.PhoneNumber,
-		&business.Address,
-		&business.City,
-		&business.ZipCode,
-		&business.Country,
-		&business.CreatedAt,
-		&business.UpdatedAt,
-	)
-	if errFetch != nil {
-		log.Println(`Erreur lors de la récupération des informations de l'entreprise : `, errFetch)
-		http.Error(w, "Erreur lors de la récupération de l'entreprise : "+errFetch.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	log.Println(`Informations récupérées : `, *business.ID,
-		*business.UserID,
-		*business.Name,
-		*business.BusinessType,
-		*business.PhoneNumber,
-		*business.Address,
-		*business.City,
-		*business.ZipCode,
-		*business.Country,
-		*business.CreatedAt,
-		*business.UpdatedAt)
-
-	response := models.UpdateBusinessResponse{
-		Response: "L'entreprise a été modifiée avec succès.",
-		Business: business,
+	// Aucun champ à modifier : la version est tout de même vérifiée
+	if len(sets) == 0 && conditional {
+		stale = !slices.ContainsFunc(versions, updatedBusiness.UpdatedAt.Equal)
+	}
+	if stale {
+		utils.WriteError(w, r, http.StatusPreconditionFailed, models.ErrCodePreconditionFailed, "business.modified_concurrently")
+		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	slog.InfoContext(r.Context(), "Entreprise modifiée", "business_id", businessID, "fields", len(sets))
+	utils.WriteJSON(w, http.StatusOK, models.UpdateBusinessResponse{
+		Response: utils.T(r, "business.updated"),
+		Business: updatedBusiness,
+	})
 }
 
-// Supprimer une entreprise
+/*
+Supprimer une entreprise
+L'entreprise est désactivée (file fermée, clients en attente annulés) et reste restaurable pendant BUSINESS_RETENTION,
+puis la tâche de fond `business_purge` la supprime définitivement en conservant son activité agrégée par mois
+*/
 func DeleteBusinessHandler(w http.ResponseWriter, r *http.Request) {
-	// Réponse JSON
-	w.Header().Set("Content-Type", "application/json")
-
 	// Méthode HTTP
 	if r.Method != http.MethodDelete {
-		http.Error(w, `Mauvaise requête HTTP.`, http.StatusBadRequest)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
-	// Récupérer l'ID de l'entreprise depuis l'URL
-	IDParam := r.PathValue("id")
+	businessID, ok := requireBusinessOwner(w, r, "businessHandler.go -> DeleteBusinessHandler()")
+	if !ok {
+		return
+	}
 
-	// Récupération dans la base de données
-	_, err := database.DB.Exec(`DELETE FROM businesses WHERE id=$1`, IDParam)
+	tx, err := database.DB.BeginTx(r.Context(), nil)
 	if err != nil {
-		log.Println(`Erreur lors de la suppression de l'entreprise : `, err)
-		http.Error(w, "Erreur lors de la suppression de l'entreprise : "+err.Error(), http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "businessHandler.go -> DeleteBusinessHandler()", err)
 		return
 	}
+	defer tx.Rollback()
 
-	response := "Entreprise supprimée avec succès."
+	var deletedAt time.Time
+	err = tx.QueryRowContext(r.Context(), `
+		UPDATE businesses SET deleted_at = NOW(), is_active = false, is_queue_active = false, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at`, businessID).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		// Supprimée entre-temps par une autre requête
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.archived")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "businessHandler.go -> DeleteBusinessHandler()", err)
+		return
+	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
+	_, err = tx.ExecContext(r.Context(), `
+		UPDATE queue_entries SET status = 'cancelled', updated_at = NOW()
+		WHERE BusinessId = $1 AND status IN ('waiting', 'called')`, businessID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "businessHandler.go -> DeleteBusinessHandler()", err)
+		return
+	}
 
-/*
-Générer un QR Code
-"content" = côté Front on récupère le lien de l'app (exemple : https://waitify.fr/q/) + le token du QR code (table `businesses` colonne `qr_code_token`). Donc le paramètre `content` = https://waitify.fr/q/{qr_code_token}.
-*/
-func GenerateQRCodeHandler(w http.ResponseWriter, r *http.Request) {
-	r.ParseMultipartForm(10 << 20)
-	var size, content string = r.FormValue("size"), r.FormValue("content")
-	var codeData []byte
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "businessHandler.go -> DeleteBusinessHandler()", err)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
+	// Le logo est conservé jusqu'à la purge
+	slog.InfoContext(r.Context(), "Entreprise supprimée", "business_id", businessID)
+	utils.WriteJSON(w, http.StatusOK, models.DeleteBusinessResponse{
+		Message: utils.T(r, "business.deleted", formatDuration(r, businessRetention)),
+		PurgeAt: deletedAt.Add(businessRetention),
+	})
+}
 
-	if content == "" {
-		w.WriteHeader(400)
-		json.NewEncoder(w).Encode(
-			"Impossible de déterminer le contenu souhaité du code QR.",
-		)
+// Restaurer une entreprise supprimée (avant la fin de la durée de conservation), sa file reste fermée
+func RestoreBusinessHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var ownerID uuid.UUID
+	var deletedAt sql.NullTime
+	err := database.DB.QueryRowContext(r.Context(), "SELECT UserId, deleted_at FROM businesses WHERE id = $1", r.PathValue("id")).
+		Scan(&ownerID, &deletedAt)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteDBError(w, r, "businessHandler.go -> RestoreBusinessHandler()", err)
+		return
+	}
+	if ownerID != claims.UserID {
+		utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "business.owner_only")
+		return
+	}
+	if !deletedAt.Valid {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "business.not_deleted")
 		return
 	}
 
-	qrCodeSize, err := strconv.Atoi(size)
-	if err != nil || size == "" {
-		w.WriteHeader(400)
-		json.NewEncoder(w).Encode("Impossible de déterminer la taille souhaitée du code QR.")
+	// Conditionné à la suppression lue : une suppression ou restauration concurrente ne passe qu'une fois
+	var businessID uuid.UUID
+	err = database.DB.QueryRowContext(r.Context(), `
+		UPDATE businesses SET deleted_at = NULL, is_active = true, updated_at = NOW()
+		WHERE id = $1 AND deleted_at = $2 AND deleted_at >= NOW() - make_interval(secs => $3)
+		RETURNING id`, r.PathValue("id"), deletedAt.Time, businessRetention.Seconds()).Scan(&businessID)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "business.restore_expired")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "businessHandler.go -> RestoreBusinessHandler()", err)
 		return
 	}
 
-	qrCode := utils.QRCode{Content: content, Size: qrCodeSize}
-	codeData, err = qrCode.Generate()
+	business, err := fetchBusiness(r.Context(), businessID)
 	if err != nil {
-		w.WriteHeader(400)
-		json.NewEncoder(w).Encode(
-			fmt.Sprintf("Impossible de générer le code QR. %v", err),
-		)
+		utils.WriteDBError(w, r, "businessHandler.go -> RestoreBusinessHandler()", err)
 		return
 	}
 
-	log.Println("INFORMATIONS QR CODE : ", qrCode)
-	w.Header().Set("Content-Type", "image/png")
-	w.Write(codeData)
+	slog.InfoContext(r.Context(), "Entreprise restaurée", "business_id", businessID)
+	w.Header().Set("ETag", utils.ETag(business.UpdatedAt))
+	utils.WriteJSON(w, http.StatusOK, models.RestoreBusinessResponse{
+		Message:  utils.T(r, "business.restored"),
+		Business: business,
+	})
+}
+
+/*
+Tâche de fond : supprimer définitivement les entreprises supprimées depuis plus de BUSINESS_RETENTION
+Celles d'un compte en cours de suppression sont laissées à `account_purge` (restaurées si le compte l'est)
+L'activité est d'abord archivée dans business_usage_archives, dans la même transaction que la suppression
+*/
+func PurgeDeletedBusinesses(ctx context.Context) error {
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT businesses.id FROM businesses JOIN users ON users.id = businesses.UserId
+		WHERE businesses.deleted_at < NOW() - make_interval(secs => $1) AND users.deleted_at IS NULL
+		LIMIT 100 FOR UPDATE OF businesses SKIP LOCKED`, businessRetention.Seconds())
+	if err != nil {
+		return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := archiveBusinessUsage(ctx, tx, ids); err != nil {
+		return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM businesses WHERE id = ANY($1::uuid[])", pq.Array(ids)); err != nil {
+		return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[businessHandler.go -> PurgeDeletedBusinesses()] -> %w", err)
+	}
+
+	// Logos (un échec est rattrapé par la tâche `storage_cleanup`)
+	for _, id := range ids {
+		removeStoredPrefix(ctx, storage.BusinessPrefix(uuid.MustParse(id)))
+	}
+
+	slog.InfoContext(ctx, "Entreprises supprimées définitivement", "count", len(ids))
+	return nil
+}
+
+/*
+Archiver l'activité des entreprises avant leur suppression définitive : un total par mois
+(clients inscrits, servis, manqués, annulés, attente moyenne, SMS envoyés et leur coût), conservé pour la facturation
+Les mois déjà archivés ne sont pas écrasés
+*/
+func archiveBusinessUsage(ctx context.Context, tx *sql.Tx, businessIDs []string) error {
+	_, err := tx.ExecContext(ctx, `
+		WITH entries AS (
+			SELECT BusinessId, date_trunc('month', created_at)::date AS month,
+				COUNT(*) AS registered,
+				COUNT(*) FILTER (WHERE status = 'served') AS served,
+				COUNT(*) FILTER (WHERE status = 'missed') AS missed,
+				COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled
+			FROM queue_entries WHERE BusinessId = ANY($1::uuid[]) AND created_at IS NOT NULL
+			GROUP BY 1, 2
+		), sms AS (
+			SELECT BusinessId, date_trunc('month', sent_at)::date AS month, COUNT(*) AS sent, COALESCE(SUM(cost_cents), 0) AS cost
+			FROM sms_logs WHERE BusinessId = ANY($1::uuid[]) AND sent_at IS NOT NULL AND status IS DISTINCT FROM 'failed'
+			GROUP BY 1, 2
+		), analytics AS (
+			SELECT BusinessId, date_trunc('month', date)::date AS month, ROUND(AVG(average_wait_time))::integer AS average_wait_time
+			FROM analytics_daily WHERE BusinessId = ANY($1::uuid[])
+			GROUP BY 1, 2
+		), months AS (
+			SELECT BusinessId, month FROM entries
+			UNION SELECT BusinessId, month FROM sms
+			UNION SELECT BusinessId, month FROM analytics
+		)
+		INSERT INTO business_usage_archives (BusinessId, UserId, business_name, business_type, month,
+			clients_registered, clients_served, clients_missed, clients_cancelled, average_wait_time, sms_sent, sms_cost_cents)
+		SELECT businesses.id, businesses.UserId, businesses.name, businesses.business_type, months.month,
+			COALESCE(entries.registered, 0), COALESCE(entries.served, 0), COALESCE(entries.missed, 0), COALESCE(entries.cancelled, 0),
+			analytics.average_wait_time, COALESCE(sms.sent, 0), COALESCE(sms.cost, 0)
+		FROM months
+		JOIN businesses ON businesses.id = months.BusinessId
+		LEFT JOIN entries ON entries.BusinessId = months.BusinessId AND entries.month = months.month
+		LEFT JOIN sms ON sms.BusinessId = months.BusinessId AND sms.month = months.month
+		LEFT JOIN analytics ON analytics.BusinessId = months.BusinessId AND analytics.month = months.month
+		ON CONFLICT (BusinessId, month) DO NOTHING`, pq.Array(businessIDs))
+	if err != nil {
+		return fmt.Errorf("[businessHandler.go -> archiveBusinessUsage()] -> %w", err)
+	}
+	return nil
 }
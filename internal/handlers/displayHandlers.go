@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/i18n"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+)
+
+// Écran d'affichage : clients affichés, fréquence de rafraîchissement et durée maximum d'une connexion SSE
+const (
+	displayNowServing    = 3
+	displayNext          = 6
+	displayPollInterval  = 3 * time.Second
+	displayPingInterval  = 20 * time.Second
+	displayStreamMaxLife = time.Hour // le navigateur se reconnecte automatiquement (EventSource)
+)
+
+// Page de l'écran d'affichage, intégrée au binaire et analysée une seule fois au démarrage
+//
+//go:embed display.html
+var displayPage string
+
+var displayTemplate = template.Must(template.New("display.html").Parse(displayPage))
+
+// Fermé à l'arrêt du serveur : les flux SSE se terminent au lieu de bloquer server.Shutdown
+var (
+	displayStop     = make(chan struct{})
+	displayStopOnce sync.Once
+)
+
+// À enregistrer avec server.RegisterOnShutdown
+func CloseDisplayStreams() {
+	displayStopOnce.Do(func() { close(displayStop) })
+}
+
+// Lien actuel de l'écran d'affichage (404 s'il n'a pas été activé)
+func GetDisplayTokenHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "displayHandlers.go -> GetDisplayTokenHandler()")
+	if !ok {
+		return
+	}
+
+	var token sql.NullString
+	if err := database.DB.QueryRowContext(r.Context(), "SELECT display_token FROM businesses WHERE id = $1", businessID).Scan(&token); err != nil {
+		utils.WriteDBError(w, r, "displayHandlers.go -> GetDisplayTokenHandler()", err)
+		return
+	}
+	if !token.Valid {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "display.disabled")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, displayTokenResponse(token.String, ""))
+}
+
+// Activer l'écran d'affichage, ou remplacer son lien (l'ancien cesse de fonctionner, les écrans ouverts sont déconnectés)
+func RotateDisplayTokenHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "displayHandlers.go -> RotateDisplayTokenHandler()")
+	if !ok {
+		return
+	}
+
+	token, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		utils.WriteInternalError(w, r, "displayHandlers.go -> RotateDisplayTokenHandler()", err)
+		return
+	}
+
+	var previous sql.NullString
+	err = database.DB.QueryRowContext(r.Context(), `
+		UPDATE businesses SET display_token = $2
+		FROM (SELECT display_token FROM businesses WHERE id = $1 FOR UPDATE) AS previous
+		WHERE businesses.id = $1
+		RETURNING previous.display_token`, businessID, token).Scan(&previous)
+	if err != nil {
+		utils.WriteDBError(w, r, "displayHandlers.go -> RotateDisplayTokenHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Lien de l'écran d'affichage généré", "business_id", businessID, "rotated", previous.Valid)
+	status, message := http.StatusCreated, "display.enabled"
+	if previous.Valid {
+		status, message = http.StatusOK, "display.rotated"
+	}
+	utils.WriteJSON(w, status, displayTokenResponse(token, utils.T(r, message)))
+}
+
+// Désactiver l'écran d'affichage
+func DeleteDisplayTokenHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "displayHandlers.go -> DeleteDisplayTokenHandler()")
+	if !ok {
+		return
+	}
+
+	if _, err := database.DB.ExecContext(r.Context(), "UPDATE businesses SET display_token = NULL WHERE id = $1", businessID); err != nil {
+		utils.WriteDBError(w, r, "displayHandlers.go -> DeleteDisplayTokenHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Écran d'affichage désactivé", "business_id", businessID)
+	utils.WriteJSON(w, http.StatusOK, utils.T(r, "display.disabled_done"))
+}
+
+func displayTokenResponse(token, message string) models.DisplayTokenResponse {
+	return models.DisplayTokenResponse{
+		Message:    message,
+		Token:      token,
+		FeedPath:   "/display/" + token,
+		ScreenPath: "/display/" + token + "/screen",
+	}
+}
+
+/*
+Écran d'affichage (route publique, lien en lecture seule)
+- JSON par défaut
+- Flux SSE avec Accept: text/event-stream : événement `board` à chaque changement, `closed` si le lien est désactivé
+*/
+func DisplayHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		streamDisplayBoard(w, r, token)
+		return
+	}
+
+	board, err := fetchDisplayBoard(r.Context(), token)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "display.token_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "displayHandlers.go -> DisplayHandler()", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, board)
+}
+
+/*
+Flux SSE de l'écran : la file est relue toutes les `displayPollInterval`, un événement n'est envoyé que si elle a changé
+Un commentaire est envoyé régulièrement pour garder la connexion ouverte derrière les proxys
+*/
+func streamDisplayBoard(w http.ResponseWriter, r *http.Request, token string) {
+	board, err := fetchDisplayBoard(r.Context(), token)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "display.token_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "displayHandlers.go -> streamDisplayBoard()", err)
+		return
+	}
+
+	// Pas de limite d'écriture (WriteTimeout du serveur) pour cette réponse
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "Délai d'écriture du flux SSE non modifiable", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx : pas de mise en mémoire tampon
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+
+	var last []byte
+	send := func(board models.DisplayBoard) error {
+		data, err := json.Marshal(board)
+		if err != nil {
+			return err
+		}
+		// Date de mise à jour exclue de la comparaison : seul un changement de la file déclenche un événement
+		board.UpdatedAt = time.Time{}
+		state, _ := json.Marshal(board)
+		if bytes.Equal(state, last) {
+			return nil
+		}
+		last = state
+		if _, err := fmt.Fprintf(w, "event: board\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+	if err := send(board); err != nil {
+		return
+	}
+
+	poll := time.NewTicker(displayPollInterval)
+	defer poll.Stop()
+	ping := time.NewTicker(displayPingInterval)
+	defer ping.Stop()
+	deadline := time.NewTimer(displayStreamMaxLife)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-displayStop:
+			return
+		case <-deadline.C:
+			return
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		case <-poll.C:
+			board, err := fetchDisplayBoard(r.Context(), token)
+			if err == sql.ErrNoRows {
+				// Lien remplacé ou désactivé : l'écran arrête de se reconnecter
+				fmt.Fprint(w, "event: closed\ndata: {}\n\n")
+				controller.Flush()
+				return
+			}
+			if err != nil {
+				if r.Context().Err() == nil {
+					slog.WarnContext(r.Context(), "Écran d'affichage non rafraîchi", "error", err)
+				}
+				continue
+			}
+			if err := send(board); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Données de l'écran : clients appelés et prochains clients en attente, désignés par leur numéro de ticket
+func fetchDisplayBoard(ctx context.Context, token string) (models.DisplayBoard, error) {
+	board := models.DisplayBoard{NowServing: []models.DisplayTicket{}, Next: []models.DisplayTicket{}, UpdatedAt: time.Now()}
+	if token == "" {
+		return board, sql.ErrNoRows
+	}
+
+	var businessID string
+	var averageServiceTime int
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(logo, ''), default_language, COALESCE(is_queue_active, false), COALESCE(is_queue_paused, false),
+			COALESCE(average_service_time, 300)
+		FROM businesses WHERE display_token = $1 AND is_active = true`, token).Scan(
+		&businessID,
+		&board.BusinessName,
+		&board.LogoURL,
+		&board.Language,
+		&board.IsQueueActive,
+		&board.IsQueuePaused,
+		&averageServiceTime,
+	)
+	if err != nil {
+		return board, err
+	}
+	board.LogoURL = storage.PublicURL(ctx, board.LogoURL)
+
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT q.status, COALESCE(q.client_name, ''), q.called_at, q.ticket_prefix, COALESCE(q.ticket_number, 0), COALESCE(t.label, '')
+		FROM queue_entries q
+		LEFT JOIN qr_tokens t ON t.id = q.qr_token_id
+		WHERE q.BusinessId = $1 AND q.status IN ('waiting', 'called')
+		ORDER BY q.status = 'waiting', q.called_at DESC, q.position, q.created_at`, businessID)
+	if err != nil {
+		return board, fmt.Errorf("[displayHandlers.go -> fetchDisplayBoard()] -> %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, name, ticketPrefix, desk string
+		var ticketNumber int
+		var calledAt *time.Time
+		if err := rows.Scan(&status, &name, &calledAt, &ticketPrefix, &ticketNumber, &desk); err != nil {
+			return board, fmt.Errorf("[displayHandlers.go -> fetchDisplayBoard()] -> %w", err)
+		}
+		entry := models.DisplayTicket{Ticket: models.TicketLabel(ticketPrefix, ticketNumber), Initials: displayInitials(name)}
+		if status == "called" {
+			if len(board.NowServing) < displayNowServing {
+				entry.CalledAt = calledAt
+				entry.Desk = desk
+				board.NowServing = append(board.NowServing, entry)
+			}
+			continue
+		}
+		board.WaitingCount++
+		if len(board.Next) < displayNext {
+			board.Next = append(board.Next, entry)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return board, fmt.Errorf("[displayHandlers.go -> fetchDisplayBoard()] -> %w", err)
+	}
+
+	board.EstimatedWaitTime = board.WaitingCount * averageServiceTime / 60
+	return board, nil
+}
+
+// Initiales du nom affiché sur l'écran ("Marie Dupont" -> "M. D."), au plus deux
+func displayInitials(name string) string {
+	var initials []string
+	for _, word := range strings.Fields(name) {
+		for _, char := range word {
+			if unicode.IsLetter(char) {
+				initials = append(initials, string(unicode.ToUpper(char))+".")
+				break
+			}
+		}
+		if len(initials) == 2 {
+			break
+		}
+	}
+	return strings.Join(initials, " ")
+}
+
+/*
+Page de l'écran d'affichage (display.html), dans la langue par défaut du commerce
+Le script inline est autorisé par un nonce, propre à chaque réponse
+*/
+func DisplayScreenHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	var language string
+	err := database.DB.QueryRowContext(r.Context(), "SELECT default_language FROM businesses WHERE display_token = $1 AND is_active = true", token).Scan(&language)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "display.token_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "displayHandlers.go -> DisplayScreenHandler()", err)
+		return
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		utils.WriteInternalError(w, r, "displayHandlers.go -> DisplayScreenHandler()", err)
+		return
+	}
+	scriptNonce := base64.StdEncoding.EncodeToString(nonce)
+
+	labels := map[string]string{}
+	for _, key := range []string{"now_serving", "next", "waiting", "estimated_wait", "minutes", "closed", "paused", "empty", "enable_sound", "disconnected", "link_disabled"} {
+		labels[key] = i18n.T(language, "display.screen."+key)
+	}
+
+	var page bytes.Buffer
+	err = displayTemplate.Execute(&page, map[string]any{
+		"Language": language,
+		"Nonce":    scriptNonce,
+		"FeedPath": "/display/" + token,
+		"Labels":   labels,
+	})
+	if err != nil {
+		utils.WriteInternalError(w, r, "displayHandlers.go -> DisplayScreenHandler()", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'nonce-"+scriptNonce+"'; style-src 'unsafe-inline'; "+
+		"img-src 'self' https: data:; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'")
+	w.Write(page.Bytes())
+}
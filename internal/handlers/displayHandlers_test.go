@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDisplayInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Marie Dupont", "M. D."},
+		{"marie", "M."},
+		{"Jean-Pierre de la Fontaine", "J. D."},
+		{"  élodie   ÉMERY ", "É. É."},
+		{"'Olivier O'Neil", "O. O."},
+		{"06 12 34 56 78", ""},
+		{"42 Bob", "B."},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := displayInitials(test.name); got != test.want {
+			t.Errorf("displayInitials(%q) = %q, attendu %q", test.name, got, test.want)
+		}
+	}
+}
+
+// Page intégrée au binaire : analysée au démarrage, le nonce et les libellés sont échappés dans le script
+func TestDisplayTemplate(t *testing.T) {
+	var page bytes.Buffer
+	err := displayTemplate.Execute(&page, map[string]any{
+		"Language": "fr",
+		"Nonce":    "bm9uY2U=",
+		"FeedPath": "/display/token",
+		"Labels":   map[string]string{"now_serving": "Au tour de", "closed": "</script><b>"},
+	})
+	if err != nil {
+		t.Fatalf("page non générée : %v", err)
+	}
+	html := page.String()
+	for _, want := range []string{`lang="fr"`, `nonce="bm9uY2U="`, "Au tour de"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("%q absent de la page", want)
+		}
+	}
+	if strings.Contains(html, "</script><b>") {
+		t.Error("libellé non échappé dans le script")
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+)
+
+/*
+Route par défaut ("/"), appelée quand aucune autre route ne correspond
+- Le chemin existe avec une autre méthode -> 405 + header Allow
+- Sinon -> 404
+Toujours au format d'erreur JSON de l'API
+*/
+func NotFoundHandler(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if allowed := utils.AllowedMethods(mux, r); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+			return
+		}
+
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "error.route_not_found")
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/StevenYAMBOS/waitify-api/internal/workers"
+)
+
+// Délai maximum de chaque vérification de /readyz
+const readinessTimeout = 2 * time.Second
+
+// État d'une dépendance dans la réponse de /readyz
+type dependencyCheck struct {
+	Status     string  `json:"status"` // ok, fail
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+	Details    any     `json:"details,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                     `json:"status"` // ok, unavailable
+	Checks map[string]dependencyCheck `json:"checks"`
+}
+
+/*
+Liveness : le processus répond, sans vérifier les dépendances
+(un redémarrage ne réparerait pas une base de données indisponible)
+*/
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+/*
+Readiness : l'instance peut recevoir du trafic
+- PostgreSQL répond au ping
+- Toutes les migrations embarquées sont appliquées
+- Les tâches de fond tournent
+503 si une vérification échoue. Les causes détaillées sont dans les logs, pas dans la réponse.
+*/
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	response := readinessResponse{
+		Status: "ok",
+		Checks: map[string]dependencyCheck{
+			"database":   runCheck(r.Context(), "database", checkDatabase),
+			"migrations": runCheck(r.Context(), "migrations", checkMigrations),
+			"workers":    runCheck(r.Context(), "workers", checkWorkers),
+		},
+	}
+
+	status := http.StatusOK
+	for _, check := range response.Checks {
+		if check.Status != "ok" {
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, status, response)
+}
+
+// Exécuter une vérification avec un délai maximum
+func runCheck(ctx context.Context, name string, check func(ctx context.Context) (details any, message string, err error)) dependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, message, err := check(ctx)
+	result := dependencyCheck{
+		Status:     "ok",
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:    details,
+	}
+
+	if message != "" {
+		result.Status = "fail"
+		result.Error = message
+		slog.WarnContext(ctx, "Vérification de disponibilité en échec", "check", name, "reason", message, "error", err)
+	}
+	return result
+}
+
+func checkDatabase(ctx context.Context) (any, string, error) {
+	if err := database.DB.PingContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, "timeout", err
+		}
+		return nil, "unreachable", err
+	}
+
+	stats := database.DB.Stats()
+	return map[string]int{
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+	}, "", nil
+}
+
+func checkMigrations(ctx context.Context) (any, string, error) {
+	pending, err := database.PendingMigrations(ctx)
+	if err != nil {
+		return nil, "unknown", err
+	}
+	if len(pending) > 0 {
+		return map[string]any{"pending": pending}, "pending_migrations", nil
+	}
+	return nil, "", nil
+}
+
+func checkWorkers(ctx context.Context) (any, string, error) {
+	statuses := workers.Statuses()
+	now := time.Now()
+
+	message := ""
+	for _, status := range statuses {
+		if !status.Alive(now) {
+			message = "worker_down"
+		}
+	}
+	return statuses, message, nil
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/lib/pq"
+)
+
+// Limites des échecs de connexion (cf. config.Auth)
+var loginThrottle struct {
+	maxAttempts   int
+	ipMaxAttempts int
+	backoffBase   time.Duration
+	backoffMax    time.Duration
+	window        time.Duration
+}
+
+func InitLoginThrottle(cfg *config.Config) {
+	loginThrottle.maxAttempts = cfg.Auth.LoginMaxAttempts
+	loginThrottle.ipMaxAttempts = cfg.Auth.LoginIPMaxAttempts
+	loginThrottle.backoffBase = cfg.Auth.LoginBackoffBase
+	loginThrottle.backoffMax = cfg.Auth.LoginBackoffMax
+	loginThrottle.window = cfg.Auth.LoginFailureWindow
+}
+
+// Clés de la table login_throttles : par compte (même si l'email n'existe pas, pour ne rien révéler) et par adresse IP
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Temps restant avant de pouvoir réessayer (0 si aucune des clés n'est bloquée)
+func loginLockRemaining(ctx context.Context, keys ...string) (time.Duration, error) {
+	var lockedUntil sql.NullTime
+	err := database.DB.QueryRowContext(ctx,
+		"SELECT MAX(locked_until) FROM login_throttles WHERE key = ANY($1) AND locked_until > NOW()",
+		pq.Array(keys),
+	).Scan(&lockedUntil)
+	if err != nil {
+		return 0, fmt.Errorf("[loginThrottle.go -> loginLockRemaining()] -> %w", err)
+	}
+	if !lockedUntil.Valid {
+		return 0, nil
+	}
+	return time.Until(lockedUntil.Time), nil
+}
+
+/*
+Enregistrer un échec de connexion pour le compte et pour l'adresse IP
+Au-delà du nombre d'essais autorisés, la clé est bloquée : `backoffBase`, puis le double à chaque nouvel échec (plafonné à `backoffMax`)
+Le compteur repart de zéro après `window` sans échec
+Retourne la durée du blocage quand le compte vient d'être bloqué pour la première fois (notification par email), 0 sinon
+*/
+func recordLoginFailure(ctx context.Context, accountKey, ipKey string) (time.Duration, error) {
+	accountFailures, accountLock, err := recordThrottleFailure(ctx, accountKey, loginThrottle.maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	if _, _, err := recordThrottleFailure(ctx, ipKey, loginThrottle.ipMaxAttempts); err != nil {
+		return 0, err
+	}
+
+	if accountFailures == loginThrottle.maxAttempts {
+		return accountLock, nil
+	}
+	return 0, nil
+}
+
+func recordThrottleFailure(ctx context.Context, key string, maxAttempts int) (int, time.Duration, error) {
+	var failures int
+	err := database.DB.QueryRowContext(ctx, `
+		INSERT INTO login_throttles (key, failures, last_failure_at) VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < NOW() - make_interval(secs => $2) THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = NOW()
+		RETURNING failures`,
+		key, loginThrottle.window.Seconds(),
+	).Scan(&failures)
+	if err != nil {
+		return 0, 0, fmt.Errorf("[loginThrottle.go -> recordThrottleFailure()] -> %w", err)
+	}
+
+	if failures < maxAttempts {
+		return failures, 0, nil
+	}
+
+	lock := loginBackoff(failures - maxAttempts)
+	_, err = database.DB.ExecContext(ctx, "UPDATE login_throttles SET locked_until = NOW() + make_interval(secs => $2) WHERE key = $1", key, lock.Seconds())
+	if err != nil {
+		return 0, 0, fmt.Errorf("[loginThrottle.go -> recordThrottleFailure()] -> %w", err)
+	}
+	return failures, lock, nil
+}
+
+// Durée du blocage après `extra` échecs au-delà de la limite : base, 2 x base, 4 x base... plafonnée
+func loginBackoff(extra int) time.Duration {
+	if extra > 30 {
+		return loginThrottle.backoffMax
+	}
+	return min(loginThrottle.backoffBase<<extra, loginThrottle.backoffMax)
+}
+
+// Connexion réussie (ou mot de passe réinitialisé) : le compteur du compte repart de zéro
+func resetLoginFailures(ctx context.Context, accountKey string) error {
+	if _, err := database.DB.ExecContext(ctx, "DELETE FROM login_throttles WHERE key = $1", accountKey); err != nil {
+		return fmt.Errorf("[loginThrottle.go -> resetLoginFailures()] -> %w", err)
+	}
+	return nil
+}
+
+/*
+Tâche de fond : supprimer les compteurs d'échecs inactifs et les tokens envoyés par email expirés ou utilisés
+*/
+func CleanupAuthData(ctx context.Context) error {
+	_, err := database.DB.ExecContext(ctx,
+		"DELETE FROM login_throttles WHERE last_failure_at < NOW() - make_interval(secs => $1) AND (locked_until IS NULL OR locked_until < NOW())",
+		loginThrottle.window.Seconds())
+	if err != nil {
+		return fmt.Errorf("[loginThrottle.go -> CleanupAuthData()] -> Compteurs d'échecs : %w", err)
+	}
+
+	_, err = database.DB.ExecContext(ctx, "DELETE FROM auth_tokens WHERE expires_at < NOW() OR used_at IS NOT NULL")
+	if err != nil {
+		return fmt.Errorf("[loginThrottle.go -> CleanupAuthData()] -> Tokens : %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,577 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/i18n"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Taille des QR Codes PNG et SVG (pixels)
+const (
+	qrCodeMinSize     = 128
+	qrCodeMaxSize     = 2048
+	qrCodeDefaultSize = 512
+)
+
+// Page client encodée dans les QR Codes (APP_QUEUE_URL), suivie du qr_code_token
+var queueURL string
+
+func InitQRCodes(cfg *config.Config) {
+	queueURL = cfg.App.QueueURL
+}
+
+// Lien encodé dans le QR Code d'une entreprise
+func queueLink(token string) string {
+	if strings.HasSuffix(queueURL, "/") {
+		return queueURL + token
+	}
+	return queueURL + "/" + token
+}
+
+/*
+QR Code de l'entreprise, toujours généré à partir de son qr_code_token (le contenu n'est pas choisi par le client)
+Paramètres (query string ou formulaire) :
+- format : png (par défaut), svg ou pdf (affiche imprimable avec le nom de l'entreprise et le message personnalisé)
+- size : côté en pixels pour png et svg (128 à 2048, 512 par défaut)
+- level : correction d'erreur L, M (par défaut), Q ou H
+- logo : true pour superposer le logo de l'entreprise (niveau H imposé)
+- paper : a4 (par défaut) ou a5 pour le format pdf
+- token_id : QR code supplémentaire à générer (cf. CreateQRTokenHandler), le principal par défaut
+*/
+func BusinessQRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "qrcodeHandlers.go -> BusinessQRCodeHandler()")
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(r.FormValue("format"))
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" && format != "pdf" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "qrcode.format_invalid",
+			models.FieldError{Field: "format", Code: "invalid_choice", Message: "qrcode.format_invalid"})
+		return
+	}
+
+	size, ok := qrCodeSize(w, r)
+	if !ok {
+		return
+	}
+
+	level := strings.ToUpper(r.FormValue("level"))
+	if _, ok := utils.QRCodeLevels[level]; level != "" && !ok {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "qrcode.level_invalid",
+			models.FieldError{Field: "level", Code: "invalid_choice", Message: "qrcode.level_invalid"})
+		return
+	}
+
+	withLogo := false
+	if value := r.FormValue("logo"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed",
+				models.FieldError{Field: "logo", Code: "invalid_format", Message: "qrcode.logo_invalid"})
+			return
+		}
+		withLogo = parsed
+	}
+
+	paper := strings.ToLower(r.FormValue("paper"))
+	if paper == "" {
+		paper = "a4"
+	}
+	if _, ok := utils.QRPosterPapers[paper]; !ok {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "qrcode.paper_invalid",
+			models.FieldError{Field: "paper", Code: "invalid_choice", Message: "qrcode.paper_invalid"})
+		return
+	}
+
+	var name, token, message, logoKey, language string
+	err := database.DB.QueryRowContext(r.Context(), `
+		SELECT name, qr_code_token, COALESCE(custom_message, ''), COALESCE(logo, ''), default_language
+		FROM businesses WHERE id = $1`, businessID).Scan(&name, &token, &message, &logoKey, &language)
+	if err != nil {
+		utils.WriteDBError(w, r, "qrcodeHandlers.go -> BusinessQRCodeHandler()", err)
+		return
+	}
+
+	if value := r.FormValue("token_id"); value != "" {
+		var usable bool
+		tokenID, err := uuid.Parse(value)
+		if err == nil {
+			err = database.DB.QueryRowContext(r.Context(), "SELECT token, "+qrTokenUsable+" FROM qr_tokens WHERE BusinessId = $1 AND id = $2",
+				businessID, tokenID).Scan(&token, &usable)
+			if err != nil && err != sql.ErrNoRows {
+				utils.WriteInternalError(w, r, "qrcodeHandlers.go -> BusinessQRCodeHandler()", err)
+				return
+			}
+		}
+		if err != nil {
+			utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "qrcode.token_not_found")
+			return
+		}
+		if !usable {
+			utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "qrcode.token_not_active")
+			return
+		}
+	}
+
+	code := utils.QRCode{Content: queueLink(token), Size: size, Level: level}
+	if withLogo {
+		if logoKey == "" {
+			utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "qrcode.logo_missing",
+				models.FieldError{Field: "logo", Code: "not_found", Message: "qrcode.logo_missing"})
+			return
+		}
+		code.Logo, err = loadLogo(r.Context(), logoKey)
+		if err != nil {
+			utils.WriteInternalError(w, r, "qrcodeHandlers.go -> BusinessQRCodeHandler()", err)
+			return
+		}
+	}
+
+	var data []byte
+	var contentType string
+	switch format {
+	case "png":
+		data, err = code.Generate()
+		contentType = "image/png"
+	case "svg":
+		data, err = code.SVG()
+		contentType = "image/svg+xml"
+	case "pdf":
+		poster := utils.QRPoster{
+			Code:    code,
+			Paper:   paper,
+			Title:   name,
+			Message: message,
+			Footer:  i18n.T(language, "qrcode.poster_footer"),
+			Link:    code.Content,
+		}
+		data, err = poster.PDF()
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> BusinessQRCodeHandler()", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="waitify-qrcode-%s.%s"`, businessID, format))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Write(data)
+}
+
+// Logo de l'entreprise (déjà validé et réduit à l'envoi)
+func loadLogo(ctx context.Context, key string) (image.Image, error) {
+	data, err := storage.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("[qrcodeHandlers.go -> loadLogo()] -> %w", err)
+	}
+	logo, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("[qrcodeHandlers.go -> loadLogo()] -> %w", err)
+	}
+	return logo, nil
+}
+
+/*
+Ancienne route POST /business/{id}/qrcode/generate : paramètres de BusinessQRCodeHandler en formulaire (multipart
+ou urlencoded), en query string ou en JSON (Content-Type: application/json). Le champ "content" est ignoré
+*/
+func GenerateQRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		BusinessQRCodeHandler(w, r)
+		return
+	}
+
+	var req models.QRCodeGenerateRequest
+	if !utils.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	// Paramètres du corps reportés dans la query string, lue par BusinessQRCodeHandler (prioritaires sur celle de l'URL)
+	query := r.URL.Query()
+	set := func(name, value string) {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	set("format", req.Format)
+	set("level", req.Level)
+	set("paper", req.Paper)
+	set("token_id", req.TokenID)
+	if req.Size != nil {
+		query.Set("size", strconv.Itoa(*req.Size))
+	}
+	if req.Logo != nil {
+		query.Set("logo", strconv.FormatBool(*req.Logo))
+	}
+	r.URL.RawQuery = query.Encode()
+	r.Form = nil
+	BusinessQRCodeHandler(w, r)
+}
+
+// Taille demandée (champ "size"), 512 par défaut
+func qrCodeSize(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.FormValue("size")
+	if value == "" {
+		return qrCodeDefaultSize, true
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < qrCodeMinSize || size > qrCodeMaxSize {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "qrcode.size_invalid",
+			models.FieldError{Field: "size", Code: "out_of_range", Message: "qrcode.size_range", Args: []any{qrCodeMinSize, qrCodeMaxSize}})
+		return 0, false
+	}
+	return size, true
+}
+
+/* -------------- Tokens des QR codes -------------- */
+
+const (
+	qrTokenMaxActive    = 20          // QR codes actifs par entreprise (principal compris)
+	qrTokenMaxLabel     = 100         // caractères
+	qrTokenMaxGraceMins = 7 * 24 * 60 // délai de grâce maximum d'une rotation (7 jours)
+)
+
+// Token encore scannable : ni révoqué, ni expiré
+const qrTokenUsable = "revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())"
+
+// Colonnes lues par scanQRToken (qr_tokens t, businesses b)
+const qrTokenSelect = `
+	SELECT t.id, COALESCE(t.label, ''), t.ticket_prefix, t.token, t.token = b.qr_code_token,
+		CASE
+			WHEN t.revoked_at IS NOT NULL THEN 'revoked'
+			WHEN t.expires_at IS NULL THEN 'active'
+			WHEN t.expires_at > NOW() THEN 'grace'
+			ELSE 'expired'
+		END,
+		t.scan_count, (SELECT COUNT(*) FROM queue_entries e WHERE e.qr_token_id = t.id),
+		t.last_scanned_at, t.expires_at, t.revoked_at, t.replaced_by, t.created_at
+	FROM qr_tokens t
+	JOIN businesses b ON b.id = t.BusinessId`
+
+func scanQRToken(row interface{ Scan(...any) error }) (models.QRToken, error) {
+	var token models.QRToken
+	err := row.Scan(&token.ID, &token.Label, &token.TicketPrefix, &token.Token, &token.Primary, &token.Status,
+		&token.ScanCount, &token.JoinCount, &token.LastScannedAt, &token.ExpiresAt, &token.RevokedAt, &token.ReplacedBy, &token.CreatedAt)
+	token.URL = queueLink(token.Token)
+	return token, err
+}
+
+func fetchQRToken(ctx context.Context, businessID, tokenID uuid.UUID) (models.QRToken, error) {
+	return scanQRToken(database.DB.QueryRowContext(ctx, qrTokenSelect+" WHERE t.BusinessId = $1 AND t.id = $2", businessID, tokenID))
+}
+
+// Historique des QR codes de l'entreprise (actifs d'abord, puis du plus récent au plus ancien)
+func ListQRTokensHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "qrcodeHandlers.go -> ListQRTokensHandler()")
+	if !ok {
+		return
+	}
+
+	rows, err := database.DB.QueryContext(r.Context(), qrTokenSelect+`
+		WHERE t.BusinessId = $1
+		ORDER BY (t.revoked_at IS NULL AND t.expires_at IS NULL) DESC, t.created_at DESC`, businessID)
+	if err != nil {
+		utils.WriteDBError(w, r, "qrcodeHandlers.go -> ListQRTokensHandler()", err)
+		return
+	}
+	defer rows.Close()
+
+	response := models.QRTokensResponse{Tokens: []models.QRToken{}}
+	for rows.Next() {
+		token, err := scanQRToken(rows)
+		if err != nil {
+			utils.WriteInternalError(w, r, "qrcodeHandlers.go -> ListQRTokensHandler()", err)
+			return
+		}
+		response.Tokens = append(response.Tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> ListQRTokensHandler()", err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+/*
+Créer un QR code supplémentaire (une entrée, un comptoir...) pour distinguer l'origine des inscriptions
+Le libellé est facultatif, le QR code principal reste celui des affiches par défaut
+*/
+func CreateQRTokenHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "qrcodeHandlers.go -> CreateQRTokenHandler()")
+	if !ok {
+		return
+	}
+
+	var request models.QRTokenCreateRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	var details []models.FieldError
+	request.Label = strings.TrimSpace(request.Label)
+	if utf8.RuneCountInString(request.Label) > qrTokenMaxLabel {
+		details = append(details, models.FieldError{Field: "label", Code: "invalid_length", Message: "qrcode.label_length", Args: []any{qrTokenMaxLabel}})
+	}
+	if request.TicketPrefix != nil && models.ValidateTicketPrefix(*request.TicketPrefix) != nil {
+		details = append(details, models.FieldError{Field: "ticket_prefix", Code: "invalid_format", Message: "business.ticket_prefix_invalid"})
+	}
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> CreateQRTokenHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Verrou sur l'entreprise : deux créations simultanées ne dépassent pas la limite
+	var active int
+	err = tx.QueryRowContext(r.Context(), `
+		SELECT (SELECT COUNT(*) FROM qr_tokens WHERE BusinessId = b.id AND revoked_at IS NULL AND expires_at IS NULL)
+		FROM businesses b WHERE b.id = $1 FOR UPDATE`, businessID).Scan(&active)
+	if err != nil {
+		utils.WriteDBError(w, r, "qrcodeHandlers.go -> CreateQRTokenHandler()", err)
+		return
+	}
+	if active >= qrTokenMaxActive {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "qrcode.token_limit")
+		return
+	}
+
+	tokenID := uuid.New()
+	_, err = tx.ExecContext(r.Context(), "INSERT INTO qr_tokens (id, BusinessId, token, label, ticket_prefix) VALUES ($1, $2, $3, NULLIF($4, ''), $5)",
+		tokenID, businessID, uuid.New().String(), request.Label, request.TicketPrefix)
+	if err != nil {
+		utils.WriteDBError(w, r, "qrcodeHandlers.go -> CreateQRTokenHandler()", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> CreateQRTokenHandler()", err)
+		return
+	}
+
+	token, err := fetchQRToken(r.Context(), businessID, tokenID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> CreateQRTokenHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "QR code créé", "business_id", businessID, "qr_token_id", tokenID)
+	utils.WriteJSON(w, http.StatusCreated, models.QRTokenResponse{Message: utils.T(r, "qrcode.token_created"), Token: token})
+}
+
+/*
+Remplacer un QR code (affiche abîmée, lien diffusé...) : un nouveau token est émis avec le même libellé
+L'ancien reste scannable pendant `grace_minutes` (0 par défaut : invalide immédiatement), le temps de réimprimer les affiches
+Sans `token_id`, c'est le QR code principal (businesses.qr_code_token) qui est remplacé
+*/
+func RotateQRTokenHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()")
+	if !ok {
+		return
+	}
+
+	// Corps facultatif
+	var request models.QRTokenRotateRequest
+	if r.ContentLength != 0 && !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if request.GraceMinutes < 0 || request.GraceMinutes > qrTokenMaxGraceMins {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed",
+			models.FieldError{Field: "grace_minutes", Code: "out_of_range", Message: "qrcode.grace_range", Args: []any{qrTokenMaxGraceMins}})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var primaryToken string
+	err = tx.QueryRowContext(r.Context(), "SELECT qr_code_token FROM businesses WHERE id = $1 FOR UPDATE", businessID).Scan(&primaryToken)
+	if err != nil {
+		utils.WriteDBError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+
+	var previousID uuid.UUID
+	var previousToken string
+	var usable bool
+	query := "SELECT id, token, " + qrTokenUsable + " AND expires_at IS NULL FROM qr_tokens WHERE BusinessId = $1 AND token = $2 FOR UPDATE"
+	args := []any{businessID, primaryToken}
+	if request.TokenID != nil {
+		query = "SELECT id, token, " + qrTokenUsable + " AND expires_at IS NULL FROM qr_tokens WHERE BusinessId = $1 AND id = $2 FOR UPDATE"
+		args = []any{businessID, *request.TokenID}
+	}
+	err = tx.QueryRowContext(r.Context(), query, args...).Scan(&previousID, &previousToken, &usable)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "qrcode.token_not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+	if !usable {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "qrcode.token_not_active")
+		return
+	}
+
+	tokenID := uuid.New()
+	token := uuid.New().String()
+	_, err = tx.ExecContext(r.Context(), `
+		INSERT INTO qr_tokens (id, BusinessId, token, label, ticket_prefix)
+		SELECT $1, BusinessId, $2, label, ticket_prefix FROM qr_tokens WHERE id = $3`, tokenID, token, previousID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+	_, err = tx.ExecContext(r.Context(), "UPDATE qr_tokens SET expires_at = NOW() + make_interval(mins => $2), replaced_by = $3 WHERE id = $1",
+		previousID, request.GraceMinutes, tokenID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+	if previousToken == primaryToken {
+		_, err = tx.ExecContext(r.Context(), "UPDATE businesses SET qr_code_token = $2, updated_at = NOW() WHERE id = $1", businessID, token)
+		if err != nil {
+			utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+
+	response := models.QRTokenRotateResponse{Message: utils.T(r, "qrcode.token_rotated")}
+	if response.Token, err = fetchQRToken(r.Context(), businessID, tokenID); err == nil {
+		response.Previous, err = fetchQRToken(r.Context(), businessID, previousID)
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RotateQRTokenHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "QR code remplacé", "business_id", businessID, "qr_token_id", tokenID, "previous_id", previousID, "grace_minutes", request.GraceMinutes)
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+/*
+Révoquer un QR code : il n'est plus reconnu immédiatement (délai de grâce d'une rotation compris)
+Le QR code principal se remplace (rotation) mais ne se révoque pas : l'entreprise garde toujours un code valable
+*/
+func RevokeQRTokenHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "qrcodeHandlers.go -> RevokeQRTokenHandler()")
+	if !ok {
+		return
+	}
+
+	tokenID, err := uuid.Parse(r.PathValue("token_id"))
+	if err != nil {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "qrcode.token_not_found")
+		return
+	}
+
+	result, err := database.DB.ExecContext(r.Context(), `
+		UPDATE qr_tokens t SET revoked_at = COALESCE(t.revoked_at, NOW())
+		FROM businesses b
+		WHERE b.id = t.BusinessId AND t.BusinessId = $1 AND t.id = $2 AND t.token <> b.qr_code_token`, businessID, tokenID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RevokeQRTokenHandler()", err)
+		return
+	}
+	if revoked, err := result.RowsAffected(); err != nil || revoked == 0 {
+		// Token inconnu ou QR code principal
+		var exists bool
+		if err == nil {
+			err = database.DB.QueryRowContext(r.Context(), "SELECT EXISTS(SELECT 1 FROM qr_tokens WHERE BusinessId = $1 AND id = $2)", businessID, tokenID).Scan(&exists)
+		}
+		switch {
+		case err != nil:
+			utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RevokeQRTokenHandler()", err)
+		case exists:
+			utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "qrcode.token_primary")
+		default:
+			utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "qrcode.token_not_found")
+		}
+		return
+	}
+
+	token, err := fetchQRToken(r.Context(), businessID, tokenID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> RevokeQRTokenHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "QR code révoqué", "business_id", businessID, "qr_token_id", tokenID)
+	utils.WriteJSON(w, http.StatusOK, models.QRTokenResponse{Message: utils.T(r, "qrcode.token_revoked"), Token: token})
+}
+
+/*
+Page client : entreprise désignée par le QR code scanné (route publique)
+Chaque appel compte comme un scan du QR code, un token révoqué ou expiré répond 404
+*/
+func QueueTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var response models.QueueTokenResponse
+	var logoKey string
+	err := database.DB.QueryRowContext(r.Context(), `
+		WITH scanned AS (
+			UPDATE qr_tokens SET scan_count = scan_count + 1, last_scanned_at = NOW()
+			WHERE token = $1 AND `+qrTokenUsable+`
+			RETURNING BusinessId
+		)
+		SELECT b.id, b.name, COALESCE(b.custom_message, ''), b.default_language, COALESCE(b.logo, ''),
+			COALESCE(b.is_queue_active, false), COALESCE(b.is_queue_paused, false)
+		FROM businesses b JOIN scanned s ON s.BusinessId = b.id
+		WHERE b.is_active = true`, r.PathValue("token")).Scan(
+		&response.BusinessID, &response.Name, &response.CustomMessage, &response.DefaultLanguage, &logoKey,
+		&response.IsQueueActive, &response.IsQueuePaused)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "qrcode.token_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "qrcodeHandlers.go -> QueueTokenHandler()", err)
+		return
+	}
+	response.LogoURL = storage.PublicURL(r.Context(), logoKey)
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// Token scannable : identifiants du token et de son entreprise (sql.ErrNoRows sinon)
+func usableQRToken(ctx context.Context, token string) (tokenID, businessID uuid.UUID, err error) {
+	err = database.DB.QueryRowContext(ctx, "SELECT id, BusinessId FROM qr_tokens WHERE token = $1 AND "+qrTokenUsable, token).
+		Scan(&tokenID, &businessID)
+	return tokenID, businessID, err
+}
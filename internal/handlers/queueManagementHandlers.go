@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Colonnes d'une entrée du tableau de bord (cf. scanQueueDashboardEntry)
+const queueDashboardSelect = `
+	SELECT id, ticket_prefix, COALESCE(ticket_number, 0), COALESCE(client_name, ''), phone, position, status, language, source,
+		(EXTRACT(EPOCH FROM NOW() - created_at) / 60)::integer, COALESCE(sms_sent_count, 0), called_at, created_at
+	FROM queue_entries`
+
+/*
+Tableau de bord du commerçant : clients appelés puis en attente, et compteurs de la journée
+Le temps d'attente estimé d'un client en attente est calculé avec la durée moyenne de service, selon sa position actuelle
+*/
+func QueueDashboardHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "queueManagementHandlers.go -> QueueDashboardHandler()")
+	if !ok {
+		return
+	}
+
+	response := models.QueueDashboardResponse{BusinessID: businessID, Entries: []models.QueueDashboardEntry{}, GeneratedAt: time.Now()}
+	err := database.DB.QueryRowContext(r.Context(), `
+		SELECT COALESCE(is_queue_active, false), COALESCE(is_queue_paused, false), COALESCE(average_service_time, 300)
+		FROM businesses WHERE id = $1`, businessID).Scan(&response.IsQueueActive, &response.IsQueuePaused, &response.AverageServiceTime)
+	if err != nil {
+		utils.WriteDBError(w, r, "queueManagementHandlers.go -> QueueDashboardHandler()", err)
+		return
+	}
+
+	rows, err := database.DB.QueryContext(r.Context(), queueDashboardSelect+`
+		WHERE BusinessId = $1 AND status IN ('waiting', 'called')
+		ORDER BY status = 'waiting', called_at, position, created_at`, businessID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> QueueDashboardHandler()", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanQueueDashboardEntry(rows, response.AverageServiceTime, response.GeneratedAt)
+		if err != nil {
+			utils.WriteInternalError(w, r, "queueManagementHandlers.go -> QueueDashboardHandler()", err)
+			return
+		}
+		response.Entries = append(response.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> QueueDashboardHandler()", err)
+		return
+	}
+	rows.Close()
+
+	// Journée : depuis minuit (fuseau horaire des commerces, BUSINESS_TIMEZONE)
+	var averageWait sql.NullInt64
+	err = database.DB.QueryRowContext(r.Context(), `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= $2),
+			COUNT(*) FILTER (WHERE status = 'served' AND COALESCE(served_at, updated_at) >= $2),
+			COUNT(*) FILTER (WHERE status = 'missed' AND updated_at >= $2),
+			COUNT(*) FILTER (WHERE status = 'cancelled' AND updated_at >= $2),
+			ROUND(AVG(EXTRACT(EPOCH FROM called_at - created_at) / 60) FILTER (WHERE called_at >= $2))::integer
+		FROM queue_entries
+		WHERE BusinessId = $1 AND (created_at >= $2 OR updated_at >= $2)`, businessID, businessDayStart(response.GeneratedAt)).Scan(
+		&response.Today.Registered,
+		&response.Today.Served,
+		&response.Today.Missed,
+		&response.Today.Cancelled,
+		&averageWait,
+	)
+	if err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> QueueDashboardHandler()", err)
+		return
+	}
+	if averageWait.Valid {
+		minutes := int(averageWait.Int64)
+		response.Today.AverageWaitMinutes = &minutes
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// Déplacer un client en attente à une autre position (les clients entre les deux positions sont décalés)
+func MoveQueueEntryHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.QueueMoveRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	var details []models.FieldError
+	if request.Position < 1 {
+		details = append(details, models.FieldError{Field: "position", Code: "out_of_range", Message: "queue.position_invalid"})
+	}
+	details = append(details, validateQueueNote(request.Note)...)
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
+		return
+	}
+
+	moveQueueEntry(w, r, models.QueueActionMove, request.Position, request.Note)
+}
+
+// Renvoyer un client en attente en fin de file (ex : absent au moment de l'appel, sans le retirer de la file)
+func MoveQueueEntryToEndHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.QueueMoveToEndRequest
+	if r.ContentLength != 0 && !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if details := validateQueueNote(request.Note); len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
+		return
+	}
+
+	moveQueueEntry(w, r, models.QueueActionMoveToEnd, 0, request.Note)
+}
+
+/*
+Déplacement d'un client en attente (position 0 : fin de file), enregistré dans queue_entry_events
+La ligne de l'entreprise est verrouillée : les modifications de la file sont traitées l'une après l'autre
+*/
+func moveQueueEntry(w http.ResponseWriter, r *http.Request, action string, position int, note string) {
+	claims := utils.ClaimsFromContext(r.Context())
+	businessID, ok := requireBusinessOwner(w, r, "queueManagementHandlers.go -> moveQueueEntry()")
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> moveQueueEntry()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	entryID, status, from, averageServiceTime, ok := lockQueueEntry(w, r, tx, businessID)
+	if !ok {
+		return
+	}
+	if status != "waiting" {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "queue.entry_not_waiting")
+		return
+	}
+
+	var waiting int
+	if err := tx.QueryRowContext(r.Context(), "SELECT COUNT(*) FROM queue_entries WHERE BusinessId = $1 AND status = 'waiting'", businessID).Scan(&waiting); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> moveQueueEntry()", err)
+		return
+	}
+	to := min(position, waiting)
+	if position == 0 {
+		to = waiting
+	}
+
+	// Décalage des clients entre l'ancienne et la nouvelle position, les positions restent continues (1 à n)
+	if to != from {
+		_, err = tx.ExecContext(r.Context(), `
+			UPDATE queue_entries SET position = CASE
+				WHEN id = $2 THEN $4::integer
+				WHEN $4::integer < $3::integer THEN position + 1
+				ELSE position - 1
+			END
+			WHERE BusinessId = $1 AND status = 'waiting' AND position BETWEEN LEAST($3::integer, $4::integer) AND GREATEST($3::integer, $4::integer)`,
+			businessID, entryID, from, to)
+		if err != nil {
+			utils.WriteInternalError(w, r, "queueManagementHandlers.go -> moveQueueEntry()", err)
+			return
+		}
+	}
+
+	if err := recordQueueEvent(r.Context(), tx, entryID, businessID, claims.UserID, action, "", note, from, to); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> moveQueueEntry()", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> moveQueueEntry()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Client déplacé dans la file", "business_id", businessID, "entry_id", entryID, "action", action, "from", from, "to", to)
+	writeQueueEntryAction(w, r, entryID, averageServiceTime, "queue.entry_moved")
+}
+
+/*
+Retirer un client en attente ou appelé : marqué absent (missed) pour le motif no_show, annulé (cancelled) sinon
+Les positions des clients suivants sont recalculées par le trigger de la table
+*/
+func RemoveQueueEntryHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.QueueRemoveRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	var details []models.FieldError
+	if !slices.Contains(models.QueueRemoveReasons, request.Reason) {
+		details = append(details, models.FieldError{Field: "reason", Code: "invalid_choice", Message: "queue.remove_reason_invalid",
+			Args: []any{strings.Join(models.QueueRemoveReasons, ", ")}})
+	}
+	details = append(details, validateQueueNote(request.Note)...)
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
+		return
+	}
+
+	businessID, ok := requireBusinessOwner(w, r, "queueManagementHandlers.go -> RemoveQueueEntryHandler()")
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> RemoveQueueEntryHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	entryID, status, position, averageServiceTime, ok := lockQueueEntry(w, r, tx, businessID)
+	if !ok {
+		return
+	}
+	if status != "waiting" && status != "called" {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "queue.entry_not_active")
+		return
+	}
+
+	newStatus := "cancelled"
+	if request.Reason == "no_show" {
+		newStatus = "missed"
+	}
+	if _, err := tx.ExecContext(r.Context(), "UPDATE queue_entries SET status = $2, updated_at = NOW() WHERE id = $1", entryID, newStatus); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> RemoveQueueEntryHandler()", err)
+		return
+	}
+
+	if err := recordQueueEvent(r.Context(), tx, entryID, businessID, claims.UserID, models.QueueActionRemove, request.Reason, request.Note, position, 0); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> RemoveQueueEntryHandler()", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> RemoveQueueEntryHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Client retiré de la file", "business_id", businessID, "entry_id", entryID, "reason", request.Reason)
+	writeQueueEntryAction(w, r, entryID, averageServiceTime, "queue.entry_removed")
+}
+
+/*
+Entrée de l'URL ({entry_id}) dans la file de l'entreprise, verrouillée avec la ligne de l'entreprise
+404 si elle n'existe pas ou appartient à une autre entreprise
+*/
+func lockQueueEntry(w http.ResponseWriter, r *http.Request, tx *sql.Tx, businessID uuid.UUID) (entryID uuid.UUID, status string, position, averageServiceTime int, ok bool) {
+	err := tx.QueryRowContext(r.Context(), "SELECT COALESCE(average_service_time, 300) FROM businesses WHERE id = $1 FOR UPDATE", businessID).
+		Scan(&averageServiceTime)
+	if err != nil {
+		utils.WriteDBError(w, r, "queueManagementHandlers.go -> lockQueueEntry()", err)
+		return
+	}
+
+	entryID, err = uuid.Parse(r.PathValue("entry_id"))
+	if err == nil {
+		err = tx.QueryRowContext(r.Context(), "SELECT status, position FROM queue_entries WHERE id = $1 AND BusinessId = $2 FOR UPDATE", entryID, businessID).
+			Scan(&status, &position)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows || entryID == uuid.Nil {
+			utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "queue.entry_not_found")
+			return
+		}
+		utils.WriteInternalError(w, r, "queueManagementHandlers.go -> lockQueueEntry()", err)
+		return
+	}
+	return entryID, status, position, averageServiceTime, true
+}
+
+// Note facultative du commerçant
+func validateQueueNote(note string) []models.FieldError {
+	if utf8.RuneCountInString(note) > models.QueueNoteMaxLength {
+		return []models.FieldError{{Field: "note", Code: "too_long", Message: "queue.note_length", Args: []any{models.QueueNoteMaxLength}}}
+	}
+	return nil
+}
+
+// Historique des actions du commerçant (position 0 : sans objet)
+func recordQueueEvent(ctx context.Context, tx *sql.Tx, entryID, businessID, userID uuid.UUID, action, reason, note string, from, to int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO queue_entry_events (QueueEntryId, BusinessId, UserId, action, reason, note, from_position, to_position)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), NULLIF($8, 0))`,
+		entryID, businessID, userID, action, nullIfEmpty(reason), nullIfEmpty(note), from, to)
+	if err != nil {
+		return fmt.Errorf("[queueManagementHandlers.go -> recordQueueEvent()] -> %w", err)
+	}
+	return nil
+}
+
+// Réponse d'une action sur un client : son entrée après modification
+func writeQueueEntryAction(w http.ResponseWriter, r *http.Request, entryID uuid.UUID, averageServiceTime int, messageKey string) {
+	now := time.Now()
+	entry, err := scanQueueDashboardEntry(database.DB.QueryRowContext(r.Context(), queueDashboardSelect+" WHERE id = $1", entryID), averageServiceTime, now)
+	if err != nil {
+		utils.WriteDBError(w, r, "queueManagementHandlers.go -> writeQueueEntryAction()", err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, models.QueueEntryActionResponse{
+		Message: utils.T(r, messageKey),
+		Entry:   entry,
+	})
+}
+
+// Entrée du tableau de bord, temps d'attente estimé selon la position pour un client en attente
+func scanQueueDashboardEntry(row interface{ Scan(...any) error }, averageServiceTime int, now time.Time) (models.QueueDashboardEntry, error) {
+	var entry models.QueueDashboardEntry
+	var ticketPrefix string
+	var ticketNumber int
+	err := row.Scan(
+		&entry.ID,
+		&ticketPrefix,
+		&ticketNumber,
+		&entry.ClientName,
+		&entry.Phone,
+		&entry.Position,
+		&entry.Status,
+		&entry.Language,
+		&entry.Source,
+		&entry.WaitingMinutes,
+		&entry.SmsSentCount,
+		&entry.CalledAt,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return entry, err
+	}
+	entry.Ticket = models.TicketLabel(ticketPrefix, ticketNumber)
+	entry.Remote = entry.Source == models.QueueSourceRemote
+	if entry.Status == "waiting" {
+		entry.EstimatedWaitTime = (entry.Position - 1) * averageServiceTime / 60
+		callAt := now.Add(time.Duration(entry.EstimatedWaitTime) * time.Minute)
+		entry.EstimatedCallAt = &callAt
+	}
+	return entry, nil
+}
@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/i18n"
 	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/telemetry"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 /*
@@ -18,127 +24,161 @@ Côté Font on va envoyer un booléen (true ou false) pour activer ou désactive
 */
 func ActivateQueueHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, `Mauvaise requête HTTP (mauvaise méthode).`, http.StatusMethodNotAllowed)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
+		return
 	}
 
 	var statusRequest *models.BusinessQueueStatusRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&statusRequest); err != nil {
-		log.Println(`Mauvais corps de requête : `, err)
-		http.Error(w, `Corps de la requête invalide.`, http.StatusBadRequest)
+	if !utils.DecodeJSON(w, r, &statusRequest) {
 		return
 	}
-
-	// Récupérer l'ID de l'entreprise depuis l'URL
-	IDParam := r.PathValue("id")
-	log.Println(IDParam)
-
-	// Vérifier si l'entreprise existe
-	var businessExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM businesses WHERE id = $1)",
-		IDParam).Scan(&businessExists)
-	if err != nil {
-		http.Error(w, `Erreur vérification de l'existance de l'entreprise : `+err.Error(), http.StatusInternalServerError)
+	if statusRequest == nil || statusRequest.IsQueueActive == nil {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "queue.status_required",
+			models.FieldError{Field: "is_queue_active", Code: "required", Message: "queue.status_required"})
 		return
 	}
-	if !businessExists {
-		log.Println("L'entreprise avec cet 'id' n'existe pas en base de données : ", err)
-		http.Error(w, `L'entreprise n'existe pas en base de données ! `+err.Error(), http.StatusConflict)
+
+	// Entreprise de l'URL : propriétaire uniquement, pas une entreprise supprimée
+	businessID, ok := requireBusinessOwner(w, r, "queuesHandlers.go -> ActivateQueueHandler()")
+	if !ok {
 		return
 	}
 
-	// Query base de données
-	updt, err := database.DB.Exec(`UPDATE businesses SET is_queue_active=$2 WHERE id=$1 RETURNING *;`, IDParam, &statusRequest.IsQueueActive)
+	// Query base de données (l'entreprise peut avoir été supprimée entre-temps)
+	updt, err := database.DB.ExecContext(r.Context(), "UPDATE businesses SET is_queue_active = $2 WHERE id = $1 AND deleted_at IS NULL",
+		businessID, *statusRequest.IsQueueActive)
 	if err != nil {
-		http.Error(w, "Erreur lors de la modification de l'entreprise : "+err.Error(), http.StatusInternalServerError)
+		utils.WriteDBError(w, r, "queuesHandlers.go -> ActivateQueueHandler()", err)
 		return
 	}
 
-	log.Println(`Informations récupérées : `, *statusRequest.IsQueueActive)
-
 	rowsAffected, err := updt.RowsAffected()
 	if err != nil {
-		log.Fatalf("Erreur lors de la vérification du nombre de lignes modifiées. %v", err)
+		utils.WriteInternalError(w, r, "queuesHandlers.go -> ActivateQueueHandler()", err)
+		return
+	}
+	if rowsAffected == 0 {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.archived")
+		return
 	}
-	log.Println(`Nombre de lignes modifiées : `, rowsAffected)
+	slog.InfoContext(r.Context(), "État de la file d'attente modifié", "business_id", businessID, "is_queue_active", *statusRequest.IsQueueActive)
 
-	response := []string{"File d'attente ouverte !"}
+	response := []string{utils.T(r, "queue.opened")}
+	if !*statusRequest.IsQueueActive {
+		response = []string{utils.T(r, "queue.stopped")}
+	}
 
-	w.WriteHeader(200)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 }
 
 // Rejoindre une file d'attente
 func JoinQueueHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `Méthode non autorisée`, http.StatusMethodNotAllowed)
+		utils.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "error.method_not_allowed")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
 	// 1. Décoder la requête
 	var req models.JoinQueueRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Println("Erreur parsing JSON:", err)
-		http.Error(w, `Corps de requête invalide`, http.StatusBadRequest)
+	if !utils.DecodeJSON(w, r, &req) {
 		return
 	}
 
 	// 2. Validation des champs obligatoires
-	if req.BusinessID == uuid.Nil {
-		http.Error(w, `BusinessID requis`, http.StatusBadRequest)
-		return
+	var details []models.FieldError
+	if req.BusinessID == uuid.Nil && req.QRToken == "" {
+		details = append(details, models.FieldError{Field: "business_id", Code: "required", Message: "queue.business_id_required"})
 	}
 	if req.Phone == "" {
-		http.Error(w, `Numéro de téléphone requis`, http.StatusBadRequest)
-		return
+		details = append(details, models.FieldError{Field: "phone", Code: "required", Message: "queue.phone_required"})
+	} else if err := models.ValidateBusinessPhoneNumber(req.Phone); err != nil {
+		details = append(details, models.FieldError{Field: "phone", Code: "invalid_format", Message: "queue.phone_invalid"})
 	}
-	if err := models.ValidateBusinessPhoneNumber(req.Phone); err != nil {
-		http.Error(w, `Format de téléphone invalide`, http.StatusBadRequest)
+	if req.ClientName == "" {
+		details = append(details, models.FieldError{Field: "client_name", Code: "required", Message: "queue.client_name_required"})
+	}
+	if req.Language != "" && !i18n.IsSupported(req.Language) {
+		details = append(details, models.FieldError{Field: "language", Code: "invalid_choice", Message: "queue.language_invalid"})
+	}
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
 		return
 	}
-	if req.ClientName == "" {
-		http.Error(w, `Nom du client requis`, http.StatusBadRequest)
+
+	// 3. QR code scanné : il désigne l'entreprise et doit encore être valable
+	var qrTokenID *uuid.UUID
+	source := models.QueueSourceRemote
+	if req.QRToken != "" {
+		tokenID, businessID, err := usableQRToken(r.Context(), req.QRToken)
+		if err == sql.ErrNoRows {
+			utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "qrcode.token_invalid")
+			return
+		}
+		if err != nil {
+			utils.WriteInternalError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
+			return
+		}
+		if req.BusinessID != uuid.Nil && req.BusinessID != businessID {
+			utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "queue.business_mismatch",
+				models.FieldError{Field: "qr_token", Code: "mismatch", Message: "queue.business_mismatch"})
+			return
+		}
+		req.BusinessID = businessID
+		qrTokenID = &tokenID
+		source = models.QueueSourceQR
+	}
+
+	// Étapes 4 à 10 dans une transaction, sous verrou de la ligne de l'entreprise : les inscriptions simultanées
+	// à une même file passent l'une après l'autre, doublons, capacité et position restent exacts jusqu'à l'insertion
+	// (l'index unique sur les clients en attente reste le dernier garde-fou)
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
 		return
 	}
+	defer tx.Rollback()
 
-	// 3. Vérifier que le business existe ET que la file est active
+	// Vérifier que le business existe ET que la file est active
 	var business struct {
+		Name               string
 		IsQueueActive      bool
 		MaxQueueSize       int
 		AverageServiceTime int // en secondes
+		DefaultLanguage    string
 	}
 
-	err := database.DB.QueryRow(`
-		SELECT is_queue_active, max_queue_size, average_service_time
+	err = tx.QueryRowContext(r.Context(), `
+		SELECT name, is_queue_active, max_queue_size, average_service_time, default_language
 		FROM businesses
 		WHERE id = $1 AND is_active = true
+		FOR UPDATE
 	`, req.BusinessID).Scan(
+		&business.Name,
 		&business.IsQueueActive,
 		&business.MaxQueueSize,
 		&business.AverageServiceTime,
+		&business.DefaultLanguage,
 	)
 
 	if err == sql.ErrNoRows {
-		http.Error(w, `Business introuvable ou inactif`, http.StatusNotFound)
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.not_found_or_inactive")
 		return
 	}
 	if err != nil {
-		log.Println("Erreur DB:", err)
-		http.Error(w, `Erreur serveur`, http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
 		return
 	}
 
 	// 4. Vérifier que la file est active
 	if !business.IsQueueActive {
-		http.Error(w, `La file d'attente est fermée`, http.StatusForbidden)
+		utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeQueueClosed, "queue.closed")
 		return
 	}
 
 	// 5. Vérifier que le client n'est pas déjà dans la file
 	var alreadyInQueue bool
-	err = database.DB.QueryRow(`
+	err = tx.QueryRowContext(r.Context(), `
 		SELECT EXISTS(
 			SELECT 1 FROM queue_entries
 			WHERE BusinessId = $1 AND phone = $2 AND status = 'waiting'
@@ -146,47 +186,72 @@ func JoinQueueHandler(w http.ResponseWriter, r *http.Request) {
 	`, req.BusinessID, req.Phone).Scan(&alreadyInQueue)
 
 	if err != nil {
-		log.Println("Erreur vérification doublon:", err)
-		http.Error(w, `Erreur serveur`, http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
 		return
 	}
 	if alreadyInQueue {
-		http.Error(w, `Vous êtes déjà dans la file d'attente`, http.StatusConflict)
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeAlreadyInQueue, "queue.already_joined")
 		return
 	}
 
 	// 6. Vérifier que la file n'est pas pleine
 	var currentQueueSize int
-	err = database.DB.QueryRow(`
+	err = tx.QueryRowContext(r.Context(), `
 		SELECT COUNT(*) FROM queue_entries
 		WHERE BusinessId = $1 AND status = 'waiting'
 	`, req.BusinessID).Scan(&currentQueueSize)
 
 	if err != nil {
-		log.Println("Erreur comptage file:", err)
-		http.Error(w, `Erreur serveur`, http.StatusInternalServerError)
+		utils.WriteInternalError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
 		return
 	}
 	if currentQueueSize >= business.MaxQueueSize {
-		http.Error(w, `File d'attente complète`, http.StatusServiceUnavailable)
+		utils.WriteError(w, r, http.StatusServiceUnavailable, models.ErrCodeQueueFull, "queue.full")
 		return
 	}
 
-	// 7. Calculer la position (sera recalculée par le trigger, mais on l'initialise)
+	// 7. Calculer la position (exacte sous le verrou, les positions en attente vont de 1 à currentQueueSize)
 	nextPosition := currentQueueSize + 1
 
 	// 8. Calculer le temps d'attente estimé
 	estimatedWaitMinutes := (currentQueueSize * business.AverageServiceTime) / 60
 
-	// 9. Insérer dans la base (le trigger recalculera automatiquement les positions)
+	// 9. Langue des SMS et de la page client : choix du client, sinon langue par défaut du commerce
+	language := req.Language
+	if language == "" {
+		language = business.DefaultLanguage
+	}
+	if !i18n.IsSupported(language) {
+		language = i18n.DefaultLanguage
+	}
+
+	// 10. Insérer dans la base (le trigger recalculera automatiquement les positions)
+	// Numéro de ticket attribué dans la même requête : le compteur du jour (fuseau des commerces) est incrémenté
+	// sous verrou de ligne, deux inscriptions simultanées ne reçoivent jamais le même numéro
 	entryID := uuid.New()
 	now := time.Now()
-
-	_, err = database.DB.Exec(`
+	var ticketPrefix string
+	var ticketNumber int
+
+	err = tx.QueryRowContext(r.Context(), `
+		WITH prefix AS (
+			SELECT COALESCE(t.ticket_prefix, b.ticket_prefix) AS prefix
+			FROM businesses b
+			LEFT JOIN qr_tokens t ON t.id = $9 AND t.BusinessId = b.id
+			WHERE b.id = $2
+		), counter AS (
+			INSERT INTO ticket_counters (BusinessId, day, prefix, last_number)
+			SELECT $2, $13::date, prefix, 1 FROM prefix
+			ON CONFLICT (BusinessId, day, prefix) DO UPDATE SET last_number = ticket_counters.last_number + 1
+			RETURNING day, prefix, last_number
+		)
 		INSERT INTO queue_entries (
-			id, BusinessId, phone, client_name, position, 
-			estimated_wait_time, status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			id, BusinessId, phone, client_name, position,
+			estimated_wait_time, status, language, qr_token_id, source, created_at, updated_at,
+			ticket_date, ticket_prefix, ticket_number
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $12, $10, $11, day, prefix, last_number FROM counter
+		RETURNING ticket_prefix, ticket_number
 	`,
 		entryID,
 		req.BusinessID,
@@ -195,34 +260,60 @@ func JoinQueueHandler(w http.ResponseWriter, r *http.Request) {
 		nextPosition,
 		estimatedWaitMinutes,
 		"waiting",
+		language,
+		qrTokenID,
 		now,
 		now,
-	)
+		source,
+		businessDayStart(now).Format(time.DateOnly),
+	).Scan(&ticketPrefix, &ticketNumber)
 
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "idx_queue_entries_waiting_phone" {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeAlreadyInQueue, "queue.already_joined")
+		return
+	}
 	if err != nil {
-		log.Println("Erreur insertion queue_entries:", err)
-		http.Error(w, `Impossible de rejoindre la file`, http.StatusInternalServerError)
+		utils.WriteDBError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "queuesHandlers.go -> JoinQueueHandler()", err)
 		return
 	}
+	ticket := models.TicketLabel(ticketPrefix, ticketNumber)
 
-	// 10. TODO : Envoyer SMS de confirmation (à implémenter plus tard)
-	// sendSMS(req.Phone, fmt.Sprintf("Vous êtes en position %d. Temps d'attente: ~%d min", nextPosition, estimatedWaitMinutes))
+	telemetry.QueueJoined()
 
-	// 11. Réponse succès
+	// 11. TODO : Envoyer SMS de confirmation (à implémenter plus tard)
+	// err = sendSMS(req.Phone, i18n.T(language, "sms.confirmation", ticket, business.Name, nextPosition, estimatedWaitMinutes))
+	// telemetry.RecordSMS("confirmation", err)
+
+	// 12. Réponse succès
 	response := models.JoinQueueResponse{
-		Message: "Vous avez été ajouté à la file d'attente",
+		Message: utils.T(r, "queue.joined"),
 		Entry: models.QueueEntry{
 			ID:                entryID,
 			BusinessID:        req.BusinessID,
 			Phone:             req.Phone,
 			ClientName:        req.ClientName,
+			Ticket:            ticket,
 			Position:          nextPosition,
 			EstimatedWaitTime: estimatedWaitMinutes,
 			Status:            "waiting",
+			Language:          language,
 			CreatedAt:         now,
 		},
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+// Tâche de fond : supprimer les compteurs de tickets des jours passés (les numéros repartent de 1 chaque jour)
+func CleanupTicketCounters(ctx context.Context) error {
+	today := businessDayStart(time.Now()).Format(time.DateOnly)
+	if _, err := database.DB.ExecContext(ctx, "DELETE FROM ticket_counters WHERE day < $1::date", today); err != nil {
+		return fmt.Errorf("[queuesHandlers.go -> CleanupTicketCounters()] -> %w", err)
+	}
+	return nil
 }
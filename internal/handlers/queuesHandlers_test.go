@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/google/uuid"
+)
+
+/*
+Inscriptions simultanées dans une file, sur une base PostgreSQL de test (WAITIFY_TEST_DATABASE_URL)
+Toutes les inscriptions partent en même temps, avec des numéros en double et plus de clients que de places :
+la file ne dépasse pas max_queue_size, un seul client en attente par numéro, positions et tickets de 1 à n sans doublon
+*/
+func TestJoinQueueHandlerConcurrent(t *testing.T) {
+	dsn := os.Getenv("WAITIFY_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("WAITIFY_TEST_DATABASE_URL non défini : test sur base de données ignoré")
+	}
+
+	const (
+		clients  = 200
+		phones   = 40
+		maxQueue = 30
+	)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connexion à la base de test : %v", err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		db.Close()
+	})
+
+	ctx := context.Background()
+	if _, err := database.Migrate(ctx); err != nil {
+		t.Fatalf("migrations : %v", err)
+	}
+
+	// Données de test, supprimées à la fin (l'entreprise et ses inscriptions suivent le compte)
+	userID, businessID := uuid.New(), uuid.New()
+	if _, err := db.ExecContext(ctx, "INSERT INTO users (id, email) VALUES ($1, $2)", userID, "test-"+userID.String()+"@waitify.invalid"); err != nil {
+		t.Fatalf("création du compte de test : %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.ExecContext(context.Background(), "DELETE FROM users WHERE id = $1", userID); err != nil {
+			t.Errorf("données de test non supprimées (compte %s) : %v", userID, err)
+		}
+	})
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO businesses (id, UserId, name, business_type, qr_code_token, is_queue_active, max_queue_size, ticket_prefix)
+		VALUES ($1, $2, 'Test inscriptions simultanées', 'other', $3, true, $4, 'T')`, businessID, userID, uuid.New().String(), maxQueue)
+	if err != nil {
+		t.Fatalf("création de l'entreprise de test : %v", err)
+	}
+
+	// Toutes les inscriptions partent en même temps
+	statuses := map[int]int{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"business_id": %q, "phone": "06%08d", "client_name": "Client %d"}`, businessID, i%phones, i)
+			request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/queue/join", strings.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			<-start
+			JoinQueueHandler(recorder, request)
+			mu.Lock()
+			statuses[recorder.Code]++
+			mu.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for status, count := range statuses {
+		if status != http.StatusCreated && status != http.StatusConflict && status != http.StatusServiceUnavailable {
+			t.Fatalf("%d réponses inattendues %d (réponses : %v)", count, status, statuses)
+		}
+	}
+
+	var waiting, distinctPhones, minPosition, maxPosition, distinctPositions, maxTicket, distinctTickets int
+	err = db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT phone), COALESCE(MIN(position), 0), COALESCE(MAX(position), 0), COUNT(DISTINCT position),
+			COALESCE(MAX(ticket_number), 0), COUNT(DISTINCT ticket_number)
+		FROM queue_entries WHERE BusinessId = $1 AND status = 'waiting'`, businessID).Scan(
+		&waiting, &distinctPhones, &minPosition, &maxPosition, &distinctPositions, &maxTicket, &distinctTickets)
+	if err != nil {
+		t.Fatalf("lecture de la file : %v", err)
+	}
+
+	if expected := min(phones, maxQueue); statuses[http.StatusCreated] != expected {
+		t.Fatalf("%d inscriptions acceptées, %d attendues (réponses : %v)", statuses[http.StatusCreated], expected, statuses)
+	}
+	if waiting != statuses[http.StatusCreated] {
+		t.Fatalf("%d clients en attente pour %d inscriptions acceptées", waiting, statuses[http.StatusCreated])
+	}
+	if waiting > maxQueue {
+		t.Fatalf("%d clients en attente pour une file de %d places", waiting, maxQueue)
+	}
+	if distinctPhones != waiting {
+		t.Fatalf("%d numéros distincts pour %d clients en attente", distinctPhones, waiting)
+	}
+	if minPosition != 1 || maxPosition != waiting || distinctPositions != waiting {
+		t.Fatalf("positions de %d à %d (%d distinctes) pour %d clients", minPosition, maxPosition, distinctPositions, waiting)
+	}
+	if distinctTickets != waiting || maxTicket != waiting {
+		t.Fatalf("%d tickets distincts (dernier : %d) pour %d clients", distinctTickets, maxTicket, waiting)
+	}
+}
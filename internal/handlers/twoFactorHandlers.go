@@ -0,0 +1,505 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Taille du QR code d'enrôlement (pixels)
+const twoFactorQRCodeSize = 256
+
+/*
+Démarrer l'enrôlement : nouveau secret TOTP (chiffré en base) et QR code à scanner dans l'application
+La 2FA n'est active qu'après confirmation d'un premier code (POST /auth/2fa/enable)
+*/
+func TwoFactorSetupHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var enabled bool
+	err := database.DB.QueryRowContext(r.Context(), "SELECT totp_enabled_at IS NOT NULL FROM users WHERE id = $1", claims.UserID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorSetupHandler()", err)
+		return
+	}
+	if enabled {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "2fa.already_enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorSetupHandler()", err)
+		return
+	}
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorSetupHandler()", err)
+		return
+	}
+
+	_, err = database.DB.ExecContext(r.Context(),
+		"UPDATE users SET totp_secret = $2, totp_last_step = NULL, updated_at = NOW() WHERE id = $1 AND totp_enabled_at IS NULL",
+		claims.UserID, encrypted)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorSetupHandler()", err)
+		return
+	}
+
+	uri := utils.TOTPURI(claims.Email, secret)
+	qrCode := utils.QRCode{Content: uri, Size: twoFactorQRCodeSize}
+	png, err := qrCode.Generate()
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorSetupHandler()", err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURL: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+/*
+Activer la 2FA avec un premier code de l'application
+Retourne les codes de secours (affichés une seule fois) et un nouveau token de session qui inclut la double authentification
+*/
+func TwoFactorEnableHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.TwoFactorCodeRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if request.Code == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "2fa.code_required",
+			models.FieldError{Field: "code", Code: "required", Message: "2fa.code_required"})
+		return
+	}
+
+	var encrypted sql.NullString
+	var enabled bool
+	err := database.DB.QueryRowContext(r.Context(), "SELECT totp_secret, totp_enabled_at IS NOT NULL FROM users WHERE id = $1", claims.UserID).
+		Scan(&encrypted, &enabled)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+	if enabled {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "2fa.already_enabled")
+		return
+	}
+	if !encrypted.Valid {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "2fa.setup_required")
+		return
+	}
+
+	secret, err := utils.DecryptSecret(encrypted.String)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+	step, ok := utils.ValidateTOTP(secret, request.Code, time.Now())
+	if !ok {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeInvalidOTP, "2fa.code_invalid",
+			models.FieldError{Field: "code", Code: "invalid", Message: "2fa.code_invalid"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(r.Context(),
+		"UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW() WHERE id = $1 AND totp_enabled_at IS NULL AND totp_secret = $3",
+		claims.UserID, step, encrypted.String)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+	// Activation concurrente ou nouvel enrôlement entre-temps
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "2fa.setup_required")
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(r.Context(), tx, claims.UserID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+
+	token, err := utils.GenerateToken(claims.UserID, claims.Email, utils.AMRPassword, utils.AMROTP)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorEnableHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Double authentification activée", "user_id", claims.UserID)
+	utils.WriteJSON(w, http.StatusOK, models.RecoveryCodesResponse{
+		Message:       utils.T(r, "2fa.enabled"),
+		RecoveryCodes: codes,
+		Token:         token,
+	})
+}
+
+/*
+Désactiver la 2FA (code de l'application ou code de secours requis)
+Refusé tant qu'une entreprise du compte impose la double authentification
+*/
+func TwoFactorDisableHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.TwoFactorCodeRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if !validateTwoFactorCodeRequest(w, r, request.Code, request.RecoveryCode) {
+		return
+	}
+
+	var required bool
+	err := database.DB.QueryRowContext(r.Context(), "SELECT EXISTS(SELECT 1 FROM businesses WHERE UserId = $1 AND require_two_factor)", claims.UserID).Scan(&required)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorDisableHandler()", err)
+		return
+	}
+	if required {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "2fa.required_by_business")
+		return
+	}
+
+	if !checkSecondFactor(w, r, claims.UserID, request.Code, request.RecoveryCode) {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorDisableHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(r.Context(),
+		"UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW() WHERE id = $1", claims.UserID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorDisableHandler()", err)
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), "DELETE FROM recovery_codes WHERE UserId = $1", claims.UserID); err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorDisableHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorDisableHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Double authentification désactivée", "user_id", claims.UserID)
+	utils.WriteJSON(w, http.StatusOK, models.MessageResponse{Message: utils.T(r, "2fa.disabled")})
+}
+
+// Générer de nouveaux codes de secours (les anciens sont invalidés), sur présentation d'un code valide
+func TwoFactorRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.TwoFactorCodeRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if !validateTwoFactorCodeRequest(w, r, request.Code, request.RecoveryCode) {
+		return
+	}
+	if !checkSecondFactor(w, r, claims.UserID, request.Code, request.RecoveryCode) {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorRecoveryCodesHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(r.Context(), tx, claims.UserID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorRecoveryCodesHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorRecoveryCodesHandler()", err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.RecoveryCodesResponse{
+		Message:       utils.T(r, "2fa.recovery_codes_regenerated"),
+		RecoveryCodes: codes,
+	})
+}
+
+/*
+Seconde étape de la connexion : token de challenge (POST /auth/login) + code de l'application ou code de secours
+Les échecs comptent dans le blocage des connexions du compte et de l'adresse IP
+*/
+func TwoFactorVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.TwoFactorVerifyRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	if request.ChallengeToken == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.token_required",
+			models.FieldError{Field: "challenge_token", Code: "required", Message: "auth.token_required"})
+		return
+	}
+	if !validateTwoFactorCodeRequest(w, r, request.Code, request.RecoveryCode) {
+		return
+	}
+
+	claims, err := utils.ValidateChallengeToken(request.ChallengeToken)
+	if err != nil {
+		utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidToken, "2fa.challenge_invalid")
+		return
+	}
+
+	accountKey := accountThrottleKey(claims.Email)
+	ipKey := ipThrottleKey(utils.ClientIP(r))
+	remaining, err := loginLockRemaining(r.Context(), accountKey, ipKey)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorVerifyHandler()", err)
+		return
+	}
+	if remaining > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		utils.WriteError(w, r, http.StatusTooManyRequests, models.ErrCodeTooManyAttempts, "auth.too_many_attempts")
+		return
+	}
+
+	ok, err := verifySecondFactor(r.Context(), claims.UserID, request.Code, request.RecoveryCode)
+	if errors.Is(err, errTwoFactorNotEnabled) {
+		utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidToken, "2fa.challenge_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorVerifyHandler()", err)
+		return
+	}
+	if !ok {
+		if _, err := recordLoginFailure(r.Context(), accountKey, ipKey); err != nil {
+			slog.ErrorContext(r.Context(), "Échec de connexion non enregistré", "error", err)
+		}
+		utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidOTP, "2fa.code_invalid")
+		return
+	}
+
+	if err := resetLoginFailures(r.Context(), accountKey); err != nil {
+		slog.WarnContext(r.Context(), "Compteur d'échecs de connexion non remis à zéro", "error", err)
+	}
+	if err := recordSuccessfulLogin(r.Context(), claims.UserID); err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorVerifyHandler()", err)
+		return
+	}
+
+	var user models.User
+	err = database.DB.QueryRowContext(r.Context(), "SELECT id, email, email_verified_at, created_at, updated_at FROM users WHERE id = $1", claims.UserID).
+		Scan(&user.ID, &user.Email, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorVerifyHandler()", err)
+		return
+	}
+	user.TwoFactorEnabled = true
+
+	token, err := utils.GenerateToken(user.ID, user.Email, utils.AMRPassword, utils.AMROTP)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> TwoFactorVerifyHandler()", err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.AuthResponse{Token: token, User: user})
+}
+
+/*
+Exiger (ou non) la double authentification pour gérer une entreprise (propriétaire uniquement)
+Sans comptes employés, seul le propriétaire est concerné : ses sessions ouvertes sans code n'accèdent plus à l'entreprise
+Pour l'exiger, il doit lui-même être connecté avec la double authentification
+*/
+func BusinessTwoFactorPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	businessID := r.PathValue("id")
+
+	var request models.BusinessTwoFactorPolicyRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if request.RequireTwoFactor == nil {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "2fa.policy_required",
+			models.FieldError{Field: "require_two_factor", Code: "required", Message: "2fa.policy_required"})
+		return
+	}
+
+	var ownerID uuid.UUID
+	err := database.DB.QueryRowContext(r.Context(), "SELECT UserId FROM businesses WHERE id = $1", businessID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteDBError(w, r, "twoFactorHandlers.go -> BusinessTwoFactorPolicyHandler()", err)
+		return
+	}
+	if ownerID != claims.UserID {
+		utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "business.owner_only")
+		return
+	}
+
+	if *request.RequireTwoFactor && !claims.HasOTP() {
+		utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeTwoFactorRequired, "2fa.enable_first")
+		return
+	}
+
+	_, err = database.DB.ExecContext(r.Context(), "UPDATE businesses SET require_two_factor = $2, updated_at = NOW() WHERE id = $1", businessID, *request.RequireTwoFactor)
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> BusinessTwoFactorPolicyHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Exigence de double authentification modifiée", "business_id", businessID, "require_two_factor", *request.RequireTwoFactor)
+	utils.WriteJSON(w, http.StatusOK, models.BusinessTwoFactorPolicyResponse{
+		Message:          utils.T(r, "2fa.policy_updated"),
+		RequireTwoFactor: *request.RequireTwoFactor,
+	})
+}
+
+var errTwoFactorNotEnabled = errors.New("[twoFactorHandlers.go] -> Double authentification non activée")
+
+// Code de l'application ou code de secours requis
+func validateTwoFactorCodeRequest(w http.ResponseWriter, r *http.Request, code, recoveryCode string) bool {
+	if code == "" && recoveryCode == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "2fa.code_required",
+			models.FieldError{Field: "code", Code: "required", Message: "2fa.code_required"})
+		return false
+	}
+	return true
+}
+
+// Vérifier le second facteur d'un utilisateur connecté (désactivation, nouveaux codes de secours)
+func checkSecondFactor(w http.ResponseWriter, r *http.Request, userID uuid.UUID, code, recoveryCode string) bool {
+	ok, err := verifySecondFactor(r.Context(), userID, code, recoveryCode)
+	if errors.Is(err, errTwoFactorNotEnabled) {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "2fa.not_enabled")
+		return false
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "twoFactorHandlers.go -> checkSecondFactor()", err)
+		return false
+	}
+	if !ok {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeInvalidOTP, "2fa.code_invalid",
+			models.FieldError{Field: "code", Code: "invalid", Message: "2fa.code_invalid"})
+		return false
+	}
+	return true
+}
+
+/*
+Vérifier un code TOTP ou consommer un code de secours
+- Un code TOTP déjà utilisé (même pas de temps ou antérieur) est refusé : totp_last_step n'avance que par une mise à jour atomique
+- Un code de secours n'est utilisable qu'une fois
+Retourne errTwoFactorNotEnabled si la 2FA n'est pas active sur le compte
+*/
+func verifySecondFactor(ctx context.Context, userID uuid.UUID, code, recoveryCode string) (bool, error) {
+	var encrypted sql.NullString
+	var enabled bool
+	err := database.DB.QueryRowContext(ctx, "SELECT totp_secret, totp_enabled_at IS NOT NULL FROM users WHERE id = $1", userID).Scan(&encrypted, &enabled)
+	if err == sql.ErrNoRows || (err == nil && (!enabled || !encrypted.Valid)) {
+		return false, errTwoFactorNotEnabled
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if code != "" {
+		secret, err := utils.DecryptSecret(encrypted.String)
+		if err != nil {
+			return false, err
+		}
+		step, ok := utils.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		result, err := database.DB.ExecContext(ctx,
+			"UPDATE users SET totp_last_step = $2 WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)", userID, step)
+		if err != nil {
+			return false, err
+		}
+		rows, err := result.RowsAffected()
+		return rows == 1, err
+	}
+
+	result, err := database.DB.ExecContext(ctx,
+		"UPDATE recovery_codes SET used_at = NOW() WHERE UserId = $1 AND code_hash = $2 AND used_at IS NULL",
+		userID, utils.HashOpaqueToken(utils.NormalizeRecoveryCode(recoveryCode)))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if rows == 1 {
+		slog.WarnContext(ctx, "Connexion avec un code de secours", "user_id", userID)
+	}
+	return rows == 1, err
+}
+
+// Remplacer les codes de secours de l'utilisateur, retourne les nouveaux codes en clair
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashOpaqueToken(utils.NormalizeRecoveryCode(code))
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE UserId = $1", userID); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO recovery_codes (UserId, code_hash) SELECT $1, unnest($2::text[])", userID, pq.Array(hashes))
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Un objet plus récent n'est jamais considéré comme orphelin (envoi en cours, transaction pas encore validée)
+const orphanMinAge = time.Hour
+
+// Limites des images envoyées (STORAGE_*)
+type imageLimits struct {
+	field    string
+	maxBytes int64
+	maxSize  int
+}
+
+var (
+	avatarLimits = imageLimits{field: "avatar"}
+	logoLimits   = imageLimits{field: "logo"}
+)
+
+func InitUploads(cfg *config.Config) {
+	avatarLimits.maxBytes, avatarLimits.maxSize = cfg.Storage.AvatarMaxBytes, cfg.Storage.AvatarSize
+	logoLimits.maxBytes, logoLimits.maxSize = cfg.Storage.LogoMaxBytes, cfg.Storage.LogoSize
+}
+
+/*
+Photo de profil (formulaire multipart, fichier "avatar")
+L'image est vérifiée puis réduite et réencodée (cf. storage.ProcessImage), l'ancienne photo est supprimée du stockage
+*/
+func UploadAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	img, ok := readImageUpload(w, r, avatarLimits)
+	if !ok {
+		return
+	}
+
+	key, err := storeImage(r.Context(), storage.UserPrefix(claims.UserID), img)
+	if err != nil {
+		utils.WriteInternalError(w, r, "uploadHandlers.go -> UploadAvatarHandler()", err)
+		return
+	}
+
+	var previous sql.NullString
+	err = database.DB.QueryRowContext(r.Context(), `
+		UPDATE users SET profile_picture = $2, updated_at = NOW()
+		FROM (SELECT profile_picture FROM users WHERE id = $1 FOR UPDATE) AS previous
+		WHERE users.id = $1 AND users.deleted_at IS NULL
+		RETURNING previous.profile_picture`,
+		claims.UserID, key).Scan(&previous)
+	if err != nil {
+		removeStoredObject(r.Context(), key)
+		if err == sql.ErrNoRows {
+			utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+			return
+		}
+		utils.WriteInternalError(w, r, "uploadHandlers.go -> UploadAvatarHandler()", err)
+		return
+	}
+	removeStoredObject(r.Context(), previous.String)
+
+	ProfileHandler(w, r)
+}
+
+// Supprimer la photo de profil (une photo Google est simplement oubliée)
+func DeleteAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var previous sql.NullString
+	err := database.DB.QueryRowContext(r.Context(), `
+		UPDATE users SET profile_picture = NULL, updated_at = NOW()
+		FROM (SELECT profile_picture FROM users WHERE id = $1 FOR UPDATE) AS previous
+		WHERE users.id = $1 AND users.deleted_at IS NULL
+		RETURNING previous.profile_picture`, claims.UserID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "uploadHandlers.go -> DeleteAvatarHandler()", err)
+		return
+	}
+	removeStoredObject(r.Context(), previous.String)
+
+	utils.WriteJSON(w, http.StatusOK, models.MessageResponse{Message: utils.T(r, "user.avatar_deleted")})
+}
+
+// Logo de l'entreprise (formulaire multipart, fichier "logo"), réservé à la personne propriétaire
+func UploadBusinessLogoHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "uploadHandlers.go -> UploadBusinessLogoHandler()")
+	if !ok {
+		return
+	}
+
+	img, ok := readImageUpload(w, r, logoLimits)
+	if !ok {
+		return
+	}
+
+	key, err := storeImage(r.Context(), storage.BusinessPrefix(businessID), img)
+	if err != nil {
+		utils.WriteInternalError(w, r, "uploadHandlers.go -> UploadBusinessLogoHandler()", err)
+		return
+	}
+
+	var previous sql.NullString
+	err = database.DB.QueryRowContext(r.Context(), `
+		UPDATE businesses SET logo = $2, updated_at = NOW()
+		FROM (SELECT logo FROM businesses WHERE id = $1 FOR UPDATE) AS previous
+		WHERE businesses.id = $1
+		RETURNING previous.logo`,
+		businessID, key).Scan(&previous)
+	if err != nil {
+		removeStoredObject(r.Context(), key)
+		utils.WriteDBError(w, r, "uploadHandlers.go -> UploadBusinessLogoHandler()", err)
+		return
+	}
+	removeStoredObject(r.Context(), previous.String)
+
+	slog.InfoContext(r.Context(), "Logo de l'entreprise modifié", "business_id", businessID)
+	utils.WriteJSON(w, http.StatusOK, models.BusinessLogoResponse{
+		Message: utils.T(r, "business.logo_updated"),
+		LogoURL: storage.PublicURL(r.Context(), key),
+	})
+}
+
+func DeleteBusinessLogoHandler(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := requireBusinessOwner(w, r, "uploadHandlers.go -> DeleteBusinessLogoHandler()")
+	if !ok {
+		return
+	}
+
+	var previous sql.NullString
+	err := database.DB.QueryRowContext(r.Context(), `
+		UPDATE businesses SET logo = NULL, updated_at = NOW()
+		FROM (SELECT logo FROM businesses WHERE id = $1 FOR UPDATE) AS previous
+		WHERE businesses.id = $1
+		RETURNING previous.logo`, businessID).Scan(&previous)
+	if err != nil {
+		utils.WriteDBError(w, r, "uploadHandlers.go -> DeleteBusinessLogoHandler()", err)
+		return
+	}
+	removeStoredObject(r.Context(), previous.String)
+
+	utils.WriteJSON(w, http.StatusOK, models.MessageResponse{Message: utils.T(r, "business.logo_deleted")})
+}
+
+/*
+Fichiers du stockage local (GET /uploads/{clé}), liens signés générés par storage.PublicURL
+nil avec le stockage S3 : les liens pointent directement vers le bucket
+*/
+func UploadsHandler() http.HandlerFunc {
+	return storage.LocalHandler(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "error.not_found")
+	})
+}
+
+/*
+Tâche de fond : supprimer les objets du stockage qui ne sont plus référencés en base
+(envoi interrompu, suppression best effort échouée, entité supprimée en cascade...)
+*/
+func CleanupOrphanUploads(ctx context.Context) error {
+	removed := 0
+	for _, target := range []struct{ prefix, query string }{
+		{storage.UsersDir(), "SELECT profile_picture FROM users WHERE profile_picture = ANY($1)"},
+		{storage.BusinessesDir(), "SELECT logo FROM businesses WHERE logo = ANY($1)"},
+	} {
+		objects, err := storage.List(ctx, target.prefix)
+		if err != nil {
+			return fmt.Errorf("[uploadHandlers.go -> CleanupOrphanUploads()] -> %w", err)
+		}
+
+		var candidates []string
+		for _, object := range objects {
+			if time.Since(object.LastModified) > orphanMinAge {
+				candidates = append(candidates, object.Key)
+			}
+		}
+
+		// Par lots : la liste des clés est envoyée en un seul paramètre
+		for start := 0; start < len(candidates); start += 500 {
+			batch := candidates[start:min(start+500, len(candidates))]
+			referenced, err := referencedKeys(ctx, target.query, batch)
+			if err != nil {
+				return fmt.Errorf("[uploadHandlers.go -> CleanupOrphanUploads()] -> %w", err)
+			}
+			for _, key := range batch {
+				if referenced[key] {
+					continue
+				}
+				if err := storage.Delete(ctx, key); err != nil {
+					slog.WarnContext(ctx, "Fichier orphelin non supprimé", "key", key, "error", err)
+					continue
+				}
+				removed++
+			}
+		}
+	}
+
+	if removed > 0 {
+		slog.InfoContext(ctx, "Fichiers orphelins supprimés", "count", removed)
+	}
+	return nil
+}
+
+func referencedKeys(ctx context.Context, query string, keys []string) (map[string]bool, error) {
+	rows, err := database.DB.QueryContext(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	referenced := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		referenced[key] = true
+	}
+	return referenced, rows.Err()
+}
+
+/*
+Lire et valider l'image d'un formulaire multipart
+Taille vérifiée avant la lecture, puis format et dimensions sur le contenu (erreurs 422 sur le champ du fichier)
+*/
+func readImageUpload(w http.ResponseWriter, r *http.Request, limits imageLimits) (*storage.ProcessedImage, bool) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		utils.WriteBodyError(w, r, err)
+		return nil, false
+	}
+
+	file, header, err := r.FormFile(limits.field)
+	if err != nil {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed",
+			models.FieldError{Field: limits.field, Code: "required", Message: "upload.file_required", Args: []any{limits.field}})
+		return nil, false
+	}
+	defer file.Close()
+
+	tooLarge := models.FieldError{Field: limits.field, Code: "too_large", Message: "upload.too_large", Args: []any{limits.maxBytes >> 10}}
+	if header.Size > limits.maxBytes {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "upload.too_large", tooLarge)
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, limits.maxBytes+1))
+	if err != nil {
+		utils.WriteInternalError(w, r, "uploadHandlers.go -> readImageUpload()", err)
+		return nil, false
+	}
+	if int64(len(data)) > limits.maxBytes {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "upload.too_large", tooLarge)
+		return nil, false
+	}
+
+	img, err := storage.ProcessImage(data, limits.maxSize)
+	switch {
+	case errors.Is(err, storage.ErrImageType):
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "upload.type_invalid",
+			models.FieldError{Field: limits.field, Code: "invalid_format", Message: "upload.type_invalid"})
+		return nil, false
+	case errors.Is(err, storage.ErrImageDimensions):
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "upload.dimensions",
+			models.FieldError{Field: limits.field, Code: "invalid_dimensions", Message: "upload.dimensions", Args: []any{storage.MaxImageDimension, storage.MaxImageDimension}})
+		return nil, false
+	case err != nil:
+		utils.WriteInternalError(w, r, "uploadHandlers.go -> readImageUpload()", err)
+		return nil, false
+	}
+	return img, true
+}
+
+// Enregistrer une image sous un nom aléatoire : une nouvelle image a un nouveau lien (pas de cache périmé)
+func storeImage(ctx context.Context, prefix string, img *storage.ProcessedImage) (string, error) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return "", fmt.Errorf("[uploadHandlers.go -> storeImage()] -> %w", err)
+	}
+	key := prefix + hex.EncodeToString(name) + img.Extension
+
+	if err := storage.Put(ctx, key, img.Data, img.ContentType); err != nil {
+		return "", fmt.Errorf("[uploadHandlers.go -> storeImage()] -> %w", err)
+	}
+	return key, nil
+}
+
+// Supprimer un objet remplacé (best effort : un échec laisse un orphelin, supprimé par `storage_cleanup`)
+func removeStoredObject(ctx context.Context, ref string) {
+	if !storage.IsKey(ref) {
+		return
+	}
+	if err := storage.Delete(ctx, ref); err != nil {
+		slog.WarnContext(ctx, "Fichier non supprimé", "key", ref, "error", err)
+	}
+}
+
+// Supprimer tous les objets d'une entité supprimée (best effort, cf. removeStoredObject)
+func removeStoredPrefix(ctx context.Context, prefix string) {
+	if err := storage.DeletePrefix(ctx, prefix); err != nil {
+		slog.WarnContext(ctx, "Fichiers non supprimés", "prefix", prefix, "error", err)
+	}
+}
+
+/*
+Entreprise de l'URL ({id}) dont l'utilisateur connecté est propriétaire
+404 si elle n'existe pas ou a été supprimée, 403 pour un autre compte
+*/
+func requireBusinessOwner(w http.ResponseWriter, r *http.Request, where string) (uuid.UUID, bool) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var businessID, ownerID uuid.UUID
+	var archived bool
+	err := database.DB.QueryRowContext(r.Context(), "SELECT id, UserId, deleted_at IS NOT NULL FROM businesses WHERE id = $1", r.PathValue("id")).
+		Scan(&businessID, &ownerID, &archived)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.not_found")
+		return uuid.Nil, false
+	}
+	if err != nil {
+		utils.WriteDBError(w, r, where, err)
+		return uuid.Nil, false
+	}
+	if ownerID != claims.UserID {
+		utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "business.owner_only")
+		return uuid.Nil, false
+	}
+	// Entreprise supprimée : seule la restauration est possible
+	if archived {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "business.archived")
+		return uuid.Nil, false
+	}
+	return businessID, true
+}
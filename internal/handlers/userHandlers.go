@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/mailer"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	accountDeletionGrace time.Duration
+	reauthenticationTTL  time.Duration
+)
+
+func InitUsers(cfg *config.Config) {
+	accountDeletionGrace = cfg.Auth.AccountDeletionGrace
+	reauthenticationTTL = cfg.Auth.ReauthenticationTTL
+}
+
+// Profil de l'utilisateur connecté (GET /users/me, ancienne route GET /user/profile)
+func ProfileHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	user, err := fetchProfile(r.Context(), claims.UserID)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ProfileHandler()", err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+/*
+Modifier le profil (prénom, nom, téléphone)
+Seuls les champs présents sont modifiés, une chaîne vide efface la valeur
+*/
+func UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.UpdateProfileRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	var details []models.FieldError
+	var sets []string
+	args := []any{claims.UserID}
+	set := func(column string, value string) {
+		args = append(args, nullIfEmpty(value))
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if request.FirstName != nil {
+		firstName := strings.TrimSpace(*request.FirstName)
+		if len([]rune(firstName)) > models.UserNameMaxLength {
+			details = append(details, models.FieldError{Field: "first_name", Code: "invalid_length", Message: "user.first_name_length", Args: []any{models.UserNameMaxLength}})
+		}
+		set("first_name", firstName)
+	}
+	if request.LastName != nil {
+		lastName := strings.TrimSpace(*request.LastName)
+		if len([]rune(lastName)) > models.UserNameMaxLength {
+			details = append(details, models.FieldError{Field: "last_name", Code: "invalid_length", Message: "user.last_name_length", Args: []any{models.UserNameMaxLength}})
+		}
+		set("last_name", lastName)
+	}
+	if request.PhoneNumber != nil {
+		phoneNumber := strings.NewReplacer(" ", "", ".", "", "-", "").Replace(*request.PhoneNumber)
+		if phoneNumber != "" && models.ValidateUserPhoneNumber(phoneNumber) != nil {
+			details = append(details, models.FieldError{Field: "phone", Code: "invalid_format", Message: "user.phone_invalid"})
+		}
+		set("phone_number", phoneNumber)
+	}
+
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
+		return
+	}
+
+	if len(sets) > 0 {
+		query := "UPDATE users SET " + strings.Join(sets, ", ") + ", updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+		if _, err := database.DB.ExecContext(r.Context(), query, args...); err != nil {
+			utils.WriteDBError(w, r, "userHandlers.go -> UpdateProfileHandler()", err)
+			return
+		}
+	}
+
+	ProfileHandler(w, r)
+}
+
+/*
+Changer de mot de passe
+Le mot de passe actuel est requis (un compte créé avec Google qui n'en a pas encore doit s'être connecté récemment)
+Les liens de réinitialisation en cours et les tokens de session déjà émis sont invalidés, un nouveau token est retourné
+L'utilisateur est prévenu par email
+*/
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.ChangePasswordRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	if request.NewPassword == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.password_required",
+			models.FieldError{Field: "new_password", Code: "required", Message: "auth.password_required"})
+		return
+	}
+	if err := utils.ValidatePassword(request.NewPassword); err != nil {
+		detail := utils.PasswordFieldError(err)
+		detail.Field = "new_password"
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.password_invalid", detail)
+		return
+	}
+
+	if !checkCurrentPassword(w, r, claims, request.CurrentPassword, "current_password") {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ChangePasswordHandler()", err)
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ChangePasswordHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	// À la seconde près, comme le claim "iat" : le nouveau token, émis juste après, reste valable
+	changedAt := time.Now().Truncate(time.Second)
+
+	var email string
+	err = tx.QueryRowContext(r.Context(), "UPDATE users SET password = $2, password_changed_at = $3, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING email",
+		claims.UserID, hashedPassword, changedAt).Scan(&email)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ChangePasswordHandler()", err)
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), "DELETE FROM auth_tokens WHERE UserId = $1 AND purpose = $2", claims.UserID, models.TokenPurposePasswordReset)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ChangePasswordHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ChangePasswordHandler()", err)
+		return
+	}
+
+	// Même méthode d'authentification que la session en cours (double authentification comprise)
+	token, err := utils.GenerateToken(claims.UserID, email, claims.AMR...)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> ChangePasswordHandler()", err)
+		return
+	}
+
+	mailer.SendAsync(r.Context(), mailer.Message{
+		To:      email,
+		Subject: utils.T(r, "mail.password_changed.subject"),
+		Text:    utils.T(r, "mail.password_changed.body", frontendURL+forgotPasswordPage),
+		Type:    "password_changed",
+	})
+
+	slog.InfoContext(r.Context(), "Mot de passe modifié", "user_id", claims.UserID)
+	utils.WriteJSON(w, http.StatusOK, models.ChangePasswordResponse{Message: utils.T(r, "user.password_changed"), Token: token})
+}
+
+/*
+Demander la suppression du compte
+Le compte et ses entreprises sont désactivés (files fermées, clients en attente annulés) puis supprimés définitivement
+après le délai de grâce par la tâche de fond `account_purge`. Se reconnecter avant annule la suppression.
+*/
+func DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	claims := utils.ClaimsFromContext(r.Context())
+
+	var request models.DeleteAccountRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+	if !checkCurrentPassword(w, r, claims, request.Password, "password") {
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> DeleteAccountHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var email string
+	var deletedAt time.Time
+	err = tx.QueryRowContext(r.Context(), "UPDATE users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING email, deleted_at", claims.UserID).
+		Scan(&email, &deletedAt)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> DeleteAccountHandler()", err)
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), `
+		UPDATE businesses SET deleted_at = NOW(), is_active = false, is_queue_active = false, updated_at = NOW()
+		WHERE UserId = $1 AND deleted_at IS NULL`, claims.UserID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> DeleteAccountHandler()", err)
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), `
+		UPDATE queue_entries SET status = 'cancelled', updated_at = NOW()
+		WHERE status IN ('waiting', 'called') AND BusinessId IN (SELECT id FROM businesses WHERE UserId = $1)`, claims.UserID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> DeleteAccountHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> DeleteAccountHandler()", err)
+		return
+	}
+
+	mailer.SendAsync(r.Context(), mailer.Message{
+		To:      email,
+		Subject: utils.T(r, "mail.account_deletion.subject"),
+		Text:    utils.T(r, "mail.account_deletion.body", formatDuration(r, accountDeletionGrace), frontendURL),
+		Type:    "account_deletion",
+	})
+
+	slog.InfoContext(r.Context(), "Suppression du compte demandée", "user_id", claims.UserID)
+	utils.WriteJSON(w, http.StatusAccepted, models.DeleteAccountResponse{
+		Message:    utils.T(r, "user.deletion_scheduled", formatDuration(r, accountDeletionGrace)),
+		DeletionAt: deletedAt.Add(accountDeletionGrace),
+	})
+}
+
+/*
+Connexion réussie (mot de passe + code de double authentification le cas échéant, ou Google)
+Met à jour last_login et annule une suppression du compte en cours : les entreprises désactivées avec le compte sont restaurées
+*/
+func recordSuccessfulLogin(ctx context.Context, userID uuid.UUID) error {
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[userHandlers.go -> recordSuccessfulLogin()] -> %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users SET last_login = NOW(), deleted_at = NULL
+		FROM (SELECT deleted_at FROM users WHERE id = $1 FOR UPDATE) AS previous
+		WHERE users.id = $1
+		RETURNING previous.deleted_at`, userID).Scan(&deletedAt)
+	if err != nil {
+		return fmt.Errorf("[userHandlers.go -> recordSuccessfulLogin()] -> %w", err)
+	}
+
+	if deletedAt.Valid {
+		_, err = tx.ExecContext(ctx, "UPDATE businesses SET deleted_at = NULL, is_active = true, updated_at = NOW() WHERE UserId = $1 AND deleted_at = $2", userID, deletedAt.Time)
+		if err != nil {
+			return fmt.Errorf("[userHandlers.go -> recordSuccessfulLogin()] -> %w", err)
+		}
+		slog.InfoContext(ctx, "Suppression du compte annulée", "user_id", userID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[userHandlers.go -> recordSuccessfulLogin()] -> %w", err)
+	}
+	return nil
+}
+
+/*
+Tâche de fond : supprimer définitivement les comptes dont le délai de grâce est écoulé
+Les entreprises, files et données associées suivent (ON DELETE CASCADE), ainsi que leurs fichiers (photo de profil, logos)
+L'activité des entreprises est archivée dans la même transaction (cf. archiveBusinessUsage)
+*/
+func PurgeDeletedAccounts(ctx context.Context) error {
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH purged AS (
+			SELECT id FROM users WHERE deleted_at < NOW() - make_interval(secs => $1) LIMIT 100 FOR UPDATE SKIP LOCKED
+		)
+		SELECT 'user', id FROM purged
+		UNION ALL
+		SELECT 'business', businesses.id FROM businesses JOIN purged ON businesses.UserId = purged.id`,
+		accountDeletionGrace.Seconds())
+	if err != nil {
+		return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs, businessIDs []string
+	var prefixes []string
+	for rows.Next() {
+		var kind string
+		var id uuid.UUID
+		if err := rows.Scan(&kind, &id); err != nil {
+			return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+		}
+		if kind == "user" {
+			userIDs = append(userIDs, id.String())
+			prefixes = append(prefixes, storage.UserPrefix(id))
+		} else {
+			businessIDs = append(businessIDs, id.String())
+			prefixes = append(prefixes, storage.BusinessPrefix(id))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+	}
+	rows.Close()
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	if len(businessIDs) > 0 {
+		if err := archiveBusinessUsage(ctx, tx, businessIDs); err != nil {
+			return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = ANY($1::uuid[])", pq.Array(userIDs)); err != nil {
+		return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[userHandlers.go -> PurgeDeletedAccounts()] -> %w", err)
+	}
+
+	for _, prefix := range prefixes {
+		removeStoredPrefix(ctx, prefix)
+	}
+
+	slog.InfoContext(ctx, "Comptes supprimés définitivement", "count", len(userIDs))
+	return nil
+}
+
+// Profil complet (les colonnes facultatives vides sont retournées en chaîne vide)
+func fetchProfile(ctx context.Context, userID uuid.UUID) (models.User, error) {
+	var user models.User
+	err := database.DB.QueryRowContext(ctx, `
+		SELECT id, COALESCE(google_id, ''), email, COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(phone_number, ''),
+			COALESCE(profile_picture, ''), COALESCE(auth_provider, ''), password IS NOT NULL, email_verified_at, totp_enabled_at IS NOT NULL,
+			last_login, created_at, updated_at
+		FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).Scan(
+		&user.ID,
+		&user.Google_id,
+		&user.Email,
+		&user.FirstName,
+		&user.LastName,
+		&user.PhoneNumber,
+		&user.ProfilePicture,
+		&user.AuthProvider,
+		&user.HasPassword,
+		&user.EmailVerifiedAt,
+		&user.TwoFactorEnabled,
+		&user.LastLogin,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	user.ProfilePicture = storage.PublicURL(ctx, user.ProfilePicture)
+	return user, err
+}
+
+/*
+Vérifier le mot de passe actuel avant une action sensible (changement de mot de passe, suppression du compte)
+Les erreurs comptent dans le blocage des connexions du compte, comme une connexion ratée
+Un compte sans mot de passe (Google) doit s'être connecté depuis moins de `reauthenticationTTL` : un token volé plus ancien ne suffit pas
+*/
+func checkCurrentPassword(w http.ResponseWriter, r *http.Request, claims *utils.Claims, password, field string) bool {
+	var hash sql.NullString
+	err := database.DB.QueryRowContext(r.Context(), "SELECT password FROM users WHERE id = $1 AND deleted_at IS NULL", claims.UserID).Scan(&hash)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "user.not_found")
+		return false
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> checkCurrentPassword()", err)
+		return false
+	}
+	if !hash.Valid {
+		if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > reauthenticationTTL {
+			utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeReauthRequired, "user.reauthentication_required")
+			return false
+		}
+		return true
+	}
+
+	if password == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "user.current_password_required",
+			models.FieldError{Field: field, Code: "required", Message: "user.current_password_required"})
+		return false
+	}
+
+	accountKey := accountThrottleKey(claims.Email)
+	ipKey := ipThrottleKey(utils.ClientIP(r))
+	remaining, err := loginLockRemaining(r.Context(), accountKey, ipKey)
+	if err != nil {
+		utils.WriteInternalError(w, r, "userHandlers.go -> checkCurrentPassword()", err)
+		return false
+	}
+	if remaining > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		utils.WriteError(w, r, http.StatusTooManyRequests, models.ErrCodeTooManyAttempts, "auth.too_many_attempts")
+		return false
+	}
+
+	if !utils.CheckPasswordHash(password, hash.String) {
+		if _, err := recordLoginFailure(r.Context(), accountKey, ipKey); err != nil {
+			slog.ErrorContext(r.Context(), "Échec de connexion non enregistré", "error", err)
+		}
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeInvalidCredentials, "user.current_password_invalid",
+			models.FieldError{Field: field, Code: "invalid", Message: "user.current_password_invalid"})
+		return false
+	}
+	return true
+}
+
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
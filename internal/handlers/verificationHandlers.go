@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/mailer"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Pages du Front ouvertes par les liens envoyés par email (le token est passé en paramètre "token")
+const (
+	verifyEmailPage    = "/verify-email"
+	resetPasswordPage  = "/reset-password"
+	forgotPasswordPage = "/forgot-password"
+)
+
+var (
+	frontendURL          string
+	emailVerificationTTL time.Duration
+	passwordResetTTL     time.Duration
+)
+
+// Configuration des liens envoyés par email
+func InitAuthLinks(cfg *config.Config) {
+	frontendURL = strings.TrimSuffix(cfg.App.FrontendURL, "/")
+	emailVerificationTTL = cfg.Auth.EmailVerificationTTL
+	passwordResetTTL = cfg.Auth.PasswordResetTTL
+}
+
+/*
+Confirmer l'adresse email avec le token reçu par email
+Le token est à usage unique : un second appel avec le même lien répond 400
+*/
+func VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.token_required",
+			models.FieldError{Field: "token", Code: "required", Message: "auth.token_required"})
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> VerifyEmailHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeAuthToken(r.Context(), tx, token, models.TokenPurposeEmailVerification)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidLink, "auth.link_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> VerifyEmailHandler()", err)
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), "UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE id = $1", userID)
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> VerifyEmailHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> VerifyEmailHandler()", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Adresse email confirmée", "user_id", userID)
+	utils.WriteJSON(w, http.StatusOK, models.MessageResponse{Message: utils.T(r, "auth.email_verified")})
+}
+
+/*
+Renvoyer l'email de confirmation (le lien précédent n'est plus valable)
+Toujours 202 : la réponse ne révèle pas si le compte existe
+*/
+func ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.EmailRequest
+	if !utils.DecodeJSON(w, r, &request) || !validateEmailRequest(w, r, request) {
+		return
+	}
+
+	var userID uuid.UUID
+	err := database.DB.QueryRowContext(r.Context(), "SELECT id FROM users WHERE email = $1 AND email_verified_at IS NULL", request.Email).Scan(&userID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResendVerificationHandler()", err)
+		return
+	}
+	if err == nil {
+		if err := sendVerificationEmail(r, userID, request.Email); err != nil {
+			utils.WriteInternalError(w, r, "verificationHandlers.go -> ResendVerificationHandler()", err)
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, models.MessageResponse{Message: utils.T(r, "auth.verification_sent")})
+}
+
+/*
+Mot de passe oublié : envoyer un lien de réinitialisation
+Toujours 202 : la réponse ne révèle pas si le compte existe (l'email part en arrière-plan)
+*/
+func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.EmailRequest
+	if !utils.DecodeJSON(w, r, &request) || !validateEmailRequest(w, r, request) {
+		return
+	}
+
+	var userID uuid.UUID
+	err := database.DB.QueryRowContext(r.Context(), "SELECT id FROM users WHERE email = $1 AND is_active IS NOT FALSE", request.Email).Scan(&userID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ForgotPasswordHandler()", err)
+		return
+	}
+	if err == nil {
+		token, err := issueAuthToken(r.Context(), userID, models.TokenPurposePasswordReset, passwordResetTTL)
+		if err != nil {
+			utils.WriteInternalError(w, r, "verificationHandlers.go -> ForgotPasswordHandler()", err)
+			return
+		}
+
+		mailer.SendAsync(r.Context(), mailer.Message{
+			To:      request.Email,
+			Subject: utils.T(r, "mail.password_reset.subject"),
+			Text:    utils.T(r, "mail.password_reset.body", authLink(resetPasswordPage, token), formatDuration(r, passwordResetTTL)),
+			Type:    models.TokenPurposePasswordReset,
+		})
+		slog.InfoContext(r.Context(), "Lien de réinitialisation du mot de passe envoyé", "user_id", userID)
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, models.MessageResponse{Message: utils.T(r, "auth.password_reset_sent")})
+}
+
+/*
+Choisir un nouveau mot de passe avec le token reçu par email
+- Le token est consommé dans la même transaction que la mise à jour du mot de passe
+- Les autres liens de réinitialisation en attente sont invalidés
+- L'email est considéré comme confirmé (le lien a été ouvert depuis la boîte mail) et le blocage des connexions est levé
+*/
+func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.ResetPasswordRequest
+	if !utils.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	var details []models.FieldError
+	if request.Token == "" {
+		details = append(details, models.FieldError{Field: "token", Code: "required", Message: "auth.token_required"})
+	}
+	if request.Password == "" {
+		details = append(details, models.FieldError{Field: "password", Code: "required", Message: "auth.password_required"})
+	} else if err := utils.ValidatePassword(request.Password); err != nil {
+		details = append(details, utils.PasswordFieldError(err))
+	}
+	if len(details) > 0 {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "error.validation_failed", details...)
+		return
+	}
+
+	// Hash calculé avant la transaction (bcrypt est volontairement lent)
+	hashedPassword, err := utils.HashPassword(request.Password)
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResetPasswordHandler()", err)
+		return
+	}
+
+	tx, err := database.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResetPasswordHandler()", err)
+		return
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeAuthToken(r.Context(), tx, request.Token, models.TokenPurposePasswordReset)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidLink, "auth.link_invalid")
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResetPasswordHandler()", err)
+		return
+	}
+
+	// Les tokens de session émis avant sont refusés (AuthMiddleware)
+	var email string
+	err = tx.QueryRowContext(r.Context(),
+		"UPDATE users SET password = $1, password_changed_at = $3, email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE id = $2 RETURNING email",
+		hashedPassword, userID, time.Now().Truncate(time.Second)).Scan(&email)
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResetPasswordHandler()", err)
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), "DELETE FROM auth_tokens WHERE UserId = $1 AND purpose = $2 AND used_at IS NULL", userID, models.TokenPurposePasswordReset)
+	if err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResetPasswordHandler()", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.WriteInternalError(w, r, "verificationHandlers.go -> ResetPasswordHandler()", err)
+		return
+	}
+
+	// Le propriétaire du compte a prouvé son identité : les connexions sont débloquées
+	if err := resetLoginFailures(r.Context(), accountThrottleKey(email)); err != nil {
+		slog.WarnContext(r.Context(), "Compteur d'échecs de connexion non remis à zéro", "error", err)
+	}
+
+	slog.InfoContext(r.Context(), "Mot de passe réinitialisé", "user_id", userID)
+	utils.WriteJSON(w, http.StatusOK, models.MessageResponse{Message: utils.T(r, "auth.password_reset_done")})
+}
+
+// Email requis et au bon format (422 sinon)
+func validateEmailRequest(w http.ResponseWriter, r *http.Request, request models.EmailRequest) bool {
+	if request.Email == "" {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.email_required",
+			models.FieldError{Field: "email", Code: "required", Message: "auth.email_required"})
+		return false
+	}
+
+	registerRequest := models.RegisterRequest{Email: request.Email}
+	if err := registerRequest.Validate(); err != nil {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidation, "auth.email_invalid",
+			models.FieldError{Field: "email", Code: "invalid_format", Message: "auth.email_invalid"})
+		return false
+	}
+	return true
+}
+
+// Créer un token de vérification et envoyer l'email de confirmation (en arrière-plan)
+func sendVerificationEmail(r *http.Request, userID uuid.UUID, email string) error {
+	token, err := issueAuthToken(r.Context(), userID, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	mailer.SendAsync(r.Context(), mailer.Message{
+		To:      email,
+		Subject: utils.T(r, "mail.verification.subject"),
+		Text:    utils.T(r, "mail.verification.body", authLink(verifyEmailPage, token), formatDuration(r, emailVerificationTTL)),
+		Type:    models.TokenPurposeEmailVerification,
+	})
+	return nil
+}
+
+/*
+Créer un token à usage unique pour un utilisateur
+Seul le dernier lien envoyé reste valable : les tokens précédents du même usage (et les tokens expirés) sont supprimés
+*/
+func issueAuthToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("[verificationHandlers.go -> issueAuthToken()] -> %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM auth_tokens WHERE UserId = $1 AND (purpose = $2 OR expires_at < NOW())", userID, purpose)
+	if err != nil {
+		return "", fmt.Errorf("[verificationHandlers.go -> issueAuthToken()] -> Suppression des anciens tokens : %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO auth_tokens (UserId, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		userID, purpose, hash, time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("[verificationHandlers.go -> issueAuthToken()] -> Insertion du token : %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("[verificationHandlers.go -> issueAuthToken()] -> %w", err)
+	}
+	return token, nil
+}
+
+/*
+Consommer un token : valide, non expiré, jamais utilisé et pour le bon usage
+La mise à jour est atomique (deux requêtes simultanées ne peuvent pas utiliser le même lien)
+sql.ErrNoRows si le token est refusé
+*/
+func consumeAuthToken(ctx context.Context, tx *sql.Tx, token, purpose string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := tx.QueryRowContext(ctx,
+		"UPDATE auth_tokens SET used_at = NOW() WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW() RETURNING UserId",
+		utils.HashOpaqueToken(token), purpose,
+	).Scan(&userID)
+	return userID, err
+}
+
+// Lien vers une page du Front avec le token
+func authLink(page, token string) string {
+	return frontendURL + page + "?token=" + url.QueryEscape(token)
+}
+
+// Durée de validité lisible dans la langue de la requête ("48 heures", "30 minutes")
+func formatDuration(r *http.Request, d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return utils.T(r, "duration.days", int(d.Hours()/24))
+	case d == time.Hour:
+		return utils.T(r, "duration.hour")
+	case d > time.Hour:
+		return utils.T(r, "duration.hours", int(d.Hours()))
+	default:
+		return utils.T(r, "duration.minutes", int(d.Minutes()))
+	}
+}
@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Langue par défaut de l'API (messages, SMS, pages client)
+const DefaultLanguage = "fr"
+
+// Langues disponibles dans le catalogue, la première est la langue de repli
+var SupportedLanguages = []string{"fr", "en", "es"}
+
+var matcher = language.NewMatcher([]language.Tag{language.French, language.English, language.Spanish})
+
+type contextKey string
+
+const languageKey contextKey = "language"
+
+// Vérifier qu'une langue fait partie du catalogue
+func IsSupported(lang string) bool {
+	_, ok := catalog[lang]
+	return ok
+}
+
+// Choisir la langue à partir du header "Accept-Language"
+func Match(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return DefaultLanguage
+	}
+
+	_, index := language.MatchStrings(matcher, acceptLanguage)
+	return SupportedLanguages[index]
+}
+
+// Traduire un message du catalogue, avec repli sur le français puis sur la clé elle-même
+func T(lang, key string, args ...any) string {
+	message, ok := catalog[lang][key]
+	if !ok {
+		message, ok = catalog[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Ajouter la langue de la requête au contexte
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey, lang)
+}
+
+// Récupérer la langue de la requête depuis le contexte
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey).(string); ok && lang != "" {
+		return lang
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,62 @@
+package i18n
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "fr"},
+		{"fr-FR,fr;q=0.9", "fr"},
+		{"en", "en"},
+		{"en-US,en;q=0.9,fr;q=0.8", "en"},
+		{"es-MX", "es"},
+		{"de-DE,de;q=0.9", "fr"},    // aucune langue disponible : français
+		{"de-DE,en;q=0.5", "en"},    // première langue disponible
+		{"fr;q=0.2,es;q=0.8", "es"}, // poids respectés
+		{"*", "fr"},
+		{"n'importe quoi", "fr"},
+	}
+	for _, test := range tests {
+		if got := Match(test.acceptLanguage); got != test.want {
+			t.Errorf("Match(%q) = %q, attendu %q", test.acceptLanguage, got, test.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []any
+		want string
+	}{
+		{"traduction", "en", "user.password_changed", nil, "Password changed successfully."},
+		{"langue inconnue : français", "de", "user.password_changed", nil, "Mot de passe modifié avec succès."},
+		{"clé inconnue : la clé", "en", "cle.inconnue", nil, "cle.inconnue"},
+		{"paramètres", "fr", "user.last_name_length", []any{50}, "Le nom doit contenir au maximum 50 caractères."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := T(test.lang, test.key, test.args...); got != test.want {
+				t.Fatalf("%q, attendu %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Chaque message du catalogue français est traduit dans les autres langues
+func TestCatalogComplete(t *testing.T) {
+	for _, lang := range SupportedLanguages {
+		if !IsSupported(lang) {
+			t.Fatalf("langue %q absente du catalogue", lang)
+		}
+		for key := range catalog[DefaultLanguage] {
+			if _, ok := catalog[lang][key]; !ok {
+				t.Errorf("%s : message %q manquant", lang, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,722 @@
+package i18n
+
+// Catalogue des messages par langue puis par clé
+var catalog = map[string]map[string]string{
+	"fr": {
+		// Erreurs génériques
+		"error.method_not_allowed":   "Méthode HTTP non autorisée.",
+		"error.invalid_body":         "Corps de la requête invalide.",
+		"error.unknown_field":        "Champ inconnu.",
+		"error.payload_too_large":    "Le corps de la requête est trop volumineux.",
+		"error.validation_failed":    "Certains champs sont invalides.",
+		"error.internal":             "Une erreur interne est survenue.",
+		"error.not_found":            "Ressource introuvable.",
+		"error.route_not_found":      "Cette route n'existe pas.",
+		"error.already_exists":       "La ressource existe déjà.",
+		"error.value_taken":          "Cette valeur est déjà utilisée.",
+		"error.value_rejected":       "Valeur refusée.",
+		"error.constraint_violation": "Les données envoyées ne respectent pas les contraintes.",
+		"error.invalid_parameter":    "Un paramètre n'est pas au format attendu.",
+
+		// Listes paginées
+		"list.limit_invalid":  "Le nombre d'éléments par page doit être compris entre 1 et %d.",
+		"list.cursor_invalid": "Curseur de pagination invalide (il doit provenir de next_cursor, avec le même tri).",
+		"list.sort_invalid":   "Tri non pris en charge (%s, préfixé par - pour l'ordre décroissant).",
+		"list.filter_invalid": "Valeur de filtre non prise en charge (%s).",
+		"list.date_invalid":   "Date invalide (YYYY-MM-DD ou RFC 3339).",
+
+		// Requêtes rejouées (Idempotency-Key)
+		"idempotency.key_invalid": "L'en-tête Idempotency-Key doit faire 1 à %d caractères imprimables (ex : un UUID).",
+		"idempotency.key_reused":  "Cette Idempotency-Key a déjà été utilisée pour une autre requête.",
+		"idempotency.in_progress": "Une requête avec cette Idempotency-Key est encore en cours, réessayez dans un instant.",
+		"idempotency.processed":   "Cette requête a déjà été traitée, sa réponse n'est pas conservée. Utilisez une nouvelle Idempotency-Key.",
+
+		// Authentification
+		"auth.authorization_required": `Header d'autorisation "Authorization" requis.`,
+		"auth.invalid_token":          "Token invalide.",
+		"auth.invalid_credentials":    "Email ou mot de passe incorrect.",
+		"auth.credentials_required":   "L'email et le mot de passe sont requis.",
+		"auth.email_required":         "Email requis.",
+		"auth.email_invalid":          "Format de l'email invalide.",
+		"auth.email_taken":            "Cet email est déjà associé à un compte.",
+		"auth.password_required":      "Mot de passe requis.",
+		"auth.password_invalid":       "Format du mot de passe invalide.",
+		"auth.password_too_short":     "Le mot de passe doit contenir au moins %d caractères.",
+		"auth.password_too_long":      "Le mot de passe ne doit pas dépasser %d octets (les caractères accentués en comptent plusieurs).",
+		"auth.password_classes":       "Le mot de passe doit mélanger au moins %d types de caractères : minuscules, majuscules, chiffres, symboles.",
+		"auth.password_breached":      "Ce mot de passe apparaît dans des fuites de données connues, choisissez-en un autre.",
+		"auth.oauth_state_invalid":    "État OAuth invalide.",
+		"auth.oauth_code_invalid":     "Code d'autorisation Google invalide.",
+		"auth.link_invalid":           "Ce lien est invalide ou a expiré.",
+		"auth.token_required":         "Token requis.",
+		"auth.email_not_verified":     "Confirmez votre adresse email pour accéder à cette fonctionnalité.",
+		"auth.email_verified":         "Adresse email confirmée.",
+		"auth.verification_sent":      "Si un compte non confirmé existe pour cet email, un lien de confirmation vient d'être envoyé.",
+		"auth.password_reset_sent":    "Si un compte existe pour cet email, un lien de réinitialisation vient d'être envoyé.",
+		"auth.password_reset_done":    "Mot de passe modifié, vous pouvez vous connecter.",
+		"auth.too_many_attempts":      "Trop de tentatives de connexion échouées. Réessayez plus tard.",
+
+		// Emails
+		"mail.verification.subject":     "Confirmez votre adresse email",
+		"mail.verification.body":        "Bonjour,\n\nBienvenue sur Waitify ! Pour confirmer votre adresse email, ouvrez ce lien :\n\n%s\n\nCe lien expire dans %s. Si vous n'avez pas créé de compte, ignorez cet email.\n\nL'équipe Waitify",
+		"mail.password_reset.subject":   "Réinitialisation de votre mot de passe",
+		"mail.password_reset.body":      "Bonjour,\n\nUne réinitialisation du mot de passe a été demandée pour votre compte Waitify. Pour choisir un nouveau mot de passe, ouvrez ce lien :\n\n%s\n\nCe lien expire dans %s et ne peut être utilisé qu'une fois. Si vous n'êtes pas à l'origine de cette demande, ignorez cet email : votre mot de passe reste inchangé.\n\nL'équipe Waitify",
+		"mail.login_locked.subject":     "Connexions bloquées sur votre compte Waitify",
+		"mail.login_locked.body":        "Bonjour,\n\nPlusieurs tentatives de connexion à votre compte Waitify ont échoué. Par sécurité, les connexions sont bloquées pendant %s.\n\nSi c'était vous, patientez puis réessayez. Sinon, nous vous conseillons de changer votre mot de passe :\n\n%s\n\nL'équipe Waitify",
+		"mail.password_changed.subject": "Votre mot de passe Waitify a été modifié",
+		"mail.password_changed.body":    "Bonjour,\n\nLe mot de passe de votre compte Waitify vient d'être modifié.\n\nSi vous n'êtes pas à l'origine de ce changement, réinitialisez immédiatement votre mot de passe :\n\n%s\n\nL'équipe Waitify",
+		"mail.account_deletion.subject": "Suppression de votre compte Waitify",
+		"mail.account_deletion.body":    "Bonjour,\n\nLa suppression de votre compte Waitify a été demandée. Votre compte et vos entreprises sont désactivés et seront supprimés définitivement dans %s.\n\nPour annuler la suppression, il suffit de vous reconnecter avant cette date :\n\n%s\n\nL'équipe Waitify",
+
+		// Double authentification
+		"2fa.already_enabled":            "La double authentification est déjà activée.",
+		"2fa.setup_required":             "Aucune configuration de double authentification en cours.",
+		"2fa.not_enabled":                "La double authentification n'est pas activée.",
+		"2fa.code_required":              "Code de vérification ou code de secours requis.",
+		"2fa.code_invalid":               "Code de vérification invalide.",
+		"2fa.challenge_invalid":          "Connexion expirée, saisissez à nouveau votre mot de passe.",
+		"2fa.enabled":                    "Double authentification activée. Conservez vos codes de secours en lieu sûr.",
+		"2fa.disabled":                   "Double authentification désactivée.",
+		"2fa.recovery_codes_regenerated": "Nouveaux codes de secours générés, les anciens ne fonctionnent plus.",
+		"2fa.required":                   "Cette entreprise impose la double authentification : reconnectez-vous avec votre code.",
+		"2fa.enable_first":               "Activez la double authentification sur votre compte et reconnectez-vous avant de l'imposer.",
+		"2fa.required_by_business":       "Une de vos entreprises impose la double authentification : retirez d'abord cette exigence.",
+		"2fa.policy_updated":             "Exigence de double authentification mise à jour.",
+		"2fa.policy_required":            "Le champ \"require_two_factor\" est requis.",
+
+		// Durées (emails)
+		"duration.hour":    "1 heure",
+		"duration.hours":   "%d heures",
+		"duration.minutes": "%d minutes",
+		"duration.days":    "%d jours",
+
+		// Utilisateurs
+		"user.not_found":                 "Utilisateur non trouvé.",
+		"user.first_name_length":         "Le prénom doit contenir au maximum %d caractères.",
+		"user.last_name_length":          "Le nom doit contenir au maximum %d caractères.",
+		"user.phone_invalid":             "Format du numéro de téléphone invalide (ex : 0612345678 ou +33612345678).",
+		"user.current_password_required": "Le mot de passe actuel est requis.",
+		"user.current_password_invalid":  "Le mot de passe actuel est incorrect.",
+		"user.reauthentication_required": "Reconnectez-vous pour confirmer cette action.",
+		"user.password_changed":          "Mot de passe modifié avec succès.",
+		"user.avatar_deleted":            "Photo de profil supprimée.",
+		"user.profile_picture_invalid":   "La photo de profil doit être une URL https (255 caractères maximum) ou être envoyée sur POST /users/me/avatar.",
+		"upload.file_required":           "Le fichier \"%s\" est requis.",
+		"upload.type_invalid":            "Format d'image non pris en charge (PNG, JPEG ou GIF).",
+		"upload.too_large":               "L'image ne doit pas dépasser %d Ko.",
+		"upload.dimensions":              "L'image ne doit pas dépasser %d x %d pixels.",
+		"user.deletion_scheduled":        "Votre compte sera supprimé définitivement dans %s. Reconnectez-vous avant cette date pour annuler la suppression.",
+
+		// Entreprises
+		"business.name_required":         "Le nom de l'entreprise doit avoir au moins 1 caractère.",
+		"business.phone_invalid":         "Format du numéro de téléphone invalide.",
+		"business.type_invalid":          "Type de commerce invalide (liste : GET /business-types).",
+		"business.address_length":        "L'adresse de l'entreprise doit être comprise entre 1 et 100 caractères.",
+		"business.city_length":           "La ville de l'entreprise doit être comprise entre 1 et 100 caractères.",
+		"business.zip_code_length":       "Le code postal de l'entreprise doit être compris entre 1 et 100 caractères.",
+		"business.language_invalid":      "Langue non prise en charge (fr, en, es).",
+		"business.ticket_prefix_invalid": "Le préfixe de ticket doit faire 0 à 3 lettres majuscules (ex : A).",
+		"business.not_found":             "L'entreprise n'existe pas.",
+		"business.not_found_or_inactive": "Entreprise introuvable ou inactive.",
+		"business.owner_only":            "Seule la personne propriétaire de l'entreprise peut effectuer cette action.",
+		"business.created":               "L'entreprise a été créée avec succès.",
+		"business.updated":               "L'entreprise a été modifiée avec succès.",
+		"business.fetched":               "Informations de l'entreprise récupérées avec succès.",
+		"business.deleted":               "Entreprise supprimée. Elle peut être restaurée pendant %s, avant sa suppression définitive.",
+		"business.restored":              "Entreprise restaurée. Sa file d'attente reste fermée jusqu'à sa réouverture.",
+		"business.archived":              "L'entreprise a été supprimée. Restaurez-la (POST /businesses/{id}/restore) pour la modifier.",
+		"business.not_deleted":           "L'entreprise n'est pas supprimée.",
+		"business.restore_expired":       "Le délai de restauration de l'entreprise est écoulé.",
+		"business.logo_updated":          "Logo de l'entreprise enregistré.",
+		"business.logo_deleted":          "Logo de l'entreprise supprimé.",
+		"business.name_length":           "Le nom de l'entreprise doit être compris entre %d et %d caractères.",
+		"business.field_length":          "Ce champ doit être compris entre %d et %d caractères.",
+		"business.setting_range":         "La valeur doit être comprise entre %d et %d.",
+		"business.custom_message_length": "Le message personnalisé doit faire au plus %[2]d caractères.",
+		"business.opening_hours_invalid": "Horaires invalides : jours monday à sunday, heures au format HH:MM, ouverture avant la fermeture.",
+		"business.modified_concurrently": "L'entreprise a été modifiée entre-temps : rechargez-la avant de réessayer.",
+
+		// Types de commerce
+		"business_type.bakery":             "Boulangerie",
+		"business_type.pharmacy":           "Pharmacie",
+		"business_type.restaurant":         "Restaurant",
+		"business_type.post_office":        "Bureau de poste",
+		"business_type.dry_cleaning":       "Pressing",
+		"business_type.cobbler":            "Cordonnerie",
+		"business_type.watchmaker":         "Horlogerie",
+		"business_type.phone_repair":       "Réparation de téléphones",
+		"business_type.optician":           "Opticien",
+		"business_type.hairdresser":        "Coiffeur",
+		"business_type.barber":             "Barbier",
+		"business_type.beauty_salon":       "Institut de beauté",
+		"business_type.nail_salon":         "Onglerie",
+		"business_type.massage":            "Massage",
+		"business_type.tattoo":             "Tatouage",
+		"business_type.medical_office":     "Cabinet médical",
+		"business_type.dentist":            "Dentiste",
+		"business_type.veterinary":         "Vétérinaire",
+		"business_type.bank":               "Banque",
+		"business_type.insurance":          "Assurance",
+		"business_type.notary":             "Notaire",
+		"business_type.lawyer":             "Avocat",
+		"business_type.accountant":         "Expert-comptable",
+		"business_type.real_estate":        "Agence immobilière",
+		"business_type.prefecture":         "Préfecture",
+		"business_type.city_hall":          "Mairie",
+		"business_type.family_allowance":   "Caisse d'allocations familiales",
+		"business_type.employment_agency":  "France Travail",
+		"business_type.public_service":     "Service public",
+		"business_type.garage":             "Garage",
+		"business_type.vehicle_inspection": "Contrôle technique",
+		"business_type.gas_station":        "Station-service",
+		"business_type.auto_body":          "Carrosserie",
+		"business_type.tire_service":       "Centre de pneus",
+		"business_type.other":              "Autre",
+
+		// QR Code
+		"qrcode.size_range":       "La taille du code QR doit être comprise entre %d et %d pixels.",
+		"qrcode.format_invalid":   "Format non pris en charge (png, svg ou pdf).",
+		"qrcode.level_invalid":    "Niveau de correction d'erreur invalide (L, M, Q ou H).",
+		"qrcode.paper_invalid":    "Format de papier non pris en charge (a4 ou a5).",
+		"qrcode.logo_invalid":     "Le paramètre \"logo\" doit valoir true ou false.",
+		"qrcode.logo_missing":     "L'entreprise n'a pas de logo (POST /businesses/{id}/logo).",
+		"qrcode.poster_footer":    "Scannez ce code pour rejoindre la file d'attente",
+		"qrcode.size_invalid":     "Taille du code QR invalide.",
+		"qrcode.token_not_found":  "Ce QR code n'existe pas.",
+		"qrcode.token_invalid":    "Ce QR code n'est plus valable. Scannez le code affiché dans l'établissement.",
+		"qrcode.token_not_active": "Ce QR code a déjà été remplacé ou révoqué.",
+		"qrcode.token_primary":    "Le QR code principal ne peut pas être révoqué : remplacez-le (POST /businesses/{id}/qrcode/rotate).",
+		"qrcode.token_limit":      "Nombre maximum de QR codes actifs atteint : révoquez-en un avant d'en créer un autre.",
+		"qrcode.label_length":     "Le libellé doit faire au plus %d caractères.",
+		"qrcode.grace_range":      "Le délai de grâce doit être compris entre 0 et %d minutes.",
+		"qrcode.token_created":    "QR code créé.",
+		"qrcode.token_rotated":    "QR code remplacé.",
+		"qrcode.token_revoked":    "QR code révoqué.",
+
+		// Écran d'affichage
+		"display.disabled":              "L'écran d'affichage n'est pas activé pour cette entreprise.",
+		"display.enabled":               "Écran d'affichage activé.",
+		"display.rotated":               "Nouveau lien de l'écran d'affichage généré, l'ancien ne fonctionne plus.",
+		"display.disabled_done":         "Écran d'affichage désactivé.",
+		"display.token_invalid":         "Ce lien d'écran d'affichage n'est pas valide.",
+		"display.screen.now_serving":    "Au tour de",
+		"display.screen.next":           "Prochains",
+		"display.screen.waiting":        "personne(s) en attente",
+		"display.screen.estimated_wait": "Attente estimée :",
+		"display.screen.minutes":        "min",
+		"display.screen.closed":         "La file d'attente est fermée.",
+		"display.screen.paused":         "La file d'attente est en pause.",
+		"display.screen.empty":          "Personne en attente.",
+		"display.screen.enable_sound":   "Touchez l'écran pour activer le son",
+		"display.screen.disconnected":   "Connexion perdue, reconnexion...",
+		"display.screen.link_disabled":  "Ce lien a été désactivé.",
+
+		// Files d'attente
+		"queue.status_required":       `Le champ "is_queue_active" est requis.`,
+		"queue.opened":                "File d'attente ouverte !",
+		"queue.stopped":               "File d'attente fermée !",
+		"queue.business_id_required":  "Identifiant de l'entreprise requis.",
+		"queue.business_mismatch":     "Le QR code ne correspond pas à cette entreprise.",
+		"queue.phone_required":        "Numéro de téléphone requis.",
+		"queue.phone_invalid":         "Format de téléphone invalide.",
+		"queue.client_name_required":  "Nom du client requis.",
+		"queue.language_invalid":      "Langue non prise en charge (fr, en, es).",
+		"queue.closed":                "La file d'attente est fermée.",
+		"queue.full":                  "La file d'attente est complète.",
+		"queue.already_joined":        "Vous êtes déjà dans la file d'attente.",
+		"queue.joined":                "Vous avez été ajouté à la file d'attente.",
+		"queue.entry_not_found":       "Ce client n'est pas dans la file de l'entreprise.",
+		"queue.entry_not_waiting":     "Seul un client en attente peut être déplacé.",
+		"queue.entry_not_active":      "Le client n'est plus dans la file.",
+		"queue.position_invalid":      "La position doit être supérieure ou égale à 1.",
+		"queue.remove_reason_invalid": "Motif de retrait non pris en charge (%s).",
+		"queue.note_length":           "La note ne doit pas dépasser %d caractères.",
+		"queue.entry_moved":           "Client déplacé dans la file.",
+		"queue.entry_removed":         "Client retiré de la file.",
+
+		// SMS (cf. DATABASE.md, table sms_logs)
+		"sms.confirmation": "Ticket %s chez %s confirmé (position %d), temps d'attente : %d min",
+		"sms.reminder":     "Ticket %s : plus que %d clients devant vous chez %s",
+		"sms.your_turn":    "Ticket %s : c'est votre tour chez %s ! Présentez-vous au comptoir",
+		"sms.missed":       "Ticket %s : votre tour chez %s est passé. Rescannez le QR code",
+		"sms.cancelled":    "Ticket %s : votre place chez %s a été annulée",
+	},
+	"en": {
+		// Generic errors
+		"error.method_not_allowed":   "HTTP method not allowed.",
+		"error.invalid_body":         "Invalid request body.",
+		"error.unknown_field":        "Unknown field.",
+		"error.payload_too_large":    "The request body is too large.",
+		"error.validation_failed":    "Some fields are invalid.",
+		"error.internal":             "An internal error occurred.",
+		"error.not_found":            "Resource not found.",
+		"error.route_not_found":      "This route does not exist.",
+		"error.already_exists":       "The resource already exists.",
+		"error.value_taken":          "This value is already in use.",
+		"error.value_rejected":       "Value rejected.",
+		"error.constraint_violation": "The submitted data does not satisfy the constraints.",
+		"error.invalid_parameter":    "A parameter is not in the expected format.",
+
+		// Paginated lists
+		"list.limit_invalid":  "The page size must be between 1 and %d.",
+		"list.cursor_invalid": "Invalid pagination cursor (it must come from next_cursor, with the same sort).",
+		"list.sort_invalid":   "Unsupported sort (%s, prefixed with - for descending order).",
+		"list.filter_invalid": "Unsupported filter value (%s).",
+		"list.date_invalid":   "Invalid date (YYYY-MM-DD or RFC 3339).",
+
+		// Replayed requests (Idempotency-Key)
+		"idempotency.key_invalid": "The Idempotency-Key header must be 1 to %d printable characters (e.g. a UUID).",
+		"idempotency.key_reused":  "This Idempotency-Key has already been used for a different request.",
+		"idempotency.in_progress": "A request with this Idempotency-Key is still in progress, try again in a moment.",
+		"idempotency.processed":   "This request has already been processed and its response is not kept. Use a new Idempotency-Key.",
+
+		// Authentication
+		"auth.authorization_required": `"Authorization" header required.`,
+		"auth.invalid_token":          "Invalid token.",
+		"auth.invalid_credentials":    "Incorrect email or password.",
+		"auth.credentials_required":   "Email and password are required.",
+		"auth.email_required":         "Email required.",
+		"auth.email_invalid":          "Invalid email format.",
+		"auth.email_taken":            "This email is already linked to an account.",
+		"auth.password_required":      "Password required.",
+		"auth.password_invalid":       "Invalid password format.",
+		"auth.password_too_short":     "The password must be at least %d characters long.",
+		"auth.password_too_long":      "The password must not exceed %d bytes (accented characters count as several).",
+		"auth.password_classes":       "The password must mix at least %d types of characters: lowercase, uppercase, digits, symbols.",
+		"auth.password_breached":      "This password appears in known data breaches, please choose another one.",
+		"auth.oauth_state_invalid":    "Invalid OAuth state.",
+		"auth.oauth_code_invalid":     "Invalid Google authorization code.",
+		"auth.link_invalid":           "This link is invalid or has expired.",
+		"auth.token_required":         "Token required.",
+		"auth.email_not_verified":     "Confirm your email address to use this feature.",
+		"auth.email_verified":         "Email address confirmed.",
+		"auth.verification_sent":      "If an unconfirmed account exists for this email, a confirmation link has just been sent.",
+		"auth.password_reset_sent":    "If an account exists for this email, a reset link has just been sent.",
+		"auth.password_reset_done":    "Password changed, you can now log in.",
+		"auth.too_many_attempts":      "Too many failed login attempts. Please try again later.",
+
+		// Emails
+		"mail.verification.subject":     "Confirm your email address",
+		"mail.verification.body":        "Hello,\n\nWelcome to Waitify! To confirm your email address, open this link:\n\n%s\n\nThis link expires in %s. If you did not create an account, please ignore this email.\n\nThe Waitify team",
+		"mail.password_reset.subject":   "Reset your password",
+		"mail.password_reset.body":      "Hello,\n\nA password reset was requested for your Waitify account. To choose a new password, open this link:\n\n%s\n\nThis link expires in %s and can only be used once. If you did not request it, ignore this email: your password is unchanged.\n\nThe Waitify team",
+		"mail.login_locked.subject":     "Logins blocked on your Waitify account",
+		"mail.login_locked.body":        "Hello,\n\nSeveral login attempts to your Waitify account have failed. For your security, logins are blocked for %s.\n\nIf it was you, wait and try again. Otherwise, we recommend changing your password:\n\n%s\n\nThe Waitify team",
+		"mail.password_changed.subject": "Your Waitify password was changed",
+		"mail.password_changed.body":    "Hello,\n\nThe password of your Waitify account has just been changed.\n\nIf you did not make this change, reset your password immediately:\n\n%s\n\nThe Waitify team",
+		"mail.account_deletion.subject": "Deletion of your Waitify account",
+		"mail.account_deletion.body":    "Hello,\n\nThe deletion of your Waitify account was requested. Your account and your businesses are deactivated and will be permanently deleted in %s.\n\nTo cancel the deletion, simply log in again before then:\n\n%s\n\nThe Waitify team",
+
+		// Double authentification
+		"2fa.already_enabled":            "Two-factor authentication is already enabled.",
+		"2fa.setup_required":             "No two-factor authentication setup in progress.",
+		"2fa.not_enabled":                "Two-factor authentication is not enabled.",
+		"2fa.code_required":              "Verification code or recovery code required.",
+		"2fa.code_invalid":               "Invalid verification code.",
+		"2fa.challenge_invalid":          "Login expired, please enter your password again.",
+		"2fa.enabled":                    "Two-factor authentication enabled. Keep your recovery codes in a safe place.",
+		"2fa.disabled":                   "Two-factor authentication disabled.",
+		"2fa.recovery_codes_regenerated": "New recovery codes generated, the previous ones no longer work.",
+		"2fa.required":                   "This business requires two-factor authentication: log in again with your code.",
+		"2fa.enable_first":               "Enable two-factor authentication on your account and log in again before requiring it.",
+		"2fa.required_by_business":       "One of your businesses requires two-factor authentication: remove this requirement first.",
+		"2fa.policy_updated":             "Two-factor authentication requirement updated.",
+		"2fa.policy_required":            "The \"require_two_factor\" field is required.",
+
+		// Durées (emails)
+		"duration.hour":    "1 hour",
+		"duration.hours":   "%d hours",
+		"duration.minutes": "%d minutes",
+		"duration.days":    "%d days",
+
+		// Users
+		"user.not_found":                 "User not found.",
+		"user.first_name_length":         "The first name must be at most %d characters long.",
+		"user.last_name_length":          "The last name must be at most %d characters long.",
+		"user.phone_invalid":             "Invalid phone number format (e.g. 0612345678 or +33612345678).",
+		"user.current_password_required": "The current password is required.",
+		"user.reauthentication_required": "Sign in again to confirm this action.",
+		"user.current_password_invalid":  "The current password is incorrect.",
+		"user.password_changed":          "Password changed successfully.",
+		"user.avatar_deleted":            "Profile picture deleted.",
+		"user.profile_picture_invalid":   "The profile picture must be an https URL (at most 255 characters) or be uploaded to POST /users/me/avatar.",
+		"upload.file_required":           "The \"%s\" file is required.",
+		"upload.type_invalid":            "Unsupported image format (PNG, JPEG or GIF).",
+		"upload.too_large":               "The image must not exceed %d KB.",
+		"upload.dimensions":              "The image must not exceed %d x %d pixels.",
+		"user.deletion_scheduled":        "Your account will be permanently deleted in %s. Log in again before then to cancel the deletion.",
+
+		// Businesses
+		"business.name_required":         "The business name must be at least 1 character long.",
+		"business.phone_invalid":         "Invalid phone number format.",
+		"business.type_invalid":          "Invalid business type (list: GET /business-types).",
+		"business.address_length":        "The business address must be between 1 and 100 characters long.",
+		"business.city_length":           "The business city must be between 1 and 100 characters long.",
+		"business.zip_code_length":       "The business zip code must be between 1 and 100 characters long.",
+		"business.language_invalid":      "Unsupported language (fr, en, es).",
+		"business.ticket_prefix_invalid": "The ticket prefix must be 0 to 3 uppercase letters (e.g. A).",
+		"business.not_found":             "The business does not exist.",
+		"business.not_found_or_inactive": "Business not found or inactive.",
+		"business.owner_only":            "Only the owner of the business can perform this action.",
+		"business.created":               "The business was created successfully.",
+		"business.updated":               "The business was updated successfully.",
+		"business.fetched":               "Business information retrieved successfully.",
+		"business.deleted":               "Business deleted. It can be restored for %s, before it is permanently deleted.",
+		"business.restored":              "Business restored. Its queue stays closed until it is reopened.",
+		"business.archived":              "The business has been deleted. Restore it (POST /businesses/{id}/restore) to modify it.",
+		"business.not_deleted":           "The business is not deleted.",
+		"business.restore_expired":       "The restore period for this business has expired.",
+		"business.logo_updated":          "Business logo saved.",
+		"business.logo_deleted":          "Business logo deleted.",
+		"business.name_length":           "The business name must be between %d and %d characters long.",
+		"business.field_length":          "This field must be between %d and %d characters long.",
+		"business.setting_range":         "The value must be between %d and %d.",
+		"business.custom_message_length": "The custom message must be at most %[2]d characters long.",
+		"business.opening_hours_invalid": "Invalid opening hours: days monday to sunday, times formatted as HH:MM, opening before closing.",
+		"business.modified_concurrently": "The business was modified in the meantime: reload it before trying again.",
+
+		// Business types
+		"business_type.bakery":             "Bakery",
+		"business_type.pharmacy":           "Pharmacy",
+		"business_type.restaurant":         "Restaurant",
+		"business_type.post_office":        "Post office",
+		"business_type.dry_cleaning":       "Dry cleaning",
+		"business_type.cobbler":            "Cobbler",
+		"business_type.watchmaker":         "Watchmaker",
+		"business_type.phone_repair":       "Phone repair",
+		"business_type.optician":           "Optician",
+		"business_type.hairdresser":        "Hairdresser",
+		"business_type.barber":             "Barber",
+		"business_type.beauty_salon":       "Beauty salon",
+		"business_type.nail_salon":         "Nail salon",
+		"business_type.massage":            "Massage",
+		"business_type.tattoo":             "Tattoo",
+		"business_type.medical_office":     "Medical office",
+		"business_type.dentist":            "Dentist",
+		"business_type.veterinary":         "Veterinary",
+		"business_type.bank":               "Bank",
+		"business_type.insurance":          "Insurance",
+		"business_type.notary":             "Notary",
+		"business_type.lawyer":             "Lawyer",
+		"business_type.accountant":         "Accountant",
+		"business_type.real_estate":        "Real estate agency",
+		"business_type.prefecture":         "Prefecture",
+		"business_type.city_hall":          "City hall",
+		"business_type.family_allowance":   "Family allowance office",
+		"business_type.employment_agency":  "Employment agency",
+		"business_type.public_service":     "Public service",
+		"business_type.garage":             "Garage",
+		"business_type.vehicle_inspection": "Vehicle inspection",
+		"business_type.gas_station":        "Gas station",
+		"business_type.auto_body":          "Auto body shop",
+		"business_type.tire_service":       "Tire service",
+		"business_type.other":              "Other",
+
+		// QR Code
+		"qrcode.size_range":       "The QR code size must be between %d and %d pixels.",
+		"qrcode.format_invalid":   "Unsupported format (png, svg or pdf).",
+		"qrcode.level_invalid":    "Invalid error correction level (L, M, Q or H).",
+		"qrcode.paper_invalid":    "Unsupported paper size (a4 or a5).",
+		"qrcode.logo_invalid":     "The \"logo\" parameter must be true or false.",
+		"qrcode.logo_missing":     "The business has no logo (POST /businesses/{id}/logo).",
+		"qrcode.poster_footer":    "Scan this code to join the queue",
+		"qrcode.size_invalid":     "Invalid QR code size.",
+		"qrcode.token_not_found":  "This QR code does not exist.",
+		"qrcode.token_invalid":    "This QR code is no longer valid. Scan the code displayed on the premises.",
+		"qrcode.token_not_active": "This QR code has already been replaced or revoked.",
+		"qrcode.token_primary":    "The main QR code cannot be revoked: replace it instead (POST /businesses/{id}/qrcode/rotate).",
+		"qrcode.token_limit":      "Maximum number of active QR codes reached: revoke one before creating another.",
+		"qrcode.label_length":     "The label must be at most %d characters long.",
+		"qrcode.grace_range":      "The grace period must be between 0 and %d minutes.",
+		"qrcode.token_created":    "QR code created.",
+		"qrcode.token_rotated":    "QR code replaced.",
+		"qrcode.token_revoked":    "QR code revoked.",
+
+		// Display board
+		"display.disabled":              "The display board is not enabled for this business.",
+		"display.enabled":               "Display board enabled.",
+		"display.rotated":               "New display board link generated, the previous one no longer works.",
+		"display.disabled_done":         "Display board disabled.",
+		"display.token_invalid":         "This display board link is not valid.",
+		"display.screen.now_serving":    "Now serving",
+		"display.screen.next":           "Next",
+		"display.screen.waiting":        "waiting",
+		"display.screen.estimated_wait": "Estimated wait:",
+		"display.screen.minutes":        "min",
+		"display.screen.closed":         "The queue is closed.",
+		"display.screen.paused":         "The queue is paused.",
+		"display.screen.empty":          "Nobody is waiting.",
+		"display.screen.enable_sound":   "Tap the screen to enable sound",
+		"display.screen.disconnected":   "Connection lost, reconnecting...",
+		"display.screen.link_disabled":  "This link has been disabled.",
+
+		// Queues
+		"queue.status_required":       `The "is_queue_active" field is required.`,
+		"queue.opened":                "Queue opened!",
+		"queue.stopped":               "Queue closed!",
+		"queue.business_id_required":  "Business identifier required.",
+		"queue.business_mismatch":     "The QR code does not match this business.",
+		"queue.phone_required":        "Phone number required.",
+		"queue.phone_invalid":         "Invalid phone format.",
+		"queue.client_name_required":  "Client name required.",
+		"queue.language_invalid":      "Unsupported language (fr, en, es).",
+		"queue.closed":                "The queue is closed.",
+		"queue.full":                  "The queue is full.",
+		"queue.already_joined":        "You are already in the queue.",
+		"queue.joined":                "You have been added to the queue.",
+		"queue.entry_not_found":       "This client is not in the business's queue.",
+		"queue.entry_not_waiting":     "Only a waiting client can be moved.",
+		"queue.entry_not_active":      "The client is no longer in the queue.",
+		"queue.position_invalid":      "The position must be greater than or equal to 1.",
+		"queue.remove_reason_invalid": "Unsupported removal reason (%s).",
+		"queue.note_length":           "The note must not exceed %d characters.",
+		"queue.entry_moved":           "Client moved in the queue.",
+		"queue.entry_removed":         "Client removed from the queue.",
+
+		// SMS
+		"sms.confirmation": "Ticket %s at %s confirmed (position %d), waiting time: %d min",
+		"sms.reminder":     "Ticket %s: only %d customers ahead of you at %s",
+		"sms.your_turn":    "Ticket %s: it's your turn at %s! Please come to the counter",
+		"sms.missed":       "Ticket %s: your turn at %s has passed. Scan the QR code again",
+		"sms.cancelled":    "Ticket %s: your spot at %s has been cancelled",
+	},
+	"es": {
+		// Errores genéricos
+		"error.method_not_allowed":   "Método HTTP no permitido.",
+		"error.invalid_body":         "Cuerpo de la solicitud no válido.",
+		"error.unknown_field":        "Campo desconocido.",
+		"error.payload_too_large":    "El cuerpo de la solicitud es demasiado grande.",
+		"error.validation_failed":    "Algunos campos no son válidos.",
+		"error.internal":             "Se produjo un error interno.",
+		"error.not_found":            "Recurso no encontrado.",
+		"error.route_not_found":      "Esta ruta no existe.",
+		"error.already_exists":       "El recurso ya existe.",
+		"error.value_taken":          "Este valor ya está en uso.",
+		"error.value_rejected":       "Valor rechazado.",
+		"error.constraint_violation": "Los datos enviados no cumplen las restricciones.",
+		"error.invalid_parameter":    "Un parámetro no tiene el formato esperado.",
+
+		// Listas paginadas
+		"list.limit_invalid":  "El número de elementos por página debe estar entre 1 y %d.",
+		"list.cursor_invalid": "Cursor de paginación no válido (debe proceder de next_cursor, con el mismo orden).",
+		"list.sort_invalid":   "Orden no admitido (%s, con el prefijo - para el orden descendente).",
+		"list.filter_invalid": "Valor de filtro no admitido (%s).",
+		"list.date_invalid":   "Fecha no válida (YYYY-MM-DD o RFC 3339).",
+
+		// Solicitudes repetidas (Idempotency-Key)
+		"idempotency.key_invalid": "El encabezado Idempotency-Key debe tener de 1 a %d caracteres imprimibles (p. ej. un UUID).",
+		"idempotency.key_reused":  "Esta Idempotency-Key ya se utilizó para otra solicitud.",
+		"idempotency.in_progress": "Una solicitud con esta Idempotency-Key sigue en curso, vuelva a intentarlo en un momento.",
+		"idempotency.processed":   "Esta solicitud ya se procesó y su respuesta no se conserva. Utilice una nueva Idempotency-Key.",
+
+		// Autenticación
+		"auth.authorization_required": `Se requiere el encabezado "Authorization".`,
+		"auth.invalid_token":          "Token no válido.",
+		"auth.invalid_credentials":    "Correo electrónico o contraseña incorrectos.",
+		"auth.credentials_required":   "El correo electrónico y la contraseña son obligatorios.",
+		"auth.email_required":         "Correo electrónico obligatorio.",
+		"auth.email_invalid":          "Formato de correo electrónico no válido.",
+		"auth.email_taken":            "Este correo electrónico ya está asociado a una cuenta.",
+		"auth.password_required":      "Contraseña obligatoria.",
+		"auth.password_invalid":       "Formato de contraseña no válido.",
+		"auth.password_too_short":     "La contraseña debe tener al menos %d caracteres.",
+		"auth.password_too_long":      "La contraseña no debe superar %d bytes (los caracteres acentuados cuentan como varios).",
+		"auth.password_classes":       "La contraseña debe combinar al menos %d tipos de caracteres: minúsculas, mayúsculas, números, símbolos.",
+		"auth.password_breached":      "Esta contraseña aparece en filtraciones de datos conocidas, elija otra.",
+		"auth.oauth_state_invalid":    "Estado OAuth no válido.",
+		"auth.oauth_code_invalid":     "Código de autorización de Google no válido.",
+		"auth.link_invalid":           "Este enlace no es válido o ha caducado.",
+		"auth.token_required":         "Token obligatorio.",
+		"auth.email_not_verified":     "Confirme su correo electrónico para usar esta función.",
+		"auth.email_verified":         "Correo electrónico confirmado.",
+		"auth.verification_sent":      "Si existe una cuenta sin confirmar para este correo, se acaba de enviar un enlace de confirmación.",
+		"auth.password_reset_sent":    "Si existe una cuenta para este correo, se acaba de enviar un enlace de restablecimiento.",
+		"auth.password_reset_done":    "Contraseña modificada, ya puede iniciar sesión.",
+		"auth.too_many_attempts":      "Demasiados intentos de inicio de sesión fallidos. Inténtelo más tarde.",
+
+		// Emails
+		"mail.verification.subject":     "Confirme su correo electrónico",
+		"mail.verification.body":        "Hola:\n\n¡Bienvenido a Waitify! Para confirmar su correo electrónico, abra este enlace:\n\n%s\n\nEste enlace caduca en %s. Si no ha creado ninguna cuenta, ignore este correo.\n\nEl equipo de Waitify",
+		"mail.password_reset.subject":   "Restablecer su contraseña",
+		"mail.password_reset.body":      "Hola:\n\nSe ha solicitado restablecer la contraseña de su cuenta Waitify. Para elegir una nueva contraseña, abra este enlace:\n\n%s\n\nEste enlace caduca en %s y solo puede usarse una vez. Si no lo ha solicitado, ignore este correo: su contraseña no cambia.\n\nEl equipo de Waitify",
+		"mail.login_locked.subject":     "Inicios de sesión bloqueados en su cuenta Waitify",
+		"mail.login_locked.body":        "Hola:\n\nVarios intentos de inicio de sesión en su cuenta Waitify han fallado. Por seguridad, los inicios de sesión están bloqueados durante %s.\n\nSi fue usted, espere e inténtelo de nuevo. Si no, le recomendamos cambiar su contraseña:\n\n%s\n\nEl equipo de Waitify",
+		"mail.password_changed.subject": "Se ha cambiado su contraseña de Waitify",
+		"mail.password_changed.body":    "Hola:\n\nAcaba de cambiarse la contraseña de su cuenta Waitify.\n\nSi no ha sido usted, restablezca su contraseña inmediatamente:\n\n%s\n\nEl equipo de Waitify",
+		"mail.account_deletion.subject": "Eliminación de su cuenta Waitify",
+		"mail.account_deletion.body":    "Hola:\n\nSe ha solicitado la eliminación de su cuenta Waitify. Su cuenta y sus negocios están desactivados y se eliminarán definitivamente dentro de %s.\n\nPara cancelar la eliminación, basta con volver a iniciar sesión antes de esa fecha:\n\n%s\n\nEl equipo de Waitify",
+
+		// Double authentification
+		"2fa.already_enabled":            "La autenticación en dos pasos ya está activada.",
+		"2fa.setup_required":             "No hay ninguna configuración de autenticación en dos pasos en curso.",
+		"2fa.not_enabled":                "La autenticación en dos pasos no está activada.",
+		"2fa.code_required":              "Se requiere un código de verificación o un código de recuperación.",
+		"2fa.code_invalid":               "Código de verificación no válido.",
+		"2fa.challenge_invalid":          "El inicio de sesión ha caducado, introduzca de nuevo su contraseña.",
+		"2fa.enabled":                    "Autenticación en dos pasos activada. Guarde sus códigos de recuperación en un lugar seguro.",
+		"2fa.disabled":                   "Autenticación en dos pasos desactivada.",
+		"2fa.recovery_codes_regenerated": "Nuevos códigos de recuperación generados, los anteriores ya no funcionan.",
+		"2fa.required":                   "Este negocio exige la autenticación en dos pasos: vuelva a iniciar sesión con su código.",
+		"2fa.enable_first":               "Active la autenticación en dos pasos en su cuenta y vuelva a iniciar sesión antes de exigirla.",
+		"2fa.required_by_business":       "Uno de sus negocios exige la autenticación en dos pasos: retire primero esta exigencia.",
+		"2fa.policy_updated":             "Exigencia de autenticación en dos pasos actualizada.",
+		"2fa.policy_required":            "El campo \"require_two_factor\" es obligatorio.",
+
+		// Durées (emails)
+		"duration.hour":    "1 hora",
+		"duration.hours":   "%d horas",
+		"duration.minutes": "%d minutos",
+		"duration.days":    "%d días",
+
+		// Usuarios
+		"user.not_found":                 "Usuario no encontrado.",
+		"user.first_name_length":         "El nombre debe tener como máximo %d caracteres.",
+		"user.last_name_length":          "El apellido debe tener como máximo %d caracteres.",
+		"user.phone_invalid":             "Formato de número de teléfono no válido (ej.: 0612345678 o +33612345678).",
+		"user.current_password_required": "Se requiere la contraseña actual.",
+		"user.reauthentication_required": "Vuelva a iniciar sesión para confirmar esta acción.",
+		"user.current_password_invalid":  "La contraseña actual es incorrecta.",
+		"user.password_changed":          "Contraseña cambiada correctamente.",
+		"user.avatar_deleted":            "Foto de perfil eliminada.",
+		"user.profile_picture_invalid":   "La foto de perfil debe ser una URL https (255 caracteres como máximo) o enviarse a POST /users/me/avatar.",
+		"upload.file_required":           "El archivo \"%s\" es obligatorio.",
+		"upload.type_invalid":            "Formato de imagen no admitido (PNG, JPEG o GIF).",
+		"upload.too_large":               "La imagen no debe superar %d KB.",
+		"upload.dimensions":              "La imagen no debe superar %d x %d píxeles.",
+		"user.deletion_scheduled":        "Su cuenta se eliminará definitivamente dentro de %s. Vuelva a iniciar sesión antes de esa fecha para cancelar la eliminación.",
+
+		// Negocios
+		"business.name_required":         "El nombre del negocio debe tener al menos 1 carácter.",
+		"business.phone_invalid":         "Formato de número de teléfono no válido.",
+		"business.type_invalid":          "Tipo de negocio no válido (lista: GET /business-types).",
+		"business.address_length":        "La dirección del negocio debe tener entre 1 y 100 caracteres.",
+		"business.city_length":           "La ciudad del negocio debe tener entre 1 y 100 caracteres.",
+		"business.zip_code_length":       "El código postal del negocio debe tener entre 1 y 100 caracteres.",
+		"business.language_invalid":      "Idioma no compatible (fr, en, es).",
+		"business.ticket_prefix_invalid": "El prefijo del ticket debe tener de 0 a 3 letras mayúsculas (p. ej. A).",
+		"business.not_found":             "El negocio no existe.",
+		"business.not_found_or_inactive": "Negocio no encontrado o inactivo.",
+		"business.owner_only":            "Solo el propietario del negocio puede realizar esta acción.",
+		"business.created":               "El negocio se ha creado correctamente.",
+		"business.updated":               "El negocio se ha modificado correctamente.",
+		"business.fetched":               "Información del negocio obtenida correctamente.",
+		"business.deleted":               "Negocio eliminado. Puede restaurarse durante %s, antes de su eliminación definitiva.",
+		"business.restored":              "Negocio restaurado. Su cola permanece cerrada hasta que se vuelva a abrir.",
+		"business.archived":              "El negocio ha sido eliminado. Restáurelo (POST /businesses/{id}/restore) para modificarlo.",
+		"business.not_deleted":           "El negocio no está eliminado.",
+		"business.restore_expired":       "El plazo de restauración del negocio ha vencido.",
+		"business.logo_updated":          "Logotipo del negocio guardado.",
+		"business.logo_deleted":          "Logotipo del negocio eliminado.",
+		"business.name_length":           "El nombre del negocio debe tener entre %d y %d caracteres.",
+		"business.field_length":          "Este campo debe tener entre %d y %d caracteres.",
+		"business.setting_range":         "El valor debe estar entre %d y %d.",
+		"business.custom_message_length": "El mensaje personalizado debe tener como máximo %[2]d caracteres.",
+		"business.opening_hours_invalid": "Horario no válido: días monday a sunday, horas en formato HH:MM, apertura antes del cierre.",
+		"business.modified_concurrently": "El negocio se ha modificado mientras tanto: vuelva a cargarlo antes de intentarlo de nuevo.",
+
+		// Tipos de negocio
+		"business_type.bakery":             "Panadería",
+		"business_type.pharmacy":           "Farmacia",
+		"business_type.restaurant":         "Restaurante",
+		"business_type.post_office":        "Oficina de correos",
+		"business_type.dry_cleaning":       "Tintorería",
+		"business_type.cobbler":            "Zapatero",
+		"business_type.watchmaker":         "Relojería",
+		"business_type.phone_repair":       "Reparación de teléfonos",
+		"business_type.optician":           "Óptica",
+		"business_type.hairdresser":        "Peluquería",
+		"business_type.barber":             "Barbería",
+		"business_type.beauty_salon":       "Salón de belleza",
+		"business_type.nail_salon":         "Salón de uñas",
+		"business_type.massage":            "Masajes",
+		"business_type.tattoo":             "Tatuajes",
+		"business_type.medical_office":     "Consultorio médico",
+		"business_type.dentist":            "Dentista",
+		"business_type.veterinary":         "Veterinario",
+		"business_type.bank":               "Banco",
+		"business_type.insurance":          "Seguros",
+		"business_type.notary":             "Notaría",
+		"business_type.lawyer":             "Abogado",
+		"business_type.accountant":         "Contable",
+		"business_type.real_estate":        "Agencia inmobiliaria",
+		"business_type.prefecture":         "Prefectura",
+		"business_type.city_hall":          "Ayuntamiento",
+		"business_type.family_allowance":   "Oficina de prestaciones familiares",
+		"business_type.employment_agency":  "Oficina de empleo",
+		"business_type.public_service":     "Servicio público",
+		"business_type.garage":             "Taller mecánico",
+		"business_type.vehicle_inspection": "Inspección técnica de vehículos",
+		"business_type.gas_station":        "Gasolinera",
+		"business_type.auto_body":          "Taller de carrocería",
+		"business_type.tire_service":       "Centro de neumáticos",
+		"business_type.other":              "Otro",
+
+		// Código QR
+		"qrcode.size_range":       "El tamaño del código QR debe estar entre %d y %d píxeles.",
+		"qrcode.format_invalid":   "Formato no admitido (png, svg o pdf).",
+		"qrcode.level_invalid":    "Nivel de corrección de errores no válido (L, M, Q o H).",
+		"qrcode.paper_invalid":    "Tamaño de papel no admitido (a4 o a5).",
+		"qrcode.logo_invalid":     "El parámetro \"logo\" debe ser true o false.",
+		"qrcode.logo_missing":     "El negocio no tiene logotipo (POST /businesses/{id}/logo).",
+		"qrcode.poster_footer":    "Escanee este código para unirse a la fila",
+		"qrcode.size_invalid":     "Tamaño del código QR no válido.",
+		"qrcode.token_not_found":  "Este código QR no existe.",
+		"qrcode.token_invalid":    "Este código QR ya no es válido. Escanee el código expuesto en el establecimiento.",
+		"qrcode.token_not_active": "Este código QR ya ha sido sustituido o revocado.",
+		"qrcode.token_primary":    "El código QR principal no se puede revocar: sustitúyalo (POST /businesses/{id}/qrcode/rotate).",
+		"qrcode.token_limit":      "Se ha alcanzado el número máximo de códigos QR activos: revoque uno antes de crear otro.",
+		"qrcode.label_length":     "La etiqueta debe tener como máximo %d caracteres.",
+		"qrcode.grace_range":      "El periodo de gracia debe estar entre 0 y %d minutos.",
+		"qrcode.token_created":    "Código QR creado.",
+		"qrcode.token_rotated":    "Código QR sustituido.",
+		"qrcode.token_revoked":    "Código QR revocado.",
+
+		// Pantalla de visualización
+		"display.disabled":              "La pantalla de visualización no está activada para este negocio.",
+		"display.enabled":               "Pantalla de visualización activada.",
+		"display.rotated":               "Nuevo enlace de la pantalla de visualización generado, el anterior ya no funciona.",
+		"display.disabled_done":         "Pantalla de visualización desactivada.",
+		"display.token_invalid":         "Este enlace de pantalla de visualización no es válido.",
+		"display.screen.now_serving":    "Es el turno de",
+		"display.screen.next":           "Siguientes",
+		"display.screen.waiting":        "persona(s) en espera",
+		"display.screen.estimated_wait": "Espera estimada:",
+		"display.screen.minutes":        "min",
+		"display.screen.closed":         "La cola está cerrada.",
+		"display.screen.paused":         "La cola está en pausa.",
+		"display.screen.empty":          "No hay nadie en espera.",
+		"display.screen.enable_sound":   "Toque la pantalla para activar el sonido",
+		"display.screen.disconnected":   "Conexión perdida, reconectando...",
+		"display.screen.link_disabled":  "Este enlace ha sido desactivado.",
+
+		// Colas
+		"queue.status_required":       `El campo "is_queue_active" es obligatorio.`,
+		"queue.opened":                "¡Cola abierta!",
+		"queue.stopped":               "¡Cola cerrada!",
+		"queue.business_id_required":  "Identificador del negocio obligatorio.",
+		"queue.business_mismatch":     "El código QR no corresponde a este negocio.",
+		"queue.phone_required":        "Número de teléfono obligatorio.",
+		"queue.phone_invalid":         "Formato de teléfono no válido.",
+		"queue.client_name_required":  "Nombre del cliente obligatorio.",
+		"queue.language_invalid":      "Idioma no compatible (fr, en, es).",
+		"queue.closed":                "La cola está cerrada.",
+		"queue.full":                  "La cola está completa.",
+		"queue.already_joined":        "Ya está en la cola.",
+		"queue.joined":                "Se le ha añadido a la cola.",
+		"queue.entry_not_found":       "Este cliente no está en la cola del negocio.",
+		"queue.entry_not_waiting":     "Solo se puede mover a un cliente en espera.",
+		"queue.entry_not_active":      "El cliente ya no está en la cola.",
+		"queue.position_invalid":      "La posición debe ser mayor o igual a 1.",
+		"queue.remove_reason_invalid": "Motivo de retirada no admitido (%s).",
+		"queue.note_length":           "La nota no debe superar los %d caracteres.",
+		"queue.entry_moved":           "Cliente movido en la cola.",
+		"queue.entry_removed":         "Cliente retirado de la cola.",
+
+		// SMS
+		"sms.confirmation": "Ticket %s en %s confirmado (posición %d), tiempo de espera: %d min",
+		"sms.reminder":     "Ticket %s: solo quedan %d clientes delante de usted en %s",
+		"sms.your_turn":    "Ticket %s: ¡es su turno en %s! Acérquese al mostrador",
+		"sms.missed":       "Ticket %s: su turno en %s ha pasado. Vuelva a escanear el código QR",
+		"sms.cancelled":    "Ticket %s: su turno en %s ha sido cancelado",
+	},
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Développement : un fichier .eml par email, lisible par n'importe quel client mail
+type fileMailer struct {
+	dir string
+}
+
+func newFileMailer(dir string) (*fileMailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("[file.go -> newFileMailer()] -> Impossible de créer le dossier %q : %w", dir, err)
+	}
+	return &fileMailer{dir: dir}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	content, err := buildMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.dir, time.Now().Format("20060102-150405")+"-"+uuid.New().String()[:8]+".eml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("[file.go -> Send()] -> Impossible d'écrire l'email : %w", err)
+	}
+
+	slog.InfoContext(ctx, "Email écrit sur le disque", "type", msg.Type, "path", path)
+	return nil
+}
+
+/*
+Développement : l'email (liens compris) est écrit dans les logs
+Ne jamais utiliser en production, les liens donnent accès aux comptes
+*/
+type logMailer struct{}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "Email non envoyé (MAIL_DRIVER=log)", "type", msg.Type, "to", msg.To, "subject", msg.Subject, "body", msg.Text)
+	return nil
+}
@@ -0,0 +1,151 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+// Délai maximum d'un envoi en arrière-plan
+const sendTimeout = 30 * time.Second
+
+// Email texte brut
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	Type    string // métriques : email_verification, password_reset...
+}
+
+/*
+Envoi d'emails, choisi par la configuration (MAIL_DRIVER)
+- smtp : serveur SMTP (production)
+- file : un fichier .eml par email dans MAIL_FILE_DIR (développement, tests manuels)
+- log : l'email est écrit dans les logs (développement)
+*/
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	current Mailer = &logMailer{}
+	from           = "Waitify <no-reply@waitify.fr>"
+	wg      sync.WaitGroup
+)
+
+// Choisir l'implémentation à partir de la configuration (déjà validée)
+func Init(cfg *config.Config) error {
+	from = cfg.Mail.From
+
+	switch cfg.Mail.Driver {
+	case "smtp":
+		current = newSMTPMailer(cfg)
+	case "file":
+		fileMailer, err := newFileMailer(cfg.Mail.FileDir)
+		if err != nil {
+			return err
+		}
+		current = fileMailer
+	case "log":
+		current = &logMailer{}
+	default:
+		return fmt.Errorf("[mailer.go -> Init()] -> Driver d'email inconnu %q", cfg.Mail.Driver)
+	}
+	return nil
+}
+
+// Envoyer un email et attendre le résultat
+func Send(ctx context.Context, msg Message) error {
+	err := current.Send(ctx, msg)
+	telemetry.RecordEmail(msg.Type, err)
+	return err
+}
+
+/*
+Envoyer un email en arrière-plan : la réponse HTTP n'attend pas le serveur SMTP
+(et son temps de réponse ne révèle pas si le compte existe). Les erreurs sont journalisées.
+*/
+func SendAsync(ctx context.Context, msg Message) {
+	ctx = context.WithoutCancel(ctx)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+
+		if err := Send(ctx, msg); err != nil {
+			slog.ErrorContext(ctx, "Erreur lors de l'envoi de l'email", "type", msg.Type, "error", err)
+		}
+	}()
+}
+
+// Attendre la fin des envois en cours (arrêt du serveur)
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Email au format RFC 5322 (UTF-8, quoted-printable)
+func buildMessage(msg Message) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("[mailer.go -> buildMessage()] -> Expéditeur invalide : %w", err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("[mailer.go -> buildMessage()] -> Destinataire invalide : %w", err)
+	}
+
+	_, domain, _ := strings.Cut(sender.Address, "@")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", sender.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", recipient.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", uuid.New().String(), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(msg.Text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Adresse email seule (enveloppe SMTP)
+func address(value string) (string, error) {
+	parsed, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Address, nil
+}
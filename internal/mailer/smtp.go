@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+)
+
+/*
+Envoi par un serveur SMTP
+- Port 465 : TLS dès la connexion
+- Autres ports : STARTTLS dès que le serveur le propose (obligatoire pour s'authentifier, sauf en local)
+*/
+type smtpMailer struct {
+	host     string
+	addr     string
+	user     string
+	password string
+}
+
+func newSMTPMailer(cfg *config.Config) *smtpMailer {
+	return &smtpMailer{
+		host:     cfg.Mail.SMTPHost,
+		addr:     net.JoinHostPort(cfg.Mail.SMTPHost, strconv.Itoa(cfg.Mail.SMTPPort)),
+		user:     cfg.Mail.SMTPUser,
+		password: cfg.Mail.SMTPPassword,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	content, err := buildMessage(msg)
+	if err != nil {
+		return err
+	}
+	sender, err := address(from)
+	if err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> Expéditeur invalide : %w", err)
+	}
+	recipient, err := address(msg.To)
+	if err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> Destinataire invalide : %w", err)
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> Connexion au serveur SMTP impossible : %w", err)
+	}
+	// Le contexte borne toute la conversation SMTP
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("[smtp.go -> Send()] -> Serveur SMTP invalide : %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("[smtp.go -> Send()] -> STARTTLS : %w", err)
+		}
+	}
+
+	if m.user != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.user, m.password, m.host)); err != nil {
+			return fmt.Errorf("[smtp.go -> Send()] -> Authentification SMTP : %w", err)
+		}
+	}
+
+	if err := client.Mail(sender); err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> MAIL FROM : %w", err)
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> RCPT TO : %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> DATA : %w", err)
+	}
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return fmt.Errorf("[smtp.go -> Send()] -> Écriture de l'email : %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("[smtp.go -> Send()] -> Email refusé : %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (m *smtpMailer) dial(ctx context.Context) (net.Conn, error) {
+	var dialer net.Dialer
+	_, port, _ := net.SplitHostPort(m.addr)
+	if port == "465" {
+		tlsDialer := tls.Dialer{NetDialer: &dialer, Config: &tls.Config{ServerName: m.host, MinVersion: tls.VersionTLS12}}
+		return tlsDialer.DialContext(ctx, "tcp", m.addr)
+	}
+	return dialer.DialContext(ctx, "tcp", m.addr)
+}
@@ -1,17 +1,26 @@
 package middlewares
 
 import (
+	"database/sql"
 	"net/http"
 	"strings"
 
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
 	"github.com/StevenYAMBOS/waitify-api/internal/utils"
 )
 
+/*
+Token JWT obligatoire (header Authorization)
+Le compte est relu en base : après DELETE /users/me, les tokens déjà émis sont refusés comme un token invalide
+(une reconnexion pendant le délai de grâce passe par /auth/login et restaure le compte)
+Les tokens émis avant le dernier changement de mot de passe (password_changed_at) sont refusés de la même manière
+*/
 func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenString := r.Header.Get("Authorization")
 		if tokenString == "" {
-			http.Error(w, `[authMiddleware.go -> AuthMiddleware()] -> Authorization header requis !`, http.StatusUnauthorized)
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "auth.authorization_required")
 			return
 		}
 
@@ -20,9 +29,61 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 		}
 
-		_, err := utils.ValidateToken(tokenString)
+		claims, err := utils.ValidateToken(tokenString)
 		if err != nil {
-			http.Error(w, `[authMiddleware.go -> AuthMiddleware()] -> Token invalide.`, http.StatusUnauthorized)
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidToken, "auth.invalid_token")
+			return
+		}
+
+		if claims.IssuedAt == nil {
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidToken, "auth.invalid_token")
+			return
+		}
+
+		// Compte supprimé (ou en cours de suppression), ou mot de passe changé depuis l'émission du token
+		var active bool
+		err = database.DB.QueryRowContext(r.Context(), `
+			SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL AND (password_changed_at IS NULL OR password_changed_at <= $2))`,
+			claims.UserID, claims.IssuedAt.Time).Scan(&active)
+		if err != nil {
+			utils.WriteInternalError(w, r, "authMiddleware.go -> AuthMiddleware()", err)
+			return
+		}
+		if !active {
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidToken, "auth.invalid_token")
+			return
+		}
+
+		// Utilisateur authentifié disponible pour les handlers (utils.ClaimsFromContext)
+		next.ServeHTTP(w, r.WithContext(utils.WithClaims(r.Context(), claims)))
+	}
+}
+
+/*
+Fonctionnalités réservées aux comptes dont l'email est confirmé (création d'entreprise, ouverture de file...)
+À placer après AuthMiddleware. L'état est relu en base : le JWT émis avant la confirmation reste valable.
+Un compte en cours de suppression est refusé comme un token invalide.
+*/
+func VerifiedEmailMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims := utils.ClaimsFromContext(r.Context())
+		if claims == nil {
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "auth.authorization_required")
+			return
+		}
+
+		var verified bool
+		err := database.DB.QueryRowContext(r.Context(), "SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL", claims.UserID).Scan(&verified)
+		if err == sql.ErrNoRows {
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidToken, "auth.invalid_token")
+			return
+		}
+		if err != nil {
+			utils.WriteInternalError(w, r, "authMiddleware.go -> VerifiedEmailMiddleware()", err)
+			return
+		}
+		if !verified {
+			utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeEmailNotVerified, "auth.email_not_verified")
 			return
 		}
 
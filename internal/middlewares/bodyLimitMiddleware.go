@@ -0,0 +1,16 @@
+package middlewares
+
+import "net/http"
+
+/*
+Limiter la taille du corps de la requête pour une route
+Au-delà de `limit` octets, la lecture du corps échoue (http.MaxBytesError) et le handler répond 413 (utils.WriteBodyError)
+*/
+func BodyLimitMiddleware(limit int64) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+/*
+Adresse IP réelle du client derrière un load balancer
+Avec `trustProxyHeaders`, r.RemoteAddr est remplacée par la dernière adresse de "X-Forwarded-For" (celle ajoutée par notre proxy, les précédentes sont fournies par le client et falsifiables)
+Sans proxy devant l'API, ne pas activer : n'importe quel client pourrait choisir son adresse
+*/
+func ClientIPMiddleware(trustProxyHeaders bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !trustProxyHeaders {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+				addresses := strings.Split(forwarded, ",")
+				if ip := net.ParseIP(strings.TrimSpace(addresses[len(addresses)-1])); ip != nil {
+					r = r.WithContext(r.Context())
+					r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
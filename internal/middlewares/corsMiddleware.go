@@ -1,18 +1,160 @@
 package middlewares
 
-import "net/http"
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
-func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+)
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// Politique CORS prête à l'emploi (origines normalisées, headers déjà joints)
+type corsPolicy struct {
+	allowAll         bool
+	origins          []string // origines exactes
+	suffixes         []string // "https://*.waitify.fr" -> schéma "https://" + suffixe ".waitify.fr"
+	allowCredentials bool
+	allowedHeaders   []string
+	exposedHeaders   string
+	maxAge           string
+}
+
+func newCORSPolicy(cfg config.CORSPolicy) corsPolicy {
+	policy := corsPolicy{
+		allowCredentials: cfg.AllowCredentials,
+		exposedHeaders:   strings.Join(cfg.ExposedHeaders, ", "),
+	}
+	if cfg.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	for _, header := range cfg.AllowedHeaders {
+		policy.allowedHeaders = append(policy.allowedHeaders, strings.ToLower(header))
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			policy.allowAll = true
+		case strings.Contains(origin, "://*."):
+			policy.suffixes = append(policy.suffixes, origin)
+		default:
+			policy.origins = append(policy.origins, origin)
 		}
+	}
+	return policy
+}
+
+func (p corsPolicy) allows(origin string) bool {
+	if p.allowAll {
+		return true
+	}
 
-		next.ServeHTTP(w, r)
+	origin = strings.ToLower(origin)
+	if slices.Contains(p.origins, origin) {
+		return true
+	}
+	for _, wildcard := range p.suffixes {
+		scheme, suffix, _ := strings.Cut(wildcard, "://*")
+		if strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, suffix) && len(origin) > len(scheme)+3+len(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+CORS appliqué une seule fois autour du routeur
+- Routes dont le chemin commence par un des `publicPrefixes` (page client) -> politique `public`, sinon `merchant`
+- Origine absente de la liste -> aucun header CORS (le navigateur bloque la réponse)
+- Preflight (OPTIONS + Access-Control-Request-Method) -> 204 avec les méthodes réellement enregistrées pour ce chemin
+*/
+func NewCORSMiddleware(mux *http.ServeMux, public, merchant config.CORSPolicy, publicPrefixes ...string) func(http.Handler) http.Handler {
+	publicPolicy := newCORSPolicy(public)
+	merchantPolicy := newCORSPolicy(merchant)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy := merchantPolicy
+			for _, prefix := range publicPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					policy = publicPolicy
+					break
+				}
+			}
+
+			headers := w.Header()
+			headers.Add("Vary", "Origin")
+			if preflight {
+				headers.Add("Vary", "Access-Control-Request-Method")
+				headers.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if !policy.allows(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// "*" n'est pas accepté par les navigateurs avec les cookies : on renvoie alors l'origine exacte
+			if policy.allowAll && !policy.allowCredentials {
+				headers.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				headers.Set("Access-Control-Allow-Origin", origin)
+			}
+			if policy.allowCredentials {
+				headers.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if policy.exposedHeaders != "" {
+					headers.Set("Access-Control-Expose-Headers", policy.exposedHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Preflight : chemin inconnu -> 404 classique
+			methods := utils.AllowedMethods(mux, r)
+			if len(methods) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			headers.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if requested := allowedRequestHeaders(policy, r.Header.Get("Access-Control-Request-Headers")); requested != "" {
+				headers.Set("Access-Control-Allow-Headers", requested)
+			}
+			if policy.maxAge != "" {
+				headers.Set("Access-Control-Max-Age", policy.maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// Headers demandés par le navigateur et autorisés par la politique
+func allowedRequestHeaders(policy corsPolicy, requested string) string {
+	var allowed []string
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header != "" && slices.Contains(policy.allowedHeaders, header) {
+			allowed = append(allowed, header)
+		}
 	}
+	return strings.Join(allowed, ", ")
 }
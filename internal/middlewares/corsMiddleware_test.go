@@ -0,0 +1,146 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+)
+
+func TestCORSPolicyAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    bool
+	}{
+		{"origine exacte", []string{"https://app.waitify.fr"}, "https://app.waitify.fr", true},
+		{"casse et slash final ignorés", []string{"https://App.Waitify.fr/"}, "https://app.waitify.FR", true},
+		{"autre origine", []string{"https://app.waitify.fr"}, "https://evil.fr", false},
+		{"autre schéma", []string{"https://app.waitify.fr"}, "http://app.waitify.fr", false},
+		{"autre port", []string{"https://app.waitify.fr"}, "https://app.waitify.fr:8443", false},
+		{"sous-domaine", []string{"https://*.waitify.fr"}, "https://shop.waitify.fr", true},
+		{"sous-domaine imbriqué", []string{"https://*.waitify.fr"}, "https://a.b.waitify.fr", true},
+		{"domaine nu refusé par le joker", []string{"https://*.waitify.fr"}, "https://waitify.fr", false},
+		{"suffixe sans point", []string{"https://*.waitify.fr"}, "https://evilwaitify.fr", false},
+		{"joker avec un autre schéma", []string{"https://*.waitify.fr"}, "http://shop.waitify.fr", false},
+		{"toutes les origines", []string{"*"}, "https://n-importe-ou.fr", true},
+		{"aucune origine", nil, "https://app.waitify.fr", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := newCORSPolicy(config.CORSPolicy{AllowedOrigins: test.origins})
+			if got := policy.allows(test.origin); got != test.want {
+				t.Fatalf("allows(%q) = %v, attendu %v", test.origin, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("GET /business/{id}", ok)
+	mux.HandleFunc("PATCH /business/{id}", ok)
+	mux.HandleFunc("GET /queue/info/{token}", ok)
+	mux.HandleFunc("POST /queue/join", ok)
+
+	merchant := config.CORSPolicy{
+		AllowedOrigins:   []string{"https://app.waitify.fr"},
+		AllowCredentials: true,
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "If-Match"},
+		ExposedHeaders:   []string{"ETag", "X-Request-Id"},
+		MaxAge:           10 * time.Minute,
+	}
+	public := config.CORSPolicy{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"Content-Type"}}
+	handler := NewCORSMiddleware(mux, public, merchant, "/queue/")(mux)
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		origin          string
+		requestMethod   string // Access-Control-Request-Method : preflight
+		requestHeaders  string
+		wantStatus      int
+		wantAllowOrigin string
+		wantMethods     string
+		wantHeaders     string
+		wantCredentials string
+		wantExposed     string
+		wantMaxAge      string
+		wantVaryOrigin  bool
+	}{
+		{
+			name: "sans origine", method: http.MethodGet, path: "/business/1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "requête simple autorisée", method: http.MethodGet, path: "/business/1", origin: "https://app.waitify.fr",
+			wantStatus: http.StatusOK, wantAllowOrigin: "https://app.waitify.fr", wantCredentials: "true", wantExposed: "ETag, X-Request-Id",
+			wantVaryOrigin: true,
+		},
+		{
+			name: "requête simple refusée", method: http.MethodGet, path: "/business/1", origin: "https://evil.fr",
+			wantStatus: http.StatusOK, wantVaryOrigin: true,
+		},
+		{
+			name: "preflight autorisé", method: http.MethodOptions, path: "/business/1", origin: "https://app.waitify.fr",
+			requestMethod: http.MethodPatch, requestHeaders: "Authorization, If-Match, X-Debug",
+			wantStatus: http.StatusNoContent, wantAllowOrigin: "https://app.waitify.fr", wantMethods: "GET, PATCH",
+			wantHeaders: "authorization, if-match", wantCredentials: "true", wantMaxAge: "600", wantVaryOrigin: true,
+		},
+		{
+			name: "preflight refusé", method: http.MethodOptions, path: "/business/1", origin: "https://evil.fr",
+			requestMethod: http.MethodPatch, wantStatus: http.StatusNoContent, wantVaryOrigin: true,
+		},
+		{
+			name: "preflight d'un chemin inconnu", method: http.MethodOptions, path: "/inconnu", origin: "https://app.waitify.fr",
+			requestMethod: http.MethodGet, wantStatus: http.StatusNotFound, wantAllowOrigin: "https://app.waitify.fr",
+			wantCredentials: "true", wantVaryOrigin: true,
+		},
+		{
+			name: "preflight de la page client", method: http.MethodOptions, path: "/queue/join", origin: "https://n-importe-ou.fr",
+			requestMethod: http.MethodPost, requestHeaders: "content-type",
+			wantStatus: http.StatusNoContent, wantAllowOrigin: "*", wantMethods: "POST", wantHeaders: "content-type", wantVaryOrigin: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, test.path, nil)
+			if test.origin != "" {
+				request.Header.Set("Origin", test.origin)
+			}
+			if test.requestMethod != "" {
+				request.Header.Set("Access-Control-Request-Method", test.requestMethod)
+			}
+			if test.requestHeaders != "" {
+				request.Header.Set("Access-Control-Request-Headers", test.requestHeaders)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			headers := recorder.Header()
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("statut %d, attendu %d", recorder.Code, test.wantStatus)
+			}
+			for name, want := range map[string]string{
+				"Access-Control-Allow-Origin":      test.wantAllowOrigin,
+				"Access-Control-Allow-Methods":     test.wantMethods,
+				"Access-Control-Allow-Headers":     test.wantHeaders,
+				"Access-Control-Allow-Credentials": test.wantCredentials,
+				"Access-Control-Expose-Headers":    test.wantExposed,
+				"Access-Control-Max-Age":           test.wantMaxAge,
+			} {
+				if got := headers.Get(name); got != want {
+					t.Errorf("%s : %q, attendu %q", name, got, want)
+				}
+			}
+			if varyOrigin := headers.Get("Vary") == "Origin"; varyOrigin != test.wantVaryOrigin {
+				t.Errorf("Vary : %q", headers.Values("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	idempotencyKeyMaxLength = 255
+	idempotencyStaleAfter   = 5 * time.Minute // requête restée sans réponse (processus arrêté) : la clé est de nouveau utilisable
+)
+
+// En-têtes de la réponse conservés et rejoués avec le corps
+var idempotencyHeaders = []string{"Content-Type", "Content-Language", "Content-Disposition", "ETag", "Location"}
+
+/*
+En-tête Idempotency-Key sur une route qui modifie des données, à placer après AuthMiddleware et la limite du corps
+- Première requête : traitée normalement, sa réponse est conservée pendant `ttl` (sauf erreur 5xx : la requête peut être retentée)
+- Même clé, même requête (méthode, chemin et corps identiques) : la réponse conservée est renvoyée avec Idempotent-Replayed: true
+- Même clé, autre requête : 422 idempotency_key_reused
+- Même clé pendant le traitement de la première requête : 409 idempotency_key_in_progress
+Les clés sont propres à chaque compte (à l'adresse IP sans compte connecté). Sans en-tête, la requête est traitée normalement
+*/
+func IdempotencyMiddleware(ttl time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return idempotency(ttl, nil)
+}
+
+/*
+Variante pour les routes d'authentification et de mot de passe : leurs réponses (tokens, codes de secours) ne sont jamais conservées
+- Seule une réponse 2xx réserve la clé, sans son corps : la même requête répétée reçoit 409 idempotency_key_processed
+- Ce refus est voulu : rejouer la réponse obligerait à conserver des tokens en base, le client recommence avec une nouvelle clé
+- L'empreinte de la requête est signée avec `secret` (HMAC-SHA256) : un mot de passe du corps ne peut pas être retrouvé depuis la base
+*/
+func IdempotencyWithoutReplayMiddleware(ttl time.Duration, secret string) func(http.HandlerFunc) http.HandlerFunc {
+	key := sha256.Sum256([]byte("waitify-idempotency:" + secret))
+	return idempotency(ttl, key[:])
+}
+
+// Sans `fingerprintKey`, la réponse est conservée et rejouée ; avec, seul le statut d'une requête réussie est conservé
+func idempotency(ttl time.Duration, fingerprintKey []byte) func(http.HandlerFunc) http.HandlerFunc {
+	replay := fingerprintKey == nil
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !validIdempotencyKey(key) {
+				utils.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidParameter, "error.invalid_parameter",
+					models.FieldError{Field: "Idempotency-Key", Code: "invalid_format", Message: "idempotency.key_invalid", Args: []any{idempotencyKeyMaxLength}})
+				return
+			}
+
+			// Portée de la clé : le compte connecté, sinon l'adresse IP du client (routes d'authentification)
+			var userID *uuid.UUID
+			var scope string
+			if claims := utils.ClaimsFromContext(r.Context()); claims != nil {
+				userID = &claims.UserID
+				scope = claims.UserID.String()
+			} else {
+				sum := sha256.Sum256([]byte(utils.ClientIP(r)))
+				scope = "ip:" + hex.EncodeToString(sum[:])
+			}
+
+			// Empreinte de la requête (corps relu ensuite par le handler)
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.WriteBodyError(w, r, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := idempotencyFingerprint(r, body, fingerprintKey)
+
+			// Réserver la clé (une clé expirée, ou restée sans réponse, est réutilisable)
+			var claimed bool
+			err = database.DB.QueryRowContext(r.Context(), `
+				INSERT INTO idempotency_keys (scope, UserId, idempotency_key, method, path, fingerprint, expires_at)
+				VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(secs => $7))
+				ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+					method = EXCLUDED.method, path = EXCLUDED.path, fingerprint = EXCLUDED.fingerprint,
+					status_code = NULL, response_headers = NULL, response_body = NULL,
+					created_at = NOW(), expires_at = EXCLUDED.expires_at
+				WHERE idempotency_keys.expires_at <= NOW()
+				OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < NOW() - make_interval(secs => $8))
+				RETURNING true`,
+				scope, userID, key, r.Method, r.URL.Path, fingerprint, ttl.Seconds(), idempotencyStaleAfter.Seconds()).Scan(&claimed)
+			if err == sql.ErrNoRows {
+				replayIdempotent(w, r, scope, key, fingerprint, replay)
+				return
+			}
+			if err != nil {
+				utils.WriteInternalError(w, r, "idempotencyMiddleware.go -> IdempotencyMiddleware()", err)
+				return
+			}
+
+			// La réponse est enregistrée même si le client s'est déconnecté entre-temps (c'est justement le cas d'une nouvelle tentative)
+			ctx := context.WithoutCancel(r.Context())
+			stored := false
+			defer func() {
+				if !stored {
+					database.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2", scope, key)
+				}
+			}()
+
+			rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK, discard: !replay}
+			next.ServeHTTP(rec, r)
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+
+			// Sans rejeu : seule une requête réussie réserve la clé, sans en-têtes ni corps
+			if !replay {
+				if rec.status < http.StatusOK || rec.status >= http.StatusMultipleChoices {
+					return
+				}
+				_, err = database.DB.ExecContext(ctx, "UPDATE idempotency_keys SET status_code = $3 WHERE scope = $1 AND idempotency_key = $2",
+					scope, key, rec.status)
+				stored = err == nil
+				return
+			}
+
+			encoded, err := json.Marshal(idempotencyStoredHeaders(w.Header()))
+			if err != nil {
+				return
+			}
+			_, err = database.DB.ExecContext(ctx, `
+				UPDATE idempotency_keys SET status_code = $3, response_headers = $4, response_body = $5
+				WHERE scope = $1 AND idempotency_key = $2`,
+				scope, key, rec.status, string(encoded), rec.body.Bytes())
+			stored = err == nil
+		}
+	}
+}
+
+/*
+Empreinte d'une requête : méthode, chemin (avec les paramètres) et corps, signée avec `key` (HMAC-SHA256) si elle est fournie
+Formulaire multipart : champs, noms de fichiers et empreinte de leur contenu, sans la frontière (boundary) choisie au hasard
+par le client à chaque envoi. Un envoi répété du même formulaire (photo, logo) a ainsi la même empreinte
+*/
+func idempotencyFingerprint(r *http.Request, body, key []byte) string {
+	hash := sha256.New()
+	if key != nil {
+		hash = hmac.New(sha256.New, key)
+	}
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		if parts, err := multipartFingerprint(body, params["boundary"]); err == nil {
+			fmt.Fprintf(hash, "%s\n", mediaType)
+			for _, part := range parts {
+				fmt.Fprintln(hash, part)
+			}
+			return hex.EncodeToString(hash.Sum(nil))
+		}
+	}
+
+	// Autre corps, ou formulaire illisible (le handler le refusera) : octets bruts
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Parties d'un formulaire multipart, triées : nom du champ, nom du fichier, type et SHA-256 du contenu
+func multipartFingerprint(body []byte, boundary string) ([]string, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.New()
+		if _, err := io.Copy(sum, part); err != nil {
+			return nil, err
+		}
+		parts = append(parts, fmt.Sprintf("%q %q %q %x", part.FormName(), part.FileName(), part.Header.Get("Content-Type"), sum.Sum(nil)))
+	}
+	slices.Sort(parts)
+	return parts, nil
+}
+
+// En-têtes de la réponse conservés avec son corps (cf. idempotencyHeaders)
+func idempotencyStoredHeaders(header http.Header) http.Header {
+	stored := http.Header{}
+	for _, name := range idempotencyHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			stored[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return stored
+}
+
+// Requête déjà reçue avec cette clé : réponse conservée, ou refus si la requête diffère, n'a pas encore de réponse ou n'est pas rejouable
+func replayIdempotent(w http.ResponseWriter, r *http.Request, scope, key, fingerprint string, replay bool) {
+	var storedFingerprint string
+	var status sql.NullInt64
+	var headers, body []byte
+	err := database.DB.QueryRowContext(r.Context(), `
+		SELECT fingerprint, status_code, response_headers, response_body
+		FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`,
+		scope, key).Scan(&storedFingerprint, &status, &headers, &body)
+	if err != nil && err != sql.ErrNoRows {
+		utils.WriteInternalError(w, r, "idempotencyMiddleware.go -> replayIdempotent()", err)
+		return
+	}
+
+	if err == nil && storedFingerprint != fingerprint {
+		utils.WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeKeyReused, "idempotency.key_reused")
+		return
+	}
+	// Première requête en cours, ou clé libérée entre-temps après une erreur : le client peut réessayer
+	if !status.Valid {
+		w.Header().Set("Retry-After", "1")
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeKeyInProgress, "idempotency.in_progress")
+		return
+	}
+	if !replay {
+		utils.WriteError(w, r, http.StatusConflict, models.ErrCodeKeyProcessed, "idempotency.processed")
+		return
+	}
+
+	var stored http.Header
+	if err := json.Unmarshal(headers, &stored); err != nil {
+		utils.WriteInternalError(w, r, "idempotencyMiddleware.go -> replayIdempotent()", err)
+		return
+	}
+	for name, values := range stored {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(int(status.Int64))
+	w.Write(body)
+}
+
+// Clé fournie par le client : caractères ASCII imprimables (UUID, ULID...)
+func validIdempotencyKey(key string) bool {
+	if len(key) > idempotencyKeyMaxLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// ResponseWriter qui retient le code HTTP et une copie du corps de la réponse (sauf avec `discard`)
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	discard     bool
+	body        bytes.Buffer
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	if !rec.discard {
+		rec.body.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Nécessaire pour http.ResponseController (Flush, SetWriteDeadline...)
+func (rec *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// Tâche de fond : supprimer les réponses conservées expirées
+func CleanupIdempotencyKeys(ctx context.Context) error {
+	if _, err := database.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < NOW()"); err != nil {
+		return fmt.Errorf("[idempotencyMiddleware.go -> CleanupIdempotencyKeys()] -> %w", err)
+	}
+	return nil
+}
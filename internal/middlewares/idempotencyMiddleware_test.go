@@ -0,0 +1,236 @@
+package middlewares
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"UUID", "3f0c4b8e-7a1d-4c52-9a8f-2d6e1b0c9f47", true},
+		{"ULID", "01HZX3J6K8Q2W5E7R9T1Y3U5I7", true},
+		{"ASCII imprimable", "!~abc_DEF.123", true},
+		{"longueur maximum", strings.Repeat("a", idempotencyKeyMaxLength), true},
+		{"trop longue", strings.Repeat("a", idempotencyKeyMaxLength+1), false},
+		{"espace", "cle avec espace", false},
+		{"tabulation", "cle\t1", false},
+		{"non ASCII", "clé", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := validIdempotencyKey(test.key); got != test.want {
+				t.Fatalf("validIdempotencyKey(%q) = %v, attendu %v", test.key, got, test.want)
+			}
+		})
+	}
+}
+
+// Sans clé, la requête est traitée normalement ; une clé invalide est refusée avant toute lecture de la base
+func TestIdempotencyMiddlewareKeyHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantCalled bool
+	}{
+		{"sans clé", "", http.StatusCreated, true},
+		{"clé invalide", "clé invalide", http.StatusBadRequest, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called := false
+			handler := IdempotencyMiddleware(0)(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusCreated)
+			})
+			request := httptest.NewRequest(http.MethodPost, "/business", strings.NewReader(`{}`))
+			if test.key != "" {
+				request.Header.Set("Idempotency-Key", test.key)
+			}
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+			if recorder.Code != test.wantStatus || called != test.wantCalled {
+				t.Fatalf("statut %d, handler appelé : %v", recorder.Code, called)
+			}
+		})
+	}
+}
+
+// Formulaire multipart avec la frontière donnée, champs dans l'ordre donné ([nom, nom du fichier, contenu])
+func multipartRequest(t *testing.T, path, boundary string, fields ...[3]string) (*http.Request, []byte) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range fields {
+		var part interface{ Write([]byte) (int, error) }
+		var err error
+		if field[1] != "" {
+			part, err = writer.CreateFormFile(field[0], field[1])
+		} else {
+			part, err = writer.CreateFormField(field[0])
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(field[2]))
+	}
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body.Bytes()))
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request, body.Bytes()
+}
+
+func jsonRequest(method, path, body string) (*http.Request, []byte) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	return request, []byte(body)
+}
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	logo := [3]string{"file", "logo.png", "\x89PNG..."}
+	other := [3]string{"file", "logo.png", "\x89PNG autre image"}
+	title := [3]string{"title", "", "Boulangerie"}
+	secret := []byte("secret")
+
+	formFingerprint := func(path, boundary string, key []byte, fields ...[3]string) string {
+		request, body := multipartRequest(t, path, boundary, fields...)
+		return idempotencyFingerprint(request, body, key)
+	}
+	jsonFingerprint := func(method, path, body string, key []byte) string {
+		request, raw := jsonRequest(method, path, body)
+		return idempotencyFingerprint(request, raw, key)
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"même formulaire, autre frontière",
+			formFingerprint("/users/me/avatar", "frontiere-a", nil, logo),
+			formFingerprint("/users/me/avatar", "frontiere-b", nil, logo), true},
+		{"champs dans un autre ordre",
+			formFingerprint("/users/me/avatar", "frontiere-a", nil, logo, title),
+			formFingerprint("/users/me/avatar", "frontiere-b", nil, title, logo), true},
+		{"autre fichier",
+			formFingerprint("/users/me/avatar", "frontiere-a", nil, logo),
+			formFingerprint("/users/me/avatar", "frontiere-a", nil, other), false},
+		{"autre chemin",
+			formFingerprint("/users/me/avatar", "frontiere-a", nil, logo),
+			formFingerprint("/business/1/logo", "frontiere-a", nil, logo), false},
+		{"HMAC, même formulaire, autre frontière",
+			formFingerprint("/users/me/avatar", "frontiere-a", secret, logo),
+			formFingerprint("/users/me/avatar", "frontiere-b", secret, logo), true},
+		{"même corps JSON",
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"email":"a@b.fr"}`, nil),
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"email":"a@b.fr"}`, nil), true},
+		{"corps JSON différent",
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"email":"a@b.fr"}`, nil),
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"email":"c@d.fr"}`, nil), false},
+		{"autre méthode",
+			jsonFingerprint(http.MethodPut, "/business/1", `{}`, nil),
+			jsonFingerprint(http.MethodPatch, "/business/1", `{}`, nil), false},
+		{"paramètres de la query string",
+			jsonFingerprint(http.MethodPost, "/business/1/qrcode?size=256", `{}`, nil),
+			jsonFingerprint(http.MethodPost, "/business/1/qrcode?size=512", `{}`, nil), false},
+		{"HMAC différent du SHA-256 simple",
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"password":"Secret-123"}`, nil),
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"password":"Secret-123"}`, secret), false},
+		{"HMAC avec une autre clé",
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"password":"Secret-123"}`, secret),
+			jsonFingerprint(http.MethodPost, "/auth/login", `{"password":"Secret-123"}`, []byte("autre")), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if same := test.a == test.b; same != test.same {
+				t.Fatalf("empreintes %s et %s identiques : %v, attendu %v", test.a, test.b, same, test.same)
+			}
+		})
+	}
+}
+
+// Formulaire illisible : empreinte des octets bruts (le handler refusera la requête)
+func TestIdempotencyFingerprintInvalidMultipart(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/users/me/avatar", nil)
+	request.Header.Set("Content-Type", "multipart/form-data; boundary=frontiere")
+	a := idempotencyFingerprint(request, []byte("pas un formulaire"), nil)
+	b := idempotencyFingerprint(request, []byte("pas un formulaire non plus"), nil)
+	if a == b {
+		t.Fatal("corps différents, empreintes identiques")
+	}
+}
+
+func TestIdempotencyStoredHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Location", "/business/1")
+	header.Set("ETag", `"abc"`)
+	header.Add("content-language", "fr")
+	header.Set("Set-Cookie", "session=1")
+	header.Set("X-Request-Id", "req-1")
+	header.Set("Retry-After", "1")
+
+	want := http.Header{
+		"Content-Type":     {"application/json"},
+		"Content-Language": {"fr"},
+		"Etag":             {`"abc"`},
+		"Location":         {"/business/1"},
+	}
+	if got := idempotencyStoredHeaders(header); !reflect.DeepEqual(got, want) {
+		t.Fatalf("en-têtes conservés %v, attendu %v", got, want)
+	}
+	if got := idempotencyStoredHeaders(http.Header{}); len(got) != 0 {
+		t.Fatalf("en-têtes conservés %v pour une réponse sans en-tête", got)
+	}
+}
+
+func TestIdempotencyRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		discard    bool
+		write      func(w http.ResponseWriter)
+		wantStatus int
+		wantBody   string
+	}{
+		{"statut implicite", false, func(w http.ResponseWriter) { w.Write([]byte("ok")) }, http.StatusOK, "ok"},
+		{"premier statut retenu", false, func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusCreated)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("créé"))
+		}, http.StatusCreated, "créé"},
+		{"statut après le corps ignoré", false, func(w http.ResponseWriter) {
+			w.Write([]byte("ok"))
+			w.WriteHeader(http.StatusInternalServerError)
+		}, http.StatusOK, "ok"},
+		{"corps non conservé", true, func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"token":"..."}`))
+		}, http.StatusOK, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			rec := &idempotencyRecorder{ResponseWriter: recorder, status: http.StatusOK, discard: test.discard}
+			test.write(rec)
+			if rec.status != test.wantStatus || rec.body.String() != test.wantBody {
+				t.Fatalf("statut %d et corps %q retenus, attendu %d et %q", rec.status, rec.body.String(), test.wantStatus, test.wantBody)
+			}
+			if recorder.Body.Len() == 0 {
+				t.Fatal("corps non transmis au client")
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/i18n"
+)
+
+// Langue des messages de l'API choisie à partir du header "Accept-Language"
+func LanguageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Match(r.Header.Get("Accept-Language"))
+
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLanguage(r.Context(), lang)))
+	})
+}
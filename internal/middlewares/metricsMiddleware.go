@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/telemetry"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+Métriques HTTP (latence et code de statut par route) et nommage du span de la requête
+À placer juste autour du routeur, comme AccessLogMiddleware, pour disposer de r.Pattern
+*/
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r)
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		route := routeLabel(r.Pattern)
+		telemetry.ObserveHTTPRequest(r.Method, route, recorder.status, time.Since(start))
+
+		// Le span est créé avant le routage : on lui donne le nom de la route une fois celle-ci connue
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	})
+}
+
+// "GET /business/{id}" -> "/business/{id}" ; aucune route trouvée -> "unmatched"
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	if _, route, found := strings.Cut(pattern, " "); found {
+		return route
+	}
+	return pattern
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Réponses JSON : rien ne doit être chargé ni exécuté
+	apiContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'; base-uri 'none'"
+
+	// Pages HTML (index.html, écran d'affichage...) : ressources du même domaine, styles inline autorisés
+	htmlContentSecurityPolicy = "default-src 'self'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'"
+)
+
+/*
+Headers de sécurité sur toutes les réponses
+- X-Content-Type-Options, X-Frame-Options, Referrer-Policy
+- Strict-Transport-Security si la requête est arrivée en HTTPS et si `hstsMaxAge` > 0 (X-Forwarded-Proto du load balancer : seulement avec `trustProxyHeaders`)
+- Content-Security-Policy adaptée au type de contenu (JSON ou HTML), sauf si le handler en a déjà défini une
+*/
+func SecurityHeadersMiddleware(hstsMaxAge time.Duration, trustProxyHeaders bool) func(http.Handler) http.Handler {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(hstsMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers := w.Header()
+			headers.Set("X-Content-Type-Options", "nosniff")
+			headers.Set("X-Frame-Options", "DENY")
+			headers.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			if hsts != "" && (r.TLS != nil || (trustProxyHeaders && r.Header.Get("X-Forwarded-Proto") == "https")) {
+				headers.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(&cspWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// ResponseWriter qui choisit la CSP au moment d'envoyer les headers, quand le Content-Type est connu
+type cspWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (cw *cspWriter) WriteHeader(status int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		headers := cw.Header()
+		if headers.Get("Content-Security-Policy") == "" {
+			if strings.HasPrefix(headers.Get("Content-Type"), "text/html") {
+				headers.Set("Content-Security-Policy", htmlContentSecurityPolicy)
+			} else {
+				headers.Set("Content-Security-Policy", apiContentSecurityPolicy)
+			}
+		}
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *cspWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		// Sans Content-Type explicite, net/http le déduit du contenu : on fait de même pour choisir la CSP
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *cspWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *cspWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/database"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/StevenYAMBOS/waitify-api/internal/utils"
+)
+
+/*
+Routes d'une entreprise ({id}) : si son propriétaire s'impose la double authentification, le token de session doit l'inclure
+Seul le propriétaire gère une entreprise (pas de comptes employés) : l'exigence ne concerne que ses propres sessions
+À placer après AuthMiddleware. Identifiant invalide : 400 (WriteDBError). Entreprise introuvable : le handler répond lui-même
+*/
+func TwoFactorPolicyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims := utils.ClaimsFromContext(r.Context())
+		if claims == nil {
+			utils.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "auth.authorization_required")
+			return
+		}
+		// Pas d'entreprise dans l'URL (création) ou session déjà en double authentification
+		if claims.HasOTP() || r.PathValue("id") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var required bool
+		err := database.DB.QueryRowContext(r.Context(), "SELECT require_two_factor FROM businesses WHERE id = $1", r.PathValue("id")).Scan(&required)
+		if err != nil && err != sql.ErrNoRows {
+			utils.WriteDBError(w, r, "twoFactorMiddleware.go -> TwoFactorPolicyMiddleware()", err)
+			return
+		}
+		if required {
+			utils.WriteError(w, r, http.StatusForbidden, models.ErrCodeTwoFactorRequired, "2fa.required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
@@ -2,63 +2,95 @@ package models
 
 import (
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"regexp"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Business struct {
-	ID                      uuid.UUID `json:"id" db:"id"`
-	UserID                  uuid.UUID `json:"UserId" db:"UserId"`
-	Name                    string    `json:"name" db:"name"`
-	BusinessType            string    `json:"business_type" db:"business_type"`
-	PhoneNumber             string    `json:"phone_number" db:"phone_number"`
-	Address                 string    `json:"address" db:"address"`
-	City                    string    `json:"city" db:"city"`
-	ZipCode                 string    `json:"zip_code" db:"zip_code"`
-	Country                 string    `json:"country" db:"country"`
-	QRCodeToken             string    `json:"qr_code_token" db:"qr_code_token"`
-	AverageServiceTime      int       `json:"average_service_time" db:"average_service_time"`
-	IsQueueActive           bool      `json:"is_queue_active" db:"is_queue_active"`
-	IsQueuePaused           bool      `json:"is_queue_paused" db:"is_queue_paused"`
-	MaxQueueSize            int       `json:"max_queue_size" db:"max_queue_size"`
-	OpeningHours            string    `json:"opening_hours" db:"opening_hours"`
-	CustomMessage           string    `json:"custom_message" db:"custom_message"`
-	SmsNotificationsEnabled bool      `json:"sms_notifications_enabled" db:"sms_notifications_enabled"`
-	AutoAdvanceEnabled      bool      `json:"auto_advance_enabled" db:"auto_advance_enabled"`
-	ClientTimeoutMinutes    int       `json:"client_timeout_minutes" db:"client_timeout_minutes"`
-	IsActive                int       `json:"is_active" db:"is_active"`
-	CreatedAt               time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt               time.Time `json:"updated_at" db:"updated_at"`
+	ID                      uuid.UUID    `json:"id" db:"id"`
+	UserID                  uuid.UUID    `json:"UserId" db:"UserId"`
+	Name                    string       `json:"name" db:"name"`
+	BusinessType            string       `json:"business_type" db:"business_type"`
+	PhoneNumber             string       `json:"phone_number" db:"phone_number"`
+	Address                 string       `json:"address" db:"address"`
+	City                    string       `json:"city" db:"city"`
+	ZipCode                 string       `json:"zip_code" db:"zip_code"`
+	Country                 string       `json:"country" db:"country"`
+	QRCodeToken             string       `json:"qr_code_token" db:"qr_code_token"`
+	AverageServiceTime      int          `json:"average_service_time" db:"average_service_time"`
+	IsQueueActive           bool         `json:"is_queue_active" db:"is_queue_active"`
+	IsQueuePaused           bool         `json:"is_queue_paused" db:"is_queue_paused"`
+	MaxQueueSize            int          `json:"max_queue_size" db:"max_queue_size"`
+	OpeningHours            OpeningHours `json:"opening_hours" db:"opening_hours"`
+	CustomMessage           string       `json:"custom_message" db:"custom_message"`
+	SmsNotificationsEnabled bool         `json:"sms_notifications_enabled" db:"sms_notifications_enabled"`
+	AutoAdvanceEnabled      bool         `json:"auto_advance_enabled" db:"auto_advance_enabled"`
+	ClientTimeoutMinutes    int          `json:"client_timeout_minutes" db:"client_timeout_minutes"`
+	DefaultLanguage         string       `json:"default_language" db:"default_language"`
+	TicketPrefix            string       `json:"ticket_prefix" db:"ticket_prefix"` // préfixe des numéros de ticket (ex : "A"), vide sans préfixe
+	Logo                    string       `json:"logo_url,omitempty" db:"logo"`     // clé du stockage en base, lien signé dans les réponses
+	IsActive                int          `json:"is_active" db:"is_active"`
+	CreatedAt               time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time    `json:"updated_at" db:"updated_at"`
+	DeletedAt               *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"` // entreprise supprimée, restaurable jusqu'à la purge
 }
 
 type UpdatedBusiness struct {
-	ID                      *uuid.UUID `json:"id" db:"id"`
-	UserID                  *uuid.UUID `json:"UserId" db:"UserId"`
-	Name                    *string    `json:"name" db:"name"`
-	BusinessType            *string    `json:"business_type" db:"business_type"`
-	PhoneNumber             *string    `json:"phone_number" db:"phone_number"`
-	Address                 *string    `json:"address" db:"address"`
-	City                    *string    `json:"city" db:"city"`
-	ZipCode                 *string    `json:"zip_code" db:"zip_code"`
-	Country                 *string    `json:"country" db:"country"`
-	QRCodeToken             *string    `json:"qr_code_token" db:"qr_code_token"`
-	AverageServiceTime      *int       `json:"average_service_time" db:"average_service_time"`
-	IsQueueActive           *bool      `json:"is_queue_active" db:"is_queue_active"`
-	IsQueuePaused           *bool      `json:"is_queue_paused" db:"is_queue_paused"`
-	MaxQueueSize            *int       `json:"max_queue_size" db:"max_queue_size"`
-	OpeningHours            *string    `json:"opening_hours" db:"opening_hours"`
-	CustomMessage           *string    `json:"custom_message" db:"custom_message"`
-	SmsNotificationsEnabled *bool      `json:"sms_notifications_enabled" db:"sms_notifications_enabled"`
-	AutoAdvanceEnabled      *bool      `json:"auto_advance_enabled" db:"auto_advance_enabled"`
-	ClientTimeoutMinutes    *int       `json:"client_timeout_minutes" db:"client_timeout_minutes"`
-	IsActive                *int       `json:"is_active" db:"is_active"`
-	CreatedAt               *time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt               *time.Time `json:"updated_at" db:"updated_at"`
+	ID                      *uuid.UUID    `json:"id" db:"id"`
+	UserID                  *uuid.UUID    `json:"UserId" db:"UserId"`
+	Name                    *string       `json:"name" db:"name"`
+	BusinessType            *string       `json:"business_type" db:"business_type"`
+	PhoneNumber             *string       `json:"phone_number" db:"phone_number"`
+	Address                 *string       `json:"address" db:"address"`
+	City                    *string       `json:"city" db:"city"`
+	ZipCode                 *string       `json:"zip_code" db:"zip_code"`
+	Country                 *string       `json:"country" db:"country"`
+	QRCodeToken             *string       `json:"qr_code_token" db:"qr_code_token"`
+	AverageServiceTime      *int          `json:"average_service_time" db:"average_service_time"`
+	IsQueueActive           *bool         `json:"is_queue_active" db:"is_queue_active"`
+	IsQueuePaused           *bool         `json:"is_queue_paused" db:"is_queue_paused"`
+	MaxQueueSize            *int          `json:"max_queue_size" db:"max_queue_size"`
+	OpeningHours            *OpeningHours `json:"opening_hours" db:"opening_hours"`
+	CustomMessage           *string       `json:"custom_message" db:"custom_message"`
+	SmsNotificationsEnabled *bool         `json:"sms_notifications_enabled" db:"sms_notifications_enabled"`
+	AutoAdvanceEnabled      *bool         `json:"auto_advance_enabled" db:"auto_advance_enabled"`
+	ClientTimeoutMinutes    *int          `json:"client_timeout_minutes" db:"client_timeout_minutes"`
+	DefaultLanguage         *string       `json:"default_language" db:"default_language"`
+	TicketPrefix            *string       `json:"ticket_prefix" db:"ticket_prefix"`
+	IsActive                *int          `json:"is_active" db:"is_active"`
+	CreatedAt               *time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt               *time.Time    `json:"updated_at" db:"updated_at"`
 }
 
+// Horaires d'ouverture par jour ("monday" ... "sunday"), colonne JSONB
+type OpeningHours map[string]OpeningDay
+
+type OpeningDay struct {
+	Open   string `json:"open,omitempty"`  // HH:MM
+	Close  string `json:"close,omitempty"` // HH:MM
+	Closed bool   `json:"closed"`
+}
+
+var OpeningDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Bornes des paramètres de la file d'attente (cf. contraintes de la table businesses)
+const (
+	BusinessNameMaxLength          = 255
+	BusinessCountryMaxLength       = 50
+	BusinessCustomMessageMaxLength = 280 // message repris dans les SMS et sur l'affiche
+	BusinessMinServiceTime         = 30  // secondes
+	BusinessMaxServiceTime         = 4 * 60 * 60
+	BusinessMinQueueSize           = 1
+	BusinessMaxQueueSize           = 200
+	BusinessMinClientTimeout       = 1 // minutes
+	BusinessMaxClientTimeout       = 30
+)
+
 // État des files d'attente du commerce
 type BusinessQueueStatusRequest struct {
 	IsQueueActive *bool `json:"is_queue_active" db:"is_queue_active"`
@@ -66,50 +98,12 @@ type BusinessQueueStatusRequest struct {
 
 /* ********************* Vérifications ********************* */
 
-// Vérifier le type de commerce
-func ValidateBusinessType(businessType string) error {
-	var types [35]string = [35]string{"bakery", "hairdresser", "pharmacy", "garage", "restaurant",
-		"medical_office", "dentist", "veterinary", "optician", "bank",
-		"insurance", "notary", "lawyer", "accountant", "real_estate",
-		"prefecture", "city_hall", "family_allowance", "employment_agency", "public_service",
-		"post_office", "dry_cleaning", "cobbler", "watchmaker", "phone_repair",
-		"beauty_salon", "massage", "tattoo", "nail_salon", "barber",
-		"vehicle_inspection", "gas_station", "auto_body", "tire_service",
-		"other"}
-
-	for _, value := range types {
-		if businessType != value {
-			log.Println("Le type de commerce n'existe pas.")
-			continue
-			// return errors.New("Le type de commerce n'existe pas.")
-		} else {
-			log.Println("Le type de commerce EST BON.")
-			break
-		}
-	}
-	return nil
-}
+var ErrBusinessTypeInvalid = errors.New("[businessModels.go] -> Type de commerce inconnu")
 
-// Vérifier le type de commerce
-func (business *Business) ValidateType() error {
-	var types [35]string = [35]string{"bakery", "hairdresser", "pharmacy", "garage", "restaurant",
-		"medical_office", "dentist", "veterinary", "optician", "bank",
-		"insurance", "notary", "lawyer", "accountant", "real_estate",
-		"prefecture", "city_hall", "family_allowance", "employment_agency", "public_service",
-		"post_office", "dry_cleaning", "cobbler", "watchmaker", "phone_repair",
-		"beauty_salon", "massage", "tattoo", "nail_salon", "barber",
-		"vehicle_inspection", "gas_station", "auto_body", "tire_service",
-		"other"}
-
-	for _, value := range types {
-		if business.BusinessType != value {
-			log.Println("[businessModels.go -> ValidateBusinessType()] -> Le type de commerce n'existe pas.")
-			continue
-			// return errors.New("[businessModels.go -> ValidateBusinessType()] -> Le type de commerce n'existe pas.")
-		} else {
-			log.Println("Le type de commerce EST BON.")
-			break
-		}
+// Vérifier le type de commerce (cf. BusinessTypes)
+func ValidateBusinessType(businessType string) error {
+	if _, ok := LookupBusinessType(businessType); !ok {
+		return ErrBusinessTypeInvalid
 	}
 	return nil
 }
@@ -122,7 +116,7 @@ func ValidateBusinessPhoneNumber(phoneNumber string) error {
 
 	re := regexp.MustCompile(`^(?:(?:\(?(?:00|\+)([1-4]\d\d|[1-9]\d?)\)?)?[\-\.\ \\\/]?)?((?:\(?\d{1,}\)?[\-\.\ \\\/]?){0,})(?:[\-\.\ \\\/]?(?:#|ext\.?|extension|x)[\-\.\ \\\/]?(\d+))?$`)
 	if !re.MatchString(phoneNumber) {
-		log.Println("Le numéro de téléphone n'est pas au format valide : ", phoneNumber)
+		slog.Debug("Le numéro de téléphone n'est pas au format valide")
 		return errors.New("Le numéro de téléphone n'est pas au format valide.")
 	}
 	return nil
@@ -136,12 +130,36 @@ func (business *Business) ValidatePhoneNumber() error {
 
 	re := regexp.MustCompile(`^(?:(?:\(?(?:00|\+)([1-4]\d\d|[1-9]\d?)\)?)?[\-\.\ \\\/]?)?((?:\(?\d{1,}\)?[\-\.\ \\\/]?){0,})(?:[\-\.\ \\\/]?(?:#|ext\.?|extension|x)[\-\.\ \\\/]?(\d+))?$`)
 	if !re.MatchString(business.PhoneNumber) {
-		log.Println("[businessModels.go] -> Phone number is not valid:", business.PhoneNumber)
+		slog.Debug("Le numéro de téléphone n'est pas au format valide")
 		return errors.New("[businessModels.go -> ValidatePhoneNumber()] -> Le numéro de téléphone n'est pas au format valide.")
 	}
 	return nil
 }
 
+// Horaires valides : jours connus, heures HH:MM et ouverture avant la fermeture (sauf jour fermé)
+func (hours OpeningHours) Validate() error {
+	for day, opening := range hours {
+		if !slices.Contains(OpeningDays, day) {
+			return fmt.Errorf("jour inconnu %q", day)
+		}
+		if opening.Closed {
+			continue
+		}
+		open, err := time.Parse("15:04", opening.Open)
+		if err != nil {
+			return fmt.Errorf("%s : heure d'ouverture invalide %q", day, opening.Open)
+		}
+		closing, err := time.Parse("15:04", opening.Close)
+		if err != nil {
+			return fmt.Errorf("%s : heure de fermeture invalide %q", day, opening.Close)
+		}
+		if !open.Before(closing) {
+			return fmt.Errorf("%s : l'ouverture doit précéder la fermeture", day)
+		}
+	}
+	return nil
+}
+
 type AddBusinessResponse struct {
 	Response string   `json:"Response"`
 	Business Business `json:"Business"`
@@ -153,7 +171,24 @@ type AddBusinessQRCodeResponse struct {
 	Business Business `json:"Business"`
 }
 
+// Entreprise supprimée (DELETE /business/{id}) ou restaurée (POST /businesses/{id}/restore)
+type DeleteBusinessResponse struct {
+	Message string    `json:"message"`
+	PurgeAt time.Time `json:"purge_at"` // suppression définitive, restauration possible jusque-là
+}
+
+type RestoreBusinessResponse struct {
+	Message  string   `json:"message"`
+	Business Business `json:"business"`
+}
+
+// Logo enregistré (POST /businesses/{id}/logo)
+type BusinessLogoResponse struct {
+	Message string `json:"message"`
+	LogoURL string `json:"logo_url"`
+}
+
 type UpdateBusinessResponse struct {
-	Response string           `json:"Response"`
-	Business *UpdatedBusiness `json:"Business"`
+	Response string   `json:"Response"`
+	Business Business `json:"Business"`
 }
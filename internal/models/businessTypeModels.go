@@ -0,0 +1,79 @@
+package models
+
+/*
+Types de commerce : référence unique de l'API (validation, valeurs par défaut, GET /business-types)
+Libellés traduits dans internal/i18n (clés "business_type.<code>")
+La contrainte check_business_type de la table businesses reprend la même liste : un nouveau type passe aussi par une migration
+*/
+type BusinessType struct {
+	Code                 string `json:"code"`
+	Label                string `json:"label"`
+	AverageServiceTime   int    `json:"average_service_time"`   // secondes
+	ClientTimeoutMinutes int    `json:"client_timeout_minutes"` // minutes
+	MaxQueueSize         int    `json:"max_queue_size"`
+}
+
+// Valeurs appliquées à la création de l'entreprise, modifiables ensuite (PATCH /business/{id})
+var BusinessTypes = []BusinessType{
+	// Commerces de proximité
+	{Code: "bakery", AverageServiceTime: 90, ClientTimeoutMinutes: 3, MaxQueueSize: 30},
+	{Code: "pharmacy", AverageServiceTime: 300, ClientTimeoutMinutes: 5, MaxQueueSize: 40},
+	{Code: "restaurant", AverageServiceTime: 1200, ClientTimeoutMinutes: 10, MaxQueueSize: 50},
+	{Code: "post_office", AverageServiceTime: 300, ClientTimeoutMinutes: 5, MaxQueueSize: 60},
+	{Code: "dry_cleaning", AverageServiceTime: 180, ClientTimeoutMinutes: 5, MaxQueueSize: 30},
+	{Code: "cobbler", AverageServiceTime: 300, ClientTimeoutMinutes: 5, MaxQueueSize: 20},
+	{Code: "watchmaker", AverageServiceTime: 600, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+	{Code: "phone_repair", AverageServiceTime: 900, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+	{Code: "optician", AverageServiceTime: 900, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+
+	// Beauté et bien-être
+	{Code: "hairdresser", AverageServiceTime: 1800, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+	{Code: "barber", AverageServiceTime: 1200, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+	{Code: "beauty_salon", AverageServiceTime: 2700, ClientTimeoutMinutes: 10, MaxQueueSize: 15},
+	{Code: "nail_salon", AverageServiceTime: 2700, ClientTimeoutMinutes: 10, MaxQueueSize: 15},
+	{Code: "massage", AverageServiceTime: 3600, ClientTimeoutMinutes: 10, MaxQueueSize: 10},
+	{Code: "tattoo", AverageServiceTime: 3600, ClientTimeoutMinutes: 15, MaxQueueSize: 10},
+
+	// Santé
+	{Code: "medical_office", AverageServiceTime: 1200, ClientTimeoutMinutes: 10, MaxQueueSize: 30},
+	{Code: "dentist", AverageServiceTime: 1800, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+	{Code: "veterinary", AverageServiceTime: 1200, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+
+	// Services professionnels
+	{Code: "bank", AverageServiceTime: 600, ClientTimeoutMinutes: 5, MaxQueueSize: 40},
+	{Code: "insurance", AverageServiceTime: 900, ClientTimeoutMinutes: 10, MaxQueueSize: 30},
+	{Code: "notary", AverageServiceTime: 1800, ClientTimeoutMinutes: 15, MaxQueueSize: 15},
+	{Code: "lawyer", AverageServiceTime: 1800, ClientTimeoutMinutes: 15, MaxQueueSize: 15},
+	{Code: "accountant", AverageServiceTime: 1800, ClientTimeoutMinutes: 15, MaxQueueSize: 15},
+	{Code: "real_estate", AverageServiceTime: 1200, ClientTimeoutMinutes: 10, MaxQueueSize: 20},
+
+	// Services publics
+	{Code: "prefecture", AverageServiceTime: 900, ClientTimeoutMinutes: 10, MaxQueueSize: 150},
+	{Code: "city_hall", AverageServiceTime: 600, ClientTimeoutMinutes: 10, MaxQueueSize: 100},
+	{Code: "family_allowance", AverageServiceTime: 900, ClientTimeoutMinutes: 10, MaxQueueSize: 100},
+	{Code: "employment_agency", AverageServiceTime: 900, ClientTimeoutMinutes: 10, MaxQueueSize: 100},
+	{Code: "public_service", AverageServiceTime: 600, ClientTimeoutMinutes: 10, MaxQueueSize: 100},
+
+	// Automobile
+	{Code: "garage", AverageServiceTime: 1800, ClientTimeoutMinutes: 15, MaxQueueSize: 20},
+	{Code: "vehicle_inspection", AverageServiceTime: 2700, ClientTimeoutMinutes: 15, MaxQueueSize: 20},
+	{Code: "gas_station", AverageServiceTime: 300, ClientTimeoutMinutes: 5, MaxQueueSize: 30},
+	{Code: "auto_body", AverageServiceTime: 1800, ClientTimeoutMinutes: 15, MaxQueueSize: 20},
+	{Code: "tire_service", AverageServiceTime: 1800, ClientTimeoutMinutes: 15, MaxQueueSize: 20},
+
+	// Valeurs par défaut de la table businesses
+	{Code: "other", AverageServiceTime: 300, ClientTimeoutMinutes: 5, MaxQueueSize: 50},
+}
+
+func LookupBusinessType(code string) (BusinessType, bool) {
+	for _, businessType := range BusinessTypes {
+		if businessType.Code == code {
+			return businessType, true
+		}
+	}
+	return BusinessType{}, false
+}
+
+type BusinessTypesResponse struct {
+	BusinessTypes []BusinessType `json:"business_types"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Lien de l'écran d'affichage en magasin (GET /businesses/{id}/display)
+type DisplayTokenResponse struct {
+	Message    string `json:"message,omitempty"`
+	Token      string `json:"token"`
+	FeedPath   string `json:"feed_path"`   // données JSON, ou flux SSE avec Accept: text/event-stream
+	ScreenPath string `json:"screen_path"` // page HTML à ouvrir sur l'écran
+}
+
+/*
+Écran d'affichage (GET /display/{token}) : données minimales, sans nom complet ni téléphone
+Les clients sont désignés par leur numéro de ticket et les initiales de leur nom
+*/
+type DisplayBoard struct {
+	BusinessName      string          `json:"business_name"`
+	LogoURL           string          `json:"logo_url,omitempty"`
+	Language          string          `json:"language"`
+	IsQueueActive     bool            `json:"is_queue_active"`
+	IsQueuePaused     bool            `json:"is_queue_paused"`
+	NowServing        []DisplayTicket `json:"now_serving"` // clients appelés, le plus récent en premier
+	Next              []DisplayTicket `json:"next"`        // prochains clients en attente
+	WaitingCount      int             `json:"waiting_count"`
+	EstimatedWaitTime int             `json:"estimated_wait_time"` // en minutes, pour un client qui s'inscrit maintenant
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+type DisplayTicket struct {
+	Ticket   string     `json:"ticket"`
+	Initials string     `json:"initials"` // ex : "M. D."
+	CalledAt *time.Time `json:"called_at,omitempty"`
+	Desk     string     `json:"desk,omitempty"` // clients appelés : libellé du QR code scanné (ex : "Guichet 2"), s'il en a un
+}
@@ -0,0 +1,54 @@
+package models
+
+// Codes d'erreur stables, exploitables par le Front (ne jamais les renommer)
+const (
+	ErrCodeBadRequest         = "bad_request"
+	ErrCodeInvalidBody        = "invalid_body"
+	ErrCodePayloadTooLarge    = "payload_too_large"
+	ErrCodeInvalidParameter   = "invalid_parameter"
+	ErrCodeValidation         = "validation_failed"
+	ErrCodeUnauthorized       = "unauthorized"
+	ErrCodeInvalidToken       = "invalid_token"
+	ErrCodeInvalidCredentials = "invalid_credentials"
+	ErrCodeInvalidLink        = "invalid_link"
+	ErrCodeEmailNotVerified   = "email_not_verified"
+	ErrCodeTooManyAttempts    = "too_many_attempts"
+	ErrCodeInvalidOTP         = "invalid_otp"
+	ErrCodeTwoFactorRequired  = "two_factor_required"
+	ErrCodeReauthRequired     = "reauthentication_required"
+	ErrCodeForbidden          = "forbidden"
+	ErrCodeNotFound           = "not_found"
+	ErrCodeMethodNotAllowed   = "method_not_allowed"
+	ErrCodeConflict           = "conflict"
+	ErrCodePreconditionFailed = "precondition_failed"
+	ErrCodeEmailTaken         = "email_taken"
+	ErrCodeUnprocessable      = "unprocessable_entity"
+	ErrCodeQueueClosed        = "queue_closed"
+	ErrCodeQueueFull          = "queue_full"
+	ErrCodeAlreadyInQueue     = "already_in_queue"
+	ErrCodeKeyReused          = "idempotency_key_reused"
+	ErrCodeKeyInProgress      = "idempotency_key_in_progress"
+	ErrCodeKeyProcessed       = "idempotency_key_processed"
+	ErrCodeInternal           = "internal_error"
+)
+
+// Détail d'une erreur sur un champ précis de la requête
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Args    []any  `json:"-"` // paramètres du message (ex : longueur minimum)
+}
+
+// Erreur renvoyée par l'API
+type APIError struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Enveloppe JSON de toutes les réponses d'erreur : {"error": {...}}
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// Pagination d'une liste (cf. utils.ParseList)
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`                  // ex : "-created_at" (décroissant)
+	NextCursor string `json:"next_cursor,omitempty"` // à renvoyer dans `cursor` pour la page suivante, avec les mêmes filtres
+	HasMore    bool   `json:"has_more"`
+	Total      *int   `json:"total,omitempty"` // nombre total d'éléments filtrés, calculé sur la première page uniquement
+}
+
+// Enveloppe commune des listes paginées : {"data": [...], "pagination": {...}}
+type ListResponse[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
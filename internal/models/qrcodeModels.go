@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// États d'un token de QR code
+const (
+	QRTokenActive  = "active"  // scannable
+	QRTokenGrace   = "grace"   // remplacé, encore scannable jusqu'à expires_at
+	QRTokenExpired = "expired" // remplacé, délai de grâce écoulé
+	QRTokenRevoked = "revoked" // révoqué par le commerçant
+)
+
+// Token d'un QR code de l'entreprise (historique compris)
+type QRToken struct {
+	ID            uuid.UUID  `json:"id"`
+	Label         string     `json:"label,omitempty"` // ex : "Entrée principale"
+	TicketPrefix  *string    `json:"ticket_prefix"`   // préfixe des tickets des inscriptions par ce QR code, null : celui de l'entreprise
+	Token         string     `json:"token"`
+	URL           string     `json:"url"` // lien encodé dans le QR code
+	Primary       bool       `json:"primary"`
+	Status        string     `json:"status"`
+	ScanCount     int        `json:"scan_count"`
+	JoinCount     int        `json:"join_count"` // inscriptions dans la file depuis ce QR code
+	LastScannedAt *time.Time `json:"last_scanned_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	ReplacedBy    *uuid.UUID `json:"replaced_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type QRTokensResponse struct {
+	Tokens []QRToken `json:"tokens"`
+}
+
+// Corps JSON de l'ancienne route POST /business/{id}/qrcode/generate (mêmes paramètres que GET /businesses/{id}/qrcode)
+type QRCodeGenerateRequest struct {
+	Content string `json:"content"` // ignoré : le QR code encode toujours le lien de la file
+	Format  string `json:"format"`
+	Size    *int   `json:"size"`
+	Level   string `json:"level"`
+	Logo    *bool  `json:"logo"`
+	Paper   string `json:"paper"`
+	TokenID string `json:"token_id"`
+}
+
+// Nouveau QR code actif (en plus du principal)
+type QRTokenCreateRequest struct {
+	Label        string  `json:"label"`
+	TicketPrefix *string `json:"ticket_prefix"` // ex : "B" pour un service, absent : préfixe de l'entreprise
+}
+
+type QRTokenResponse struct {
+	Message string  `json:"message"`
+	Token   QRToken `json:"token"`
+}
+
+/*
+Remplacer un token (le principal si token_id est absent)
+L'ancien reste scannable pendant grace_minutes (0 : invalide immédiatement)
+*/
+type QRTokenRotateRequest struct {
+	TokenID      *uuid.UUID `json:"token_id"`
+	GraceMinutes int        `json:"grace_minutes"`
+}
+
+type QRTokenRotateResponse struct {
+	Message  string  `json:"message"`
+	Token    QRToken `json:"token"`
+	Previous QRToken `json:"previous"`
+}
+
+// Page client : entreprise désignée par le QR code scanné
+type QueueTokenResponse struct {
+	BusinessID      uuid.UUID `json:"business_id"`
+	Name            string    `json:"name"`
+	CustomMessage   string    `json:"custom_message,omitempty"`
+	DefaultLanguage string    `json:"default_language"`
+	LogoURL         string    `json:"logo_url,omitempty"`
+	IsQueueActive   bool      `json:"is_queue_active"`
+	IsQueuePaused   bool      `json:"is_queue_paused"`
+}
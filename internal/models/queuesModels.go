@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,14 +22,17 @@ type Queue struct {
 	ActualServiceTime int       `json:"actual_service_time" db:"actual_service_time"`
 	SmsSentCount      int       `json:"sms_sent_count" db:"sms_sent_count"`
 	LastSmsSentAt     time.Time `json:"last_sms_sent_at" db:"last_sms_sent_at"`
+	Language          string    `json:"language" db:"language"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type JoinQueueRequest struct {
-	BusinessID uuid.UUID `json:"business_id"`
+	BusinessID uuid.UUID `json:"business_id"` // facultatif avec qr_token
+	QRToken    string    `json:"qr_token"`    // QR code scanné, pour attribuer l'inscription
 	Phone      string    `json:"phone"`
 	ClientName string    `json:"client_name"`
+	Language   string    `json:"language"` // optionnel, sinon langue par défaut du commerce
 }
 
 type JoinQueueResponse struct {
@@ -39,8 +45,115 @@ type QueueEntry struct {
 	BusinessID        uuid.UUID `json:"business_id"`
 	Phone             string    `json:"phone"`
 	ClientName        string    `json:"client_name"`
+	Ticket            string    `json:"ticket"` // numéro du jour affiché au client (ex : A001)
 	Position          int       `json:"position"`
 	EstimatedWaitTime int       `json:"estimated_wait_time"` // en minutes
 	Status            string    `json:"status"`
+	Language          string    `json:"language"` // langue des SMS et de la page client
 	CreatedAt         time.Time `json:"created_at"`
 }
+
+// Préfixe de ticket : 0 à 3 lettres majuscules (ex : "A" pour un service, "" sans préfixe)
+var ticketPrefixPattern = regexp.MustCompile(`^[A-Z]{0,3}$`)
+
+var ErrTicketPrefixInvalid = errors.New("[queuesModels.go] -> Préfixe de ticket invalide")
+
+func ValidateTicketPrefix(prefix string) error {
+	if !ticketPrefixPattern.MatchString(prefix) {
+		return ErrTicketPrefixInvalid
+	}
+	return nil
+}
+
+/*
+Numéro de ticket affiché : préfixe suivi du numéro sur 3 chiffres (A001, B014), numéro seul sans préfixe (27)
+Vide pour une inscription sans numéro
+*/
+func TicketLabel(prefix string, number int) string {
+	if number <= 0 {
+		return ""
+	}
+	if prefix == "" {
+		return fmt.Sprint(number)
+	}
+	return fmt.Sprintf("%s%03d", prefix, number)
+}
+
+// Actions du commerçant sur la file (historique queue_entry_events)
+const (
+	QueueActionMove      = "move"
+	QueueActionMoveToEnd = "move_to_end"
+	QueueActionRemove    = "remove"
+)
+
+// Origine d'une inscription : QR code scanné en magasin, ou à distance (lien partagé, site)
+const (
+	QueueSourceQR     = "qr"
+	QueueSourceRemote = "remote"
+)
+
+// Motifs de retrait d'un client : no_show le marque absent (missed), les autres annulé (cancelled)
+var QueueRemoveReasons = []string{"no_show", "left", "duplicate", "behaviour", "other"}
+
+// Longueur maximum de la note du commerçant
+const QueueNoteMaxLength = 255
+
+// Tableau de bord du commerçant (GET /businesses/{id}/queue)
+type QueueDashboardResponse struct {
+	BusinessID         uuid.UUID             `json:"business_id"`
+	IsQueueActive      bool                  `json:"is_queue_active"`
+	IsQueuePaused      bool                  `json:"is_queue_paused"`
+	AverageServiceTime int                   `json:"average_service_time"` // en secondes
+	Entries            []QueueDashboardEntry `json:"entries"`              // clients appelés, puis en attente par position
+	Today              QueueTodayStats       `json:"today"`
+	GeneratedAt        time.Time             `json:"generated_at"`
+}
+
+type QueueDashboardEntry struct {
+	ID                uuid.UUID  `json:"id"`
+	Ticket            string     `json:"ticket"`
+	ClientName        string     `json:"client_name"`
+	Phone             string     `json:"phone"`
+	Position          int        `json:"position"`
+	Status            string     `json:"status"` // waiting, called
+	Language          string     `json:"language"`
+	Source            string     `json:"source"`              // qr, remote
+	Remote            bool       `json:"remote"`              // inscrit sans scanner de QR code (lien partagé, site)
+	WaitingMinutes    int        `json:"waiting_minutes"`     // depuis l'inscription
+	EstimatedWaitTime int        `json:"estimated_wait_time"` // en minutes, avant d'être appelé
+	EstimatedCallAt   *time.Time `json:"estimated_call_at"`   // null si le client n'est plus en attente
+	SmsSentCount      int        `json:"sms_sent_count"`
+	CalledAt          *time.Time `json:"called_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Compteurs de la journée (depuis minuit)
+type QueueTodayStats struct {
+	Registered         int  `json:"registered"`
+	Served             int  `json:"served"`
+	Missed             int  `json:"missed"`
+	Cancelled          int  `json:"cancelled"`
+	AverageWaitMinutes *int `json:"average_wait_minutes"` // de l'inscription à l'appel, null sans client appelé
+}
+
+// Déplacer un client en attente à une position (1 : en tête)
+type QueueMoveRequest struct {
+	Position int    `json:"position"`
+	Note     string `json:"note"`
+}
+
+// Renvoyer un client en fin de file (corps facultatif)
+type QueueMoveToEndRequest struct {
+	Note string `json:"note"`
+}
+
+// Retirer un client de la file
+type QueueRemoveRequest struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
+
+type QueueEntryActionResponse struct {
+	Message string              `json:"message"`
+	Entry   QueueDashboardEntry `json:"entry"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// Réponse de connexion quand la double authentification est activée : le token de session est remis après le code
+type LoginChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"` // secondes
+}
+
+// Second facteur : code TOTP de l'application ou code de secours
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// Fin de connexion avec le token de challenge
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// Enrôlement : secret à saisir ou QR code à scanner dans l'application
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	QRCode     string `json:"qr_code"` // image PNG en data URI
+}
+
+// Codes de secours, affichés une seule fois
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token,omitempty"` // nouveau token de session (double authentification incluse) après l'activation
+}
+
+// Exigence de double authentification pour accéder à une entreprise
+type BusinessTwoFactorPolicyRequest struct {
+	RequireTwoFactor *bool `json:"require_two_factor"`
+}
+
+type BusinessTwoFactorPolicyResponse struct {
+	Message          string `json:"message"`
+	RequireTwoFactor bool   `json:"require_two_factor"`
+}
@@ -2,7 +2,7 @@ package models
 
 import (
 	"errors"
-	"log"
+	"net/url"
 	"regexp"
 	"time"
 
@@ -14,17 +14,21 @@ import (
 
 // Modèle utilisateur
 type User struct {
-	ID             uuid.UUID `json:"id" db:"id"`
-	Google_id      string    `json:"google_id" db:"google_id"`
-	Email          string    `json:"email" db:"email"`
-	FirstName      string    `json:"first_name" db:"first_name"`
-	LastName       string    `json:"last_name" db:"last_name"`
-	ProfilePicture string    `json:"profile_picture" db:"profile_picture"`
-	AuthProvider   string    `json:"auth_provider" db:"auth_provider"`
-	Password       string    `json:"-" db:"password"` // "-" signifie que ça ne sera pas inclut dans le JSON
-	PhoneNumber    string    `json:"phone" db:"phone"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	Google_id        string     `json:"google_id" db:"google_id"`
+	Email            string     `json:"email" db:"email"`
+	FirstName        string     `json:"first_name" db:"first_name"`
+	LastName         string     `json:"last_name" db:"last_name"`
+	ProfilePicture   string     `json:"profile_picture" db:"profile_picture"`
+	AuthProvider     string     `json:"auth_provider" db:"auth_provider"`
+	Password         string     `json:"-" db:"password"` // "-" signifie que ça ne sera pas inclut dans le JSON
+	PhoneNumber      string     `json:"phone" db:"phone_number"`
+	HasPassword      bool       `json:"has_password" db:"-"`                      // false pour un compte créé avec Google
+	EmailVerifiedAt  *time.Time `json:"email_verified_at" db:"email_verified_at"` // null tant que l'email n'est pas confirmé
+	TwoFactorEnabled bool       `json:"two_factor_enabled" db:"-"`
+	LastLogin        *time.Time `json:"last_login" db:"last_login"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // Format requête connexion
@@ -49,22 +53,93 @@ func (user *RegisterRequest) Validate() error {
 	return nil
 }
 
-// Format validation mot de passe
-func (user *RegisterRequest) ValidatePassword() error {
-	if len(user.Password) < 6 {
-		return errors.New("[user.go] -> Le mot de passe doit avoir au moins caractères.")
-	}
+// Format réponse auhtentification
+type AuthResponse struct {
+	Token string `json:"token"`
+	User  User   `json:"user"`
+}
+
+/* ======================= VÉRIFICATION EMAIL / MOT DE PASSE ======================= */
 
-	if len(user.Password) > 100 {
-		return errors.New("[user.go] -> Le mot de passe ne doit pas dépasser 100 caractères.")
+// Usage d'un token envoyé par email (colonne auth_tokens.purpose)
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+// Demande de lien (réinitialisation du mot de passe, nouvel email de vérification)
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// Nouveau mot de passe avec le token reçu par email
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Réponse sans contenu particulier
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+/* ======================= PROFIL ======================= */
+
+// Modification du profil : seuls les champs présents sont modifiés, une chaîne vide efface la valeur
+type UpdateProfileRequest struct {
+	FirstName   *string `json:"first_name"`
+	LastName    *string `json:"last_name"`
+	PhoneNumber *string `json:"phone"`
+}
+
+// Changement de mot de passe (mot de passe actuel non requis pour un compte créé avec Google qui n'en a pas encore)
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// Nouveau token de session : ceux émis avant le changement sont refusés
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+	Token   string `json:"token"`
+}
+
+// Demande de suppression du compte (mot de passe requis si le compte en a un)
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type DeleteAccountResponse struct {
+	Message    string    `json:"message"`
+	DeletionAt time.Time `json:"deletion_at"` // suppression définitive, annulable en se reconnectant avant cette date
+}
+
+// Longueur maximum des noms (colonnes first_name, last_name)
+const UserNameMaxLength = 100
+
+// Numéro de téléphone français (contrainte check_phone_number_format de la table users)
+var userPhoneRegex = regexp.MustCompile(`^(\+33|0)[1-9][0-9]{8}$`)
+
+func ValidateUserPhoneNumber(phoneNumber string) error {
+	if !userPhoneRegex.MatchString(phoneNumber) {
+		return errors.New("[userModels.go -> ValidateUserPhoneNumber()] -> Format du numéro de téléphone invalide.")
 	}
 	return nil
 }
 
-// Format réponse auhtentification
-type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+/*
+Photo de profil fournie à l'inscription : URL https externe uniquement (colonne profile_picture, 255 caractères)
+Les photos stockées par l'API passent par POST /users/me/avatar : une clé du stockage n'est jamais acceptée ici
+*/
+func ValidateProfilePictureURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || len(value) > 255 {
+		return errors.New("[userModels.go -> ValidateProfilePictureURL()] -> URL de la photo de profil invalide.")
+	}
+	return nil
 }
 
 /* ======================= GOOGLE CLOUD ======================= */
@@ -89,20 +164,13 @@ type GoogleUser struct {
 var AppConfig Config
 
 // Config Google Cloud
-func GoogleConfig() oauth2.Config {
-	// Variables d'environnement
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatal(`[user.go] -> Erreur lors du chargement des variables d'environnements.`, err)
-	}
-
+func GoogleConfig(cfg *config.Config) oauth2.Config {
 	AppConfig.GoogleLoginConfig = oauth2.Config{
 		ClientID:     cfg.GCP.ClientID,
 		ClientSecret: cfg.GCP.ClientSecret,
 		RedirectURL:  cfg.GCP.RedirectURL,
-		Scopes: []string{"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile"},
-		Endpoint: google.Endpoint,
+		Scopes:       cfg.GCP.Scopes,
+		Endpoint:     google.Endpoint,
 	}
 
 	return AppConfig.GoogleLoginConfig
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Dimensions maximales acceptées avant décodage (protection mémoire : 4096 x 4096 x 4 octets = 64 Mo)
+const MaxImageDimension = 4096
+
+var (
+	ErrImageType       = errors.New("[images.go] -> Format d'image non supporté")
+	ErrImageDimensions = errors.New("[images.go] -> Dimensions de l'image invalides")
+)
+
+// Formats acceptés (type détecté sur le contenu, pas sur l'extension ni l'en-tête Content-Type)
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// Image prête à être stockée
+type ProcessedImage struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+/*
+Valider et normaliser une image envoyée par un utilisateur
+- type détecté sur le contenu (PNG, JPEG, GIF), dimensions vérifiées avant le décodage complet
+- orientation EXIF appliquée (photos de téléphone)
+- réduite pour tenir dans un carré de `maxSize` pixels (jamais agrandie)
+- réencodée en PNG si elle contient de la transparence, en JPEG sinon : les métadonnées (EXIF, GPS...) ne sont pas conservées
+*/
+func ProcessImage(data []byte, maxSize int) (*ProcessedImage, error) {
+	if !imageTypes[http.DetectContentType(data)] {
+		return nil, ErrImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+		return nil, ErrImageDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+
+	resized := Resize(applyOrientation(img, jpegOrientation(data)), maxSize)
+
+	var out bytes.Buffer
+	if hasAlpha(resized) {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&out, resized); err != nil {
+			return nil, fmt.Errorf("[images.go -> ProcessImage()] -> %w", err)
+		}
+		return &ProcessedImage{Data: out.Bytes(), ContentType: "image/png", Extension: ".png"}, nil
+	}
+
+	if err := jpeg.Encode(&out, resized, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("[images.go -> ProcessImage()] -> %w", err)
+	}
+	return &ProcessedImage{Data: out.Bytes(), ContentType: "image/jpeg", Extension: ".jpg"}, nil
+}
+
+/*
+Réduire une image pour qu'elle tienne dans un carré de `maxSize` pixels (jamais agrandie)
+Moyenne des pixels couverts (filtre "box") : suffisant pour des vignettes, sans dépendance à golang.org/x/image
+*/
+func Resize(src image.Image, maxSize int) *image.NRGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			width, height = maxSize, max(1, height*maxSize/width)
+		} else {
+			width, height = max(1, width*maxSize/height), maxSize
+		}
+	}
+
+	// Copie en NRGBA pour un accès direct aux pixels
+	source := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(source, source.Bounds(), src, bounds.Min, draw.Src)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return source
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		y0, y1 := y*bounds.Dy()/height, max((y+1)*bounds.Dy()/height, y*bounds.Dy()/height+1)
+		for x := range width {
+			x0, x1 := x*bounds.Dx()/width, max((x+1)*bounds.Dx()/width, x*bounds.Dx()/width+1)
+
+			// Moyenne pondérée par l'alpha : pas de halo sombre autour des zones transparentes
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := source.Pix[sy*source.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					alpha := uint64(p[3])
+					r += uint64(p[0]) * alpha
+					g += uint64(p[1]) * alpha
+					b += uint64(p[2]) * alpha
+					a += alpha
+					n++
+				}
+			}
+
+			pixel := color.NRGBA{}
+			if a > 0 {
+				pixel = color.NRGBA{R: uint8(r / a), G: uint8(g / a), B: uint8(b / a), A: uint8(a / n)}
+			}
+			dst.SetNRGBA(x, y, pixel)
+		}
+	}
+	return dst
+}
+
+func hasAlpha(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xff {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Orientation EXIF d'un JPEG (tag 0x0112 du segment APP1), 1 si absente ou illisible
+*/
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xda || length < 2 || i+2+length > len(data) {
+			return 1 // début des données de l'image : pas d'EXIF
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for e := range entries {
+		entry := offset + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+/*
+Appliquer une orientation EXIF (1 à 8) : l'image est redressée et l'orientation n'a plus à être lue par le client
+*/
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	transposed := orientation >= 5
+	if transposed {
+		width, height = height, width
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range bounds.Dy() {
+		for x := range bounds.Dx() {
+			var dx, dy int
+			switch orientation {
+			case 2: // miroir horizontal
+				dx, dy = bounds.Dx()-1-x, y
+			case 3: // rotation 180°
+				dx, dy = bounds.Dx()-1-x, bounds.Dy()-1-y
+			case 4: // miroir vertical
+				dx, dy = x, bounds.Dy()-1-y
+			case 5: // transposition
+				dx, dy = y, x
+			case 6: // rotation 90° horaire
+				dx, dy = bounds.Dy()-1-y, x
+			case 7: // transposition inverse
+				dx, dy = bounds.Dy()-1-y, bounds.Dx()-1-x
+			case 8: // rotation 90° antihoraire
+				dx, dy = y, bounds.Dx()-1-x
+			}
+			dst.Set(dx, dy, src.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
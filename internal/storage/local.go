@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+)
+
+// Répertoire local, les fichiers sont servis par l'API (LocalHandler) avec des liens signés (HMAC)
+type localStorage struct {
+	dir     string
+	baseURL string
+	key     []byte
+}
+
+func newLocalStorage(cfg *config.Config) (*localStorage, error) {
+	if err := os.MkdirAll(cfg.Storage.LocalDir, 0o755); err != nil {
+		return nil, fmt.Errorf("[local.go -> newLocalStorage()] -> Impossible de créer %q : %w", cfg.Storage.LocalDir, err)
+	}
+	key := sha256.Sum256([]byte("waitify-storage:" + cfg.JWT.Secret))
+	return &localStorage{
+		dir:     cfg.Storage.LocalDir,
+		baseURL: strings.TrimSuffix(cfg.Storage.LocalURL, "/"),
+		key:     key[:],
+	}, nil
+}
+
+func (s *localStorage) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("[local.go -> path()] -> Clé invalide %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Écriture dans un fichier temporaire puis renommage : un fichier servi n'est jamais incomplet
+func (s *localStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("[local.go -> Put()] -> %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("[local.go -> Put()] -> %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("[local.go -> Put()] -> %w", err)
+	}
+	return nil
+}
+
+func (s *localStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[local.go -> Get()] -> %w", err)
+	}
+	return data, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("[local.go -> Delete()] -> %w", err)
+	}
+
+	// Répertoire de l'entité vide : supprimé (échec ignoré s'il reste des fichiers)
+	os.Remove(filepath.Dir(path))
+	return nil
+}
+
+func (s *localStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	root := filepath.Join(s.dir, filepath.FromSlash(strings.TrimSuffix(prefix, "/")))
+	var objects []Object
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, Object{Key: key, LastModified: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[local.go -> List()] -> %w", err)
+	}
+	return objects, nil
+}
+
+func (s *localStorage) URL(key string, expiry time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("[local.go -> URL()] -> Clé invalide %q", key)
+	}
+	_, expires := signingWindow(time.Now(), expiry)
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", s.sign(key, expires.Unix()))
+	return s.baseURL + "/" + escapePath(key) + "?" + query.Encode(), nil
+}
+
+func (s *localStorage) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/*
+Servir les fichiers du stockage local sur /uploads/{clé}?expires=...&signature=...
+Lien expiré ou signature invalide : 404 (pas de distinction avec un fichier absent)
+nil si le stockage n'est pas local
+*/
+func LocalHandler(notFound http.HandlerFunc) http.HandlerFunc {
+	local, ok := current.(*localStorage)
+	if !ok {
+		return nil
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+		expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+		if err != nil || !validKey(key) || time.Now().Unix() > expires ||
+			subtle.ConstantTimeCompare([]byte(local.sign(key, expires)), []byte(r.URL.Query().Get("signature"))) != 1 {
+			notFound(w, r)
+			return
+		}
+
+		path, _ := local.path(key)
+		file, err := os.Open(path)
+		if err != nil {
+			notFound(w, r)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil || info.IsDir() {
+			notFound(w, r)
+			return
+		}
+
+		maxAge := max(expires-time.Now().Unix(), 0)
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+		http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+	}
+}
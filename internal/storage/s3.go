@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+)
+
+var errS3NotFound = errors.New("[s3.go] -> Objet introuvable")
+
+const (
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3UnsignedBody   = "UNSIGNED-PAYLOAD"
+	s3TimeFormat     = "20060102T150405Z"
+	s3DateFormat     = "20060102"
+	s3RequestTimeout = 30 * time.Second
+)
+
+/*
+Bucket S3 (ou compatible : MinIO, Scaleway, R2...) via l'API REST signée en SigV4
+Seules les opérations utilisées par l'API sont implémentées : PutObject, GetObject, DeleteObject, ListObjectsV2 et les liens GET pré-signés
+*/
+type s3Storage struct {
+	client    *http.Client
+	scheme    string
+	host      string
+	basePath  string // "/bucket" en adressage path-style, vide sinon
+	region    string
+	accessKey string
+	secretKey string
+}
+
+func newS3Storage(cfg *config.Config) (*s3Storage, error) {
+	s := &s3Storage{
+		client:    &http.Client{Timeout: s3RequestTimeout},
+		scheme:    "https",
+		host:      "s3." + cfg.AWSS3.AWSS3Region + ".amazonaws.com",
+		region:    cfg.AWSS3.AWSS3Region,
+		accessKey: cfg.AWSIAM.AWSIAMAccessKey,
+		secretKey: cfg.AWSIAM.AWSIAMSecretKey,
+	}
+
+	if cfg.AWSS3.AWSS3Endpoint != "" {
+		endpoint, err := url.Parse(cfg.AWSS3.AWSS3Endpoint)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("[s3.go -> newS3Storage()] -> AWS_S3_ENDPOINT invalide %q", cfg.AWSS3.AWSS3Endpoint)
+		}
+		s.scheme = endpoint.Scheme
+		s.host = endpoint.Host
+	}
+
+	if cfg.AWSS3.AWSS3PathStyle {
+		s.basePath = "/" + cfg.AWSS3.AWSS3Bucket
+	} else {
+		s.host = cfg.AWSS3.AWSS3Bucket + "." + s.host
+	}
+	return s, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return fmt.Errorf("[s3.go -> Put()] -> Clé invalide %q", key)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", "private, max-age=31536000, immutable") // clés uniques : contenu jamais modifié
+
+	resp, err := s.do(ctx, http.MethodPut, s.basePath+"/"+escapePath(key), nil, header, data)
+	if err != nil {
+		return fmt.Errorf("[s3.go -> Put()] -> %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Objets lus par l'API : images déjà réduites, taille plafonnée par prudence
+const s3MaxGetBytes = 32 << 20
+
+func (s *s3Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("[s3.go -> Get()] -> Clé invalide %q", key)
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.basePath+"/"+escapePath(key), nil, nil, nil)
+	if errors.Is(err, errS3NotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[s3.go -> Get()] -> %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, s3MaxGetBytes))
+	if err != nil {
+		return nil, fmt.Errorf("[s3.go -> Get()] -> %w", err)
+	}
+	return data, nil
+}
+
+// S3 répond 204 que l'objet existe ou non
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return fmt.Errorf("[s3.go -> Delete()] -> Clé invalide %q", key)
+	}
+	resp, err := s.do(ctx, http.MethodDelete, s.basePath+"/"+escapePath(key), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("[s3.go -> Delete()] -> %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjectsV2, pages de 1000 objets suivies jusqu'au bout
+func (s *s3Storage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, s.basePath+"/", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("[s3.go -> List()] -> %w", err)
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("[s3.go -> List()] -> Réponse invalide : %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{Key: content.Key, LastModified: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Lien GET pré-signé (X-Amz-Date arrondi à la fenêtre de signature, cf. signingWindow)
+func (s *s3Storage) URL(key string, expiry time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("[s3.go -> URL()] -> Clé invalide %q", key)
+	}
+	start, end := signingWindow(time.Now(), expiry)
+	return s.presign(s.basePath+"/"+escapePath(key), start, end.Sub(start)), nil
+}
+
+func (s *s3Storage) presign(path string, at time.Time, expires time.Duration) string {
+	at = at.UTC()
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(at))
+	query.Set("X-Amz-Date", at.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		path,
+		canonicalQuery(query),
+		"host:" + s.host + "\n",
+		"host",
+		s3UnsignedBody,
+	}, "\n")
+
+	signature := s.signature(at, canonical)
+	return s.scheme + "://" + s.host + path + "?" + canonicalQuery(query) + "&X-Amz-Signature=" + signature
+}
+
+// Requête signée (en-têtes Authorization, x-amz-date, x-amz-content-sha256)
+func (s *s3Storage) do(ctx context.Context, method, path string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	now := time.Now().UTC()
+	payloadHash := sha256Hex(body)
+
+	target := s.scheme + "://" + s.host + path
+	if len(query) > 0 {
+		target += "?" + canonicalQuery(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.ContentLength = int64(len(body))
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery(query),
+		"host:" + s.host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + now.Format(s3TimeFormat) + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errS3NotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("S3 a répondu %d : %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+func (s *s3Storage) scope(at time.Time) string {
+	return at.Format(s3DateFormat) + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s *s3Storage) signature(at time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		at.Format(s3TimeFormat),
+		s.scope(at),
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), at.Format(s3DateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Paramètres triés et encodés selon SigV4 (espaces en %20, pas en +)
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// Chemin d'une clé encodé segment par segment (utilisé aussi par le stockage local)
+func escapePath(key string) string {
+	return uriEncode(key, false)
+}
+
+// Encodage RFC 3986 exigé par SigV4 : seuls A-Z a-z 0-9 - _ . ~ restent tels quels
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/google/uuid"
+)
+
+// Objet stocké (liste pour le nettoyage des fichiers orphelins)
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+/*
+Stockage des fichiers envoyés par les utilisateurs, choisi par la configuration (STORAGE_DRIVER)
+- s3 : bucket AWS S3 ou compatible (production)
+- local : répertoire STORAGE_LOCAL_DIR, servi par l'API sur /uploads (développement, une seule instance)
+Les clés sont de la forme "<répertoire>/<id de l'entité>/<fichier>" (cf. UserPrefix, BusinessPrefix)
+*/
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Lien de téléchargement signé, valable au moins `expiry`
+	URL(key string, expiry time.Duration) (string, error)
+}
+
+var ErrNotFound = errors.New("[storage.go] -> Fichier introuvable")
+
+var (
+	current       Storage
+	urlExpiry     = time.Hour
+	usersDir      = "users"
+	businessesDir = "businesses"
+)
+
+// Choisir l'implémentation à partir de la configuration (déjà validée)
+func Init(cfg *config.Config) error {
+	urlExpiry = cfg.Storage.URLExpiry
+	usersDir = strings.Trim(cfg.AWSS3.AWSS3UsersDir, "/")
+	businessesDir = strings.Trim(cfg.AWSS3.AWSS3BusinessesDir, "/")
+
+	switch cfg.Storage.Driver {
+	case "s3":
+		s3, err := newS3Storage(cfg)
+		if err != nil {
+			return err
+		}
+		current = s3
+	case "local":
+		local, err := newLocalStorage(cfg)
+		if err != nil {
+			return err
+		}
+		current = local
+	default:
+		return fmt.Errorf("[storage.go -> Init()] -> Driver de stockage inconnu %q", cfg.Storage.Driver)
+	}
+	return nil
+}
+
+func Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return current.Put(ctx, key, data, contentType)
+}
+
+// Lire un objet (ErrNotFound s'il n'existe pas)
+func Get(ctx context.Context, key string) ([]byte, error) {
+	return current.Get(ctx, key)
+}
+
+// Supprimer un objet (absent : pas d'erreur)
+func Delete(ctx context.Context, key string) error {
+	err := current.Delete(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Supprimer tous les objets d'une entité (utilisateur ou entreprise supprimé)
+func DeletePrefix(ctx context.Context, prefix string) error {
+	objects, err := current.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, object := range objects {
+		if err := Delete(ctx, object.Key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func List(ctx context.Context, prefix string) ([]Object, error) {
+	return current.List(ctx, prefix)
+}
+
+/*
+URL à retourner au client pour une valeur de colonne (users.profile_picture, businesses.logo)
+- vide : pas d'image
+- URL complète (photo Google...) : retournée telle quelle
+- clé de stockage : lien de téléchargement signé
+*/
+func PublicURL(ctx context.Context, ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://") {
+		return ref
+	}
+	url, err := current.URL(ref, urlExpiry)
+	if err != nil {
+		slog.WarnContext(ctx, "Lien de téléchargement non généré", "key", ref, "error", err)
+		return ""
+	}
+	return url
+}
+
+// La valeur de la colonne désigne un objet du stockage (et non une URL externe)
+func IsKey(ref string) bool {
+	return ref != "" && !strings.Contains(ref, "://")
+}
+
+// Préfixe des objets d'un utilisateur (photo de profil)
+func UserPrefix(userID uuid.UUID) string {
+	return usersDir + "/" + userID.String() + "/"
+}
+
+// Préfixe des objets d'une entreprise (logo)
+func BusinessPrefix(businessID uuid.UUID) string {
+	return businessesDir + "/" + businessID.String() + "/"
+}
+
+// Répertoires racine, pour le nettoyage des fichiers orphelins
+func UsersDir() string      { return usersDir + "/" }
+func BusinessesDir() string { return businessesDir + "/" }
+
+// Clé valide : chemin relatif, sans ".." ni segment vide
+func validKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "/") && path.Clean(key) == key && !strings.HasPrefix(key, "..")
+}
+
+/*
+Fenêtre de signature : les liens générés pendant une même fenêtre sont identiques (cache du navigateur),
+et restent valables entre `expiry` et 2 x `expiry`
+*/
+func signingWindow(now time.Time, expiry time.Duration) (time.Time, time.Time) {
+	start := now.Truncate(expiry)
+	return start, start.Add(2 * expiry)
+}
@@ -0,0 +1,192 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "waitify"
+
+// Registre dédié : on n'expose que ce que l'on déclare ici (+ métriques Go et processus)
+var registry = prometheus.NewRegistry()
+
+var (
+	// HTTP (le label "route" est le pattern du routeur, jamais l'URL brute, pour limiter la cardinalité)
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Nombre de requêtes HTTP par méthode, route et code de statut.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latence des requêtes HTTP par méthode et route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// Files d'attente
+	queueJoinsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "joins_total",
+		Help:      "Nombre de clients ayant rejoint une file d'attente.",
+	})
+
+	queueCallsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "calls_total",
+		Help:      "Nombre de clients appelés au comptoir.",
+	})
+
+	queueMissesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "misses_total",
+		Help:      "Nombre de clients absents lors de leur appel.",
+	})
+
+	// SMS (type : confirmation, reminder, your_turn, missed, cancelled ; outcome : sent, failed)
+	smsSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sms",
+		Name:      "sent_total",
+		Help:      "Nombre d'envois de SMS par type et par résultat.",
+	}, []string{"type", "outcome"})
+
+	// Emails (type : email_verification, password_reset ; outcome : sent, failed)
+	emailsSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "emails",
+		Name:      "sent_total",
+		Help:      "Nombre d'envois d'emails par type et par résultat.",
+	}, []string{"type", "outcome"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		queueJoinsTotal,
+		queueCallsTotal,
+		queueMissesTotal,
+		smsSentTotal,
+		emailsSentTotal,
+	)
+}
+
+// Enregistrer les métriques liées à la base : statistiques du pool et clients en attente par entreprise
+func RegisterDBMetrics(db *sql.DB) error {
+	if err := registry.Register(collectors.NewDBStatsCollector(db, namespace)); err != nil {
+		return err
+	}
+	return registry.Register(&waitingCollector{db: db})
+}
+
+// Handler de l'endpoint /metrics
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	})
+}
+
+// Requête HTTP terminée
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// Client ajouté à une file d'attente
+func QueueJoined() {
+	queueJoinsTotal.Inc()
+}
+
+// Client appelé au comptoir
+func QueueCalled() {
+	queueCallsTotal.Inc()
+}
+
+// Client absent lors de son appel
+func QueueMissed() {
+	queueMissesTotal.Inc()
+}
+
+// Résultat d'un envoi de SMS
+func RecordSMS(smsType string, err error) {
+	outcome := "sent"
+	if err != nil {
+		outcome = "failed"
+	}
+	smsSentTotal.WithLabelValues(smsType, outcome).Inc()
+}
+
+// Résultat d'un envoi d'email
+func RecordEmail(emailType string, err error) {
+	outcome := "sent"
+	if err != nil {
+		outcome = "failed"
+	}
+	emailsSentTotal.WithLabelValues(emailType, outcome).Inc()
+}
+
+/*
+Nombre de clients en attente par entreprise, calculé à chaque scrape
+Les files ouvertes sans client remontent à 0 pour que la série ne disparaisse pas
+*/
+type waitingCollector struct {
+	db *sql.DB
+}
+
+var waitingDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "queue", "waiting"),
+	"Nombre de clients en attente par entreprise.",
+	[]string{"business_id"}, nil,
+)
+
+func (c *waitingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- waitingDesc
+}
+
+func (c *waitingCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT b.id, COUNT(q.id)
+		FROM businesses b
+		LEFT JOIN queue_entries q ON q.BusinessId = b.id AND q.status = 'waiting'
+		GROUP BY b.id
+		HAVING b.is_queue_active OR COUNT(q.id) > 0
+	`)
+	if err != nil {
+		slog.Warn("Impossible de calculer les files d'attente pour les métriques", "origin", "metrics.go -> waitingCollector.Collect()", "error", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var businessID string
+		var waiting float64
+		if err := rows.Scan(&businessID, &waiting); err != nil {
+			slog.Warn("Ligne invalide pour les métriques des files d'attente", "origin", "metrics.go -> waitingCollector.Collect()", "error", err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(waitingDesc, prometheus.GaugeValue, waiting, businessID)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Warn("Erreur lors de la lecture des files d'attente pour les métriques", "origin", "metrics.go -> waitingCollector.Collect()", "error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+/*
+Traces OpenTelemetry exportées en OTLP/HTTP
+Si les traces sont désactivées, le TracerProvider global reste le "noop" : les spans ne coûtent (presque) rien
+La fonction retournée vide le buffer de spans, elle est appelée à l'arrêt du serveur
+*/
+func InitTracing(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Tracing.Enabled {
+		return noop, nil
+	}
+
+	// Sans endpoint explicite, l'exporteur lit les variables OTEL_EXPORTER_OTLP_* standards (défaut : localhost:4318)
+	var options []otlptracehttp.Option
+	if cfg.Tracing.OTLPEndpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(cfg.Tracing.OTLPEndpoint))
+	}
+	if cfg.Tracing.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return noop, fmt.Errorf("[tracing.go -> InitTracing()] -> Impossible de créer l'exporteur OTLP : %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.Tracing.ServiceName),
+		semconv.DeploymentEnvironmentName(cfg.Environment),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("[tracing.go -> InitTracing()] -> Ressource OpenTelemetry invalide : %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
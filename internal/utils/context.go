@@ -0,0 +1,31 @@
+package utils
+
+import "context"
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+// Ajouter l'identifiant de la requête au contexte
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// Récupérer l'identifiant de la requête depuis le contexte
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+const claimsKey contextKey = "claims"
+
+// Ajouter l'utilisateur authentifié (claims du JWT) au contexte
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// Récupérer l'utilisateur authentifié depuis le contexte (nil hors AuthMiddleware)
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey).(*Claims)
+	return claims
+}
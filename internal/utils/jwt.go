@@ -2,35 +2,62 @@ package utils
 
 import (
 	"errors"
-	"os"
+	"slices"
 	"time"
 
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-var jwtSecret []byte
+var (
+	jwtSecret          []byte
+	jwtTokenExpiry     time.Duration
+	jwtChallengeExpiry time.Duration
+)
 
-func InitJWT() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		panic(`[jwt.go -> InitJWT()] -> Variable d'environnement "JWT_SECRET" manquante !`)
-	}
-	jwtSecret = []byte(secret)
+// Méthodes d'authentification utilisées pour obtenir le token (claim "amr", RFC 8176)
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+)
+
+// Token intermédiaire entre le mot de passe et le code de double authentification
+const challengePurpose = "2fa_challenge"
+
+// Le secret est requis par la validation de la configuration
+func InitJWT(cfg *config.Config) {
+	jwtSecret = []byte(cfg.JWT.Secret)
+	jwtTokenExpiry = cfg.JWT.TokenExpiry
+	jwtChallengeExpiry = cfg.Auth.TwoFactorChallengeTTL
 }
 
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
+	UserID  uuid.UUID `json:"user_id"`
+	Email   string    `json:"email"`
+	AMR     []string  `json:"amr,omitempty"`
+	Purpose string    `json:"purpose,omitempty"` // vide pour un token de session
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, email string) (string, error) {
+// Token de session, `amr` indique comment l'utilisateur s'est authentifié
+func GenerateToken(userID uuid.UUID, email string, amr ...string) (string, error) {
+	return signToken(userID, email, amr, "", jwtTokenExpiry)
+}
+
+// Token de courte durée remis après le mot de passe, échangé contre un token de session avec le code TOTP
+func GenerateChallengeToken(userID uuid.UUID, email string) (string, error) {
+	return signToken(userID, email, []string{AMRPassword}, challengePurpose, jwtChallengeExpiry)
+}
+
+func signToken(userID uuid.UUID, email string, amr []string, purpose string, expiry time.Duration) (string, error) {
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:  userID,
+		Email:   email,
+		AMR:     amr,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "waitify-api",
 		},
@@ -40,10 +67,44 @@ func GenerateToken(userID uuid.UUID, email string) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
+// Durée de validité du token de challenge (annoncée au client)
+func ChallengeTokenExpiry() time.Duration {
+	return jwtChallengeExpiry
+}
+
+// Token de session (un token de challenge 2FA est refusé)
 func ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New(`[jwt.go -> ValidateToken()] -> Token de session attendu.`)
+	}
+	return claims, nil
+}
+
+// Token de challenge 2FA
+func ValidateChallengeToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != challengePurpose {
+		return nil, errors.New(`[jwt.go -> ValidateChallengeToken()] -> Token de challenge attendu.`)
+	}
+	return claims, nil
+}
+
+// Le token a été obtenu avec la double authentification
+func (c *Claims) HasOTP() bool {
+	return slices.Contains(c.AMR, AMROTP)
+}
+
+func parseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New(`[jwt.go -> ValidateToken()] -> Méthode de signature du token invalide.`)
+			return nil, errors.New(`[jwt.go -> parseToken()] -> Méthode de signature du token invalide.`)
 		}
 		return jwtSecret, nil
 	})
@@ -56,5 +117,5 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return claims, nil
 	}
 
-	return nil, errors.New(`[jwt.go -> ValidateToken()] -> Token invalide.`)
+	return nil, errors.New(`[jwt.go -> parseToken()] -> Token invalide.`)
 }
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+Logger structuré (log/slog)
+- Format "json" (production) ou "text" (développement)
+- L'identifiant de la requête (et la trace OpenTelemetry si elle existe) est ajouté automatiquement à chaque log émis avec un contexte (slog.InfoContext...)
+*/
+func InitLogger(cfg *config.Config) error {
+	logger, err := NewLogger(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+	return nil
+}
+
+// Créer un logger avec le niveau et le format demandés
+func NewLogger(out io.Writer, level, format string) (*slog.Logger, error) {
+	var slogLevel slog.Level
+	if err := slogLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("[logger.go -> NewLogger()] -> Niveau de log invalide %q : %w", level, err)
+	}
+
+	options := &slog.HandlerOptions{Level: slogLevel}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(out, options)
+	case "text":
+		handler = slog.NewTextHandler(out, options)
+	default:
+		return nil, fmt.Errorf("[logger.go -> NewLogger()] -> Format de log invalide %q (json ou text)", format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Handler slog qui ajoute les informations du contexte de la requête
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
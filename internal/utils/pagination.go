@@ -0,0 +1,321 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// Taille des pages par défaut et maximum
+const (
+	ListDefaultLimit = 20
+	ListMaxLimit     = 100
+)
+
+// Colonne de tri : expression SQL non NULL et son type PostgreSQL (text, integer, timestamptz), pour relire le curseur
+type ListSort struct {
+	Column string
+	Type   string
+}
+
+// Types de filtres
+const (
+	FilterEquals = iota // ?key=a,b : une des valeurs
+	FilterRange         // ?key_from=...&key_to=... : dates (YYYY-MM-DD, jour inclus) ou horodatages RFC 3339 (exclu)
+)
+
+/*
+Filtre d'une liste
+- Values : valeurs acceptées (vide : libres)
+- Conditions : valeur -> condition SQL, à la place de Column (ex : "archived" -> "deleted_at IS NOT NULL")
+- Lower : comparaison insensible à la casse (Column doit être en minuscules, ex : "lower(city)")
+- Default : valeur appliquée sans paramètre
+*/
+type ListFilter struct {
+	Column     string
+	Kind       int
+	Values     []string
+	Conditions map[string]string
+	Lower      bool
+	Default    string
+}
+
+// Description d'une route de liste : tris et filtres autorisés
+type ListSpec struct {
+	Sorts       map[string]ListSort
+	DefaultSort string // ex : "-created_at"
+	IDColumn    string // départage les égalités de tri (UUID)
+	Filters     map[string]ListFilter
+}
+
+/*
+Liste demandée : taille de page, tri, curseur et filtres
+Pagination par curseur (keyset) : la page suivante commence après le dernier élément retourné,
+les insertions et suppressions entre deux pages ne décalent pas les résultats
+*/
+type List struct {
+	Limit      int
+	spec       ListSpec
+	sortKey    string
+	desc       bool
+	cursor     *listCursor
+	conditions []string
+	args       []any
+	rows       int
+	last       listCursor
+}
+
+// Contenu du curseur (base64 URL) : tri, valeur de tri et ID du dernier élément
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+/*
+Lire les paramètres de la liste (limit, cursor, sort et filtres)
+Retourne false si une réponse d'erreur (400, détail par paramètre) a déjà été envoyée
+*/
+func ParseList(w http.ResponseWriter, r *http.Request, spec ListSpec) (*List, bool) {
+	query := r.URL.Query()
+	list := &List{Limit: ListDefaultLimit, spec: spec}
+	var details []models.FieldError
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > ListMaxLimit {
+			details = append(details, models.FieldError{Field: "limit", Code: "out_of_range", Message: "list.limit_invalid", Args: []any{ListMaxLimit}})
+		} else {
+			list.Limit = limit
+		}
+	}
+
+	sort := query.Get("sort")
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := decodeListCursor(value)
+		if err != nil || (sort != "" && sort != cursor.Sort) {
+			details = append(details, models.FieldError{Field: "cursor", Code: "invalid", Message: "list.cursor_invalid"})
+		} else {
+			list.cursor = &cursor
+			sort = cursor.Sort
+		}
+	}
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	list.sortKey = strings.TrimPrefix(sort, "-")
+	list.desc = strings.HasPrefix(sort, "-")
+	if _, ok := spec.Sorts[list.sortKey]; !ok {
+		details = append(details, models.FieldError{Field: "sort", Code: "invalid_choice", Message: "list.sort_invalid", Args: []any{strings.Join(sortedKeys(spec.Sorts), ", ")}})
+	}
+
+	for _, key := range sortedKeys(spec.Filters) {
+		if fieldErr := list.addFilter(key, spec.Filters[key], query); fieldErr != nil {
+			details = append(details, *fieldErr)
+		}
+	}
+
+	if len(details) > 0 {
+		WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidParameter, "error.invalid_parameter", details...)
+		return nil, false
+	}
+	return list, true
+}
+
+// Condition SQL d'un filtre (paramètres numérotés à l'assemblage de la requête)
+func (l *List) addFilter(key string, filter ListFilter, query map[string][]string) *models.FieldError {
+	switch filter.Kind {
+	case FilterRange:
+		for _, bound := range []string{"from", "to"} {
+			param := key + "_" + bound
+			value := strings.TrimSpace(first(query[param]))
+			if value == "" {
+				continue
+			}
+			at, dateOnly, err := parseListTime(value)
+			if err != nil {
+				return &models.FieldError{Field: param, Code: "invalid_format", Message: "list.date_invalid"}
+			}
+			if bound == "from" {
+				l.conditions = append(l.conditions, filter.Column+" >= ?")
+			} else {
+				if dateOnly {
+					at = at.AddDate(0, 0, 1)
+				}
+				l.conditions = append(l.conditions, filter.Column+" < ?")
+			}
+			l.args = append(l.args, at)
+		}
+	default:
+		value := strings.TrimSpace(first(query[key]))
+		if value == "" {
+			value = filter.Default
+		}
+		if value == "" {
+			return nil
+		}
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			if filter.Lower {
+				v = strings.ToLower(v)
+			}
+			if len(filter.Values) > 0 && !slices.Contains(filter.Values, v) {
+				return &models.FieldError{Field: key, Code: "invalid_choice", Message: "list.filter_invalid", Args: []any{strings.Join(filter.Values, ", ")}}
+			}
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		if filter.Conditions != nil {
+			var conditions []string
+			for _, v := range values {
+				conditions = append(conditions, filter.Conditions[v])
+			}
+			l.conditions = append(l.conditions, "("+strings.Join(conditions, " OR ")+")")
+			return nil
+		}
+		l.conditions = append(l.conditions, filter.Column+" = ANY(?::text[])")
+		l.args = append(l.args, pq.Array(values))
+	}
+	return nil
+}
+
+/*
+Requête de la page : `base` (SELECT ... WHERE ..., paramètres `args`) suivie des filtres, du curseur, du tri et de la limite
+La dernière colonne sélectionnée doit être SortValue() (cf. Row)
+*/
+func (l *List) Query(base string, args ...any) (string, []any) {
+	conditions := l.conditions
+	offset := len(args)
+	args = append(args, l.args...)
+	sort := l.spec.Sorts[l.sortKey]
+
+	if l.cursor != nil {
+		operator := ">"
+		if l.desc {
+			operator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, %s) %s (?::%s, ?::uuid)", sort.Column, l.spec.IDColumn, operator, sort.Type))
+		args = append(args, l.cursor.Value, l.cursor.ID)
+	}
+
+	direction := "ASC"
+	if l.desc {
+		direction = "DESC"
+	}
+	query := base + joinConditions(conditions, offset) +
+		fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT %d", sort.Column, direction, l.spec.IDColumn, direction, l.Limit+1)
+	return query, args
+}
+
+// Requête du nombre total d'éléments filtrés : `base` est un SELECT COUNT(*) ... WHERE ...
+func (l *List) CountQuery(base string, args ...any) (string, []any) {
+	return base + joinConditions(l.conditions, len(args)), append(args, l.args...)
+}
+
+// Première page (sans curseur) : le total est calculé
+func (l *List) First() bool {
+	return l.cursor == nil
+}
+
+// Valeur de tri à sélectionner en dernière colonne, relue par Row pour le curseur suivant
+func (l *List) SortValue() string {
+	return "(" + l.spec.Sorts[l.sortKey].Column + ")::text"
+}
+
+// Ligne lue : false pour la ligne en trop, qui signale seulement une page suivante
+func (l *List) Row(sortValue, id string) bool {
+	l.rows++
+	if l.rows > l.Limit {
+		return false
+	}
+	l.last = listCursor{Sort: l.sort(), Value: sortValue, ID: id}
+	return true
+}
+
+// Pagination de la réponse (curseur suivant s'il reste des éléments)
+func (l *List) Page() models.Pagination {
+	page := models.Pagination{Limit: l.Limit, Sort: l.sort(), HasMore: l.rows > l.Limit}
+	if page.HasMore {
+		data, _ := json.Marshal(l.last)
+		page.NextCursor = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return page
+}
+
+func (l *List) sort() string {
+	if l.desc {
+		return "-" + l.sortKey
+	}
+	return l.sortKey
+}
+
+func decodeListCursor(value string) (listCursor, error) {
+	var cursor listCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.Sort == "" || cursor.ID == "" {
+		return cursor, fmt.Errorf("[pagination.go -> decodeListCursor()] -> Curseur incomplet")
+	}
+	return cursor, nil
+}
+
+// Date (YYYY-MM-DD, minuit UTC) ou horodatage RFC 3339
+func parseListTime(value string) (time.Time, bool, error) {
+	if at, err := time.Parse(time.DateOnly, value); err == nil {
+		return at, true, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	return at, false, err
+}
+
+// Conditions ajoutées à la clause WHERE, les "?" numérotés ($n) à la suite des paramètres de la requête de base
+func joinConditions(conditions []string, offset int) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	n := offset
+	for _, char := range " AND " + strings.Join(conditions, " AND ") {
+		if char == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
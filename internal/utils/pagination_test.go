@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testListSpec = ListSpec{
+	Sorts: map[string]ListSort{
+		"created_at": {Column: "created_at", Type: "timestamptz"},
+		"name":       {Column: "lower(name)", Type: "text"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+	Filters: map[string]ListFilter{
+		"status": {Column: "status", Values: []string{"active", "archived"}},
+	},
+}
+
+func TestDecodeListCursor(t *testing.T) {
+	encode := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+
+	tests := []struct {
+		name    string
+		value   string
+		want    listCursor
+		wantErr bool
+	}{
+		{"complet", encode(`{"s":"-created_at","v":"2025-01-02T03:04:05Z","id":"42"}`), listCursor{Sort: "-created_at", Value: "2025-01-02T03:04:05Z", ID: "42"}, false},
+		{"valeur vide", encode(`{"s":"name","v":"","id":"42"}`), listCursor{Sort: "name", ID: "42"}, false},
+		{"base64 invalide", "%%%", listCursor{}, true},
+		{"base64 avec padding", base64.URLEncoding.EncodeToString([]byte(`{"s":"name","v":"a","id":"1"}`)), listCursor{}, true},
+		{"JSON invalide", encode(`{"s":`), listCursor{}, true},
+		{"sans tri", encode(`{"v":"a","id":"42"}`), listCursor{}, true},
+		{"sans ID", encode(`{"s":"name","v":"a"}`), listCursor{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := decodeListCursor(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("erreur attendue, curseur lu : %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erreur inattendue : %v", err)
+			}
+			if got != test.want {
+				t.Fatalf("curseur %+v, attendu %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJoinConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []string
+		offset     int
+		want       string
+	}{
+		{"aucune condition", nil, 3, ""},
+		{"sans paramètre de base", []string{"status = ?"}, 0, " AND status = $1"},
+		{"à la suite des paramètres de base", []string{"status = ANY(?::text[])", "created_at >= ?"}, 2, " AND status = ANY($3::text[]) AND created_at >= $4"},
+		{"plusieurs paramètres par condition", []string{"(created_at, id) < (?::timestamptz, ?::uuid)"}, 1, " AND (created_at, id) < ($2::timestamptz, $3::uuid)"},
+		{"sans paramètre", []string{"deleted_at IS NULL"}, 1, " AND deleted_at IS NULL"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := joinConditions(test.conditions, test.offset); got != test.want {
+				t.Fatalf("%q, attendu %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Le curseur de Page() est relu par ParseList et reprend après le dernier élément retourné
+func TestListCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		sortValue string
+		wantSort  string
+		wantQuery string
+	}{
+		{"tri par défaut (décroissant)", "limit=2", "2025-01-02 03:04:05+00", "-created_at",
+			"SELECT * FROM t WHERE UserId = $1 AND (created_at, id) < ($2::timestamptz, $3::uuid) ORDER BY created_at DESC, id DESC LIMIT 3"},
+		{"tri croissant et filtre", "limit=2&sort=name&status=active", "boulangerie", "name",
+			"SELECT * FROM t WHERE UserId = $1 AND status = ANY($2::text[]) AND (lower(name), id) > ($3::text, $4::uuid) ORDER BY lower(name) ASC, id ASC LIMIT 3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list, ok := ParseList(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/list?"+test.query, nil), testListSpec)
+			if !ok {
+				t.Fatal("paramètres refusés")
+			}
+			for i, id := range []string{"id-1", "id-2", "id-3"} {
+				if kept := list.Row(test.sortValue+"-"+id, id); kept != (i < 2) {
+					t.Fatalf("ligne %d conservée : %v", i, kept)
+				}
+			}
+			page := list.Page()
+			if !page.HasMore || page.NextCursor == "" || page.Sort != test.wantSort {
+				t.Fatalf("page %+v", page)
+			}
+
+			query := "limit=2&cursor=" + page.NextCursor
+			if strings.Contains(test.query, "status=") {
+				query += "&status=active"
+			}
+			next, ok := ParseList(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/list?"+query, nil), testListSpec)
+			if !ok {
+				t.Fatal("curseur refusé")
+			}
+			sql, args := next.Query("SELECT * FROM t WHERE UserId = $1", "user")
+			if sql != test.wantQuery {
+				t.Fatalf("requête :\n%s\nattendue :\n%s", sql, test.wantQuery)
+			}
+			if last := args[len(args)-2:]; !reflect.DeepEqual(last, []any{test.sortValue + "-id-2", "id-2"}) {
+				t.Fatalf("paramètres du curseur %v", last)
+			}
+		})
+	}
+}
+
+func TestParseListInvalid(t *testing.T) {
+	other := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name","v":"a","id":"1"}`))
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"limite trop grande", "limit=101"},
+		{"limite nulle", "limit=0"},
+		{"tri inconnu", "sort=phone"},
+		{"curseur illisible", "cursor=abc"},
+		{"curseur d'un autre tri", "sort=-created_at&cursor=" + other},
+		{"filtre hors liste", "status=deleted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if _, ok := ParseList(recorder, httptest.NewRequest(http.MethodGet, "/list?"+test.query, nil), testListSpec); ok {
+				t.Fatal("paramètres acceptés")
+			}
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("statut %d, 400 attendu", recorder.Code)
+			}
+		})
+	}
+}
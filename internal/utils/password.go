@@ -1,11 +1,73 @@
 package utils
 
 import (
+	"bufio"
+	_ "embed"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
 
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// bcrypt ignore (et x/crypto refuse) tout ce qui dépasse 72 octets
+const bcryptMaxBytes = 72
+
+// Mots de passe les plus fréquents dans les fuites de données publiques (un par ligne, en minuscules)
+//
+//go:embed breached_passwords.txt
+var bundledBreachedPasswords string
+
+// Politique de mot de passe (inscription, réinitialisation, changement)
+var passwordPolicy = struct {
+	minLength     int
+	minClasses    int
+	checkBreached bool
+	breached      map[string]struct{}
+}{minLength: 10, minClasses: 3, checkBreached: true}
+
+/*
+Charger la politique de mot de passe
+La liste embarquée peut être complétée par un fichier (AUTH_PASSWORD_BREACHED_FILE, un mot de passe par ligne)
+*/
+func InitPasswordPolicy(cfg *config.Config) error {
+	passwordPolicy.minLength = cfg.Auth.PasswordMinLength
+	passwordPolicy.minClasses = cfg.Auth.PasswordMinClasses
+	passwordPolicy.checkBreached = cfg.Auth.PasswordCheckBreached
+	passwordPolicy.breached = nil
+
+	if !passwordPolicy.checkBreached {
+		return nil
+	}
+
+	breached := map[string]struct{}{}
+	addBreachedPasswords(breached, bundledBreachedPasswords)
+
+	if cfg.Auth.PasswordBreachedFile != "" {
+		content, err := os.ReadFile(cfg.Auth.PasswordBreachedFile)
+		if err != nil {
+			return fmt.Errorf("[password.go -> InitPasswordPolicy()] -> Impossible de lire la liste de mots de passe compromis : %w", err)
+		}
+		addBreachedPasswords(breached, string(content))
+	}
+
+	passwordPolicy.breached = breached
+	return nil
+}
+
+func addBreachedPasswords(set map[string]struct{}, list string) {
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = struct{}{}
+		}
+	}
+}
+
 // HashPassword converts a plain text password into a hashed version
 func HashPassword(password string) (string, error) {
 	// Cost factor of 12 provides a good balance between security and performance
@@ -22,14 +84,78 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-// ValidatePassword checks password complexity requirements
+/*
+Mot de passe refusé par la politique
+`Code` et `MessageKey` (+ `Args`) alimentent directement le détail de l'erreur de validation (champ "password")
+*/
+type PasswordError struct {
+	Code       string
+	MessageKey string
+	Args       []any
+}
+
+func (e *PasswordError) Error() string {
+	return "[password.go -> ValidatePassword()] -> Mot de passe refusé : " + e.Code
+}
+
+/*
+Vérifier le mot de passe avec la politique configurée
+- Longueur minimum (en caractères) et maximum de 72 octets (limite de bcrypt)
+- Nombre de types de caractères différents (minuscules, majuscules, chiffres, symboles)
+- Absent de la liste des mots de passe compromis
+Retourne une *PasswordError
+*/
 func ValidatePassword(password string) error {
-	if len(password) < 6 {
-		return errors.New("[password.go] -> Le mot de passe doit avoir au moins caractères.")
+	if len([]rune(password)) < passwordPolicy.minLength {
+		return &PasswordError{Code: "too_short", MessageKey: "auth.password_too_short", Args: []any{passwordPolicy.minLength}}
+	}
+
+	if len(password) > bcryptMaxBytes {
+		return &PasswordError{Code: "too_long", MessageKey: "auth.password_too_long", Args: []any{bcryptMaxBytes}}
+	}
+
+	if characterClasses(password) < passwordPolicy.minClasses {
+		return &PasswordError{Code: "too_weak", MessageKey: "auth.password_classes", Args: []any{passwordPolicy.minClasses}}
 	}
 
-	if len(password) > 100 {
-		return errors.New("[password.go] -> Le mot de passe ne doit pas dépasser 100 caractères.")
+	if passwordPolicy.checkBreached {
+		if _, found := passwordPolicy.breached[strings.ToLower(password)]; found {
+			return &PasswordError{Code: "breached", MessageKey: "auth.password_breached"}
+		}
 	}
 	return nil
 }
+
+// Détail de l'erreur de validation pour le champ "password"
+func PasswordFieldError(err error) models.FieldError {
+	var passwordErr *PasswordError
+	if errors.As(err, &passwordErr) {
+		return models.FieldError{Field: "password", Code: passwordErr.Code, Message: passwordErr.MessageKey, Args: passwordErr.Args}
+	}
+	return models.FieldError{Field: "password", Code: "invalid", Message: "auth.password_invalid"}
+}
+
+// Nombre de types de caractères présents : minuscules, majuscules, chiffres, symboles (espaces compris)
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			lower = true
+		case unicode.IsUpper(char):
+			upper = true
+		case unicode.IsDigit(char):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+)
+
+// Politique appliquée pendant le test, celle d'origine est rétablie à la fin
+func setPasswordPolicy(t *testing.T, minLength, minClasses int, checkBreached bool, breachedFile string) {
+	t.Helper()
+	previous := passwordPolicy
+	t.Cleanup(func() { passwordPolicy = previous })
+
+	var cfg config.Config
+	cfg.Auth.PasswordMinLength = minLength
+	cfg.Auth.PasswordMinClasses = minClasses
+	cfg.Auth.PasswordCheckBreached = checkBreached
+	cfg.Auth.PasswordBreachedFile = breachedFile
+	if err := InitPasswordPolicy(&cfg); err != nil {
+		t.Fatalf("politique de mot de passe : %v", err)
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	breachedFile := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(breachedFile, []byte("# commentaire\nTr0ub4dor&3\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		password      string
+		checkBreached bool
+		want          string // code de PasswordError, vide si accepté
+	}{
+		{"valide", "Correct-Horse-7", true, ""},
+		{"trop court", "Ab1!", true, "too_short"},
+		{"longueur en caractères", "Éléphant1!", true, ""},
+		{"plus de 72 octets", strings.Repeat("Aa1!€", 15), true, "too_long"},
+		{"une seule classe", "abcdefghijkl", true, "too_weak"},
+		{"deux classes", "abcdefgh12", true, "too_weak"},
+		{"espace compté comme symbole", "abcdef gh12", true, ""},
+		{"liste embarquée, casse ignorée", "Password123", true, "breached"},
+		{"liste complémentaire", "Tr0ub4dor&3", true, "breached"},
+		{"vérification désactivée", "Password123", false, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := ""
+			if test.checkBreached {
+				file = breachedFile
+			}
+			setPasswordPolicy(t, 10, 3, test.checkBreached, file)
+
+			err := ValidatePassword(test.password)
+			if test.want == "" {
+				if err != nil {
+					t.Fatalf("mot de passe refusé : %v", err)
+				}
+				return
+			}
+			if detail := PasswordFieldError(err); detail.Code != test.want || detail.Field != "password" {
+				t.Fatalf("détail %+v, code %q attendu", detail, test.want)
+			}
+		})
+	}
+}
+
+func TestInitPasswordPolicyMissingFile(t *testing.T) {
+	previous := passwordPolicy
+	t.Cleanup(func() { passwordPolicy = previous })
+
+	var cfg config.Config
+	cfg.Auth.PasswordCheckBreached = true
+	cfg.Auth.PasswordBreachedFile = filepath.Join(t.TempDir(), "absent.txt")
+	if err := InitPasswordPolicy(&cfg); err == nil {
+		t.Fatal("erreur attendue pour un fichier absent")
+	}
+}
+
+func TestCharacterClasses(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcDEF", 2},
+		{"abcDEF123", 3},
+		{"abcDEF123!", 4},
+		{"éÉ٣", 3}, // lettres accentuées et chiffres non latins
+		{"   ", 1},
+	}
+	for _, test := range tests {
+		if got := characterClasses(test.password); got != test.want {
+			t.Errorf("characterClasses(%q) = %d, attendu %d", test.password, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
+)
+
+// Formats d'affiche (points PDF, 1/72 de pouce) : largeur, hauteur et marge
+var QRPosterPapers = map[string]struct{ width, height, margin float64 }{
+	"a4": {595.28, 841.89, 48},
+	"a5": {419.53, 595.28, 32},
+}
+
+/*
+Affiche imprimable (PDF) : nom de l'entreprise, QR Code vectoriel, message personnalisé et invitation à scanner
+PDF 1.4 écrit à la main : polices standard Helvetica (encodage WinAnsi, accents français et espagnols),
+logo intégré en image RGB compressée avec sa transparence
+*/
+type QRPoster struct {
+	Code    QRCode
+	Paper   string // a4, a5
+	Title   string // nom de l'entreprise
+	Message string // message personnalisé (facultatif)
+	Footer  string // invitation à scanner, dans la langue de l'entreprise
+	Link    string // lien encodé, imprimé en petit sous l'invitation
+}
+
+func (poster *QRPoster) PDF() ([]byte, error) {
+	paper, ok := QRPosterPapers[poster.Paper]
+	if !ok {
+		return nil, fmt.Errorf("[qrPoster.go -> PDF()] -> Format de papier inconnu %q", poster.Paper)
+	}
+	modules, err := poster.Code.Modules()
+	if err != nil {
+		return nil, err
+	}
+
+	scale := paper.width / 595.28
+	titleSize, messageSize, footerSize, linkSize := 30*scale, max(15*scale, 11), max(13*scale, 10), max(9*scale, 7)
+	textWidth := paper.width - 2*paper.margin
+
+	titleLines := wrapText(poster.Title, helveticaBoldWidths, titleSize, textWidth, 2)
+	messageLines := wrapText(poster.Message, helveticaWidths, messageSize, textWidth, 6)
+
+	var content bytes.Buffer
+	y := paper.height - paper.margin
+
+	// Nom de l'entreprise
+	for _, line := range titleLines {
+		y -= titleSize
+		writeCenteredText(&content, "F2", line, helveticaBoldWidths, titleSize, paper.width, y)
+		y -= titleSize * 0.25
+	}
+
+	// Pied de page (invitation + lien), depuis le bas
+	bottom := paper.margin
+	writeCenteredText(&content, "F1", poster.Link, helveticaWidths, linkSize, paper.width, bottom)
+	bottom += linkSize * 1.6
+	writeCenteredText(&content, "F2", poster.Footer, helveticaBoldWidths, footerSize, paper.width, bottom)
+	bottom += footerSize * 2
+
+	// Message personnalisé au-dessus du pied de page
+	for i := len(messageLines) - 1; i >= 0; i-- {
+		writeCenteredText(&content, "F1", messageLines[i], helveticaWidths, messageSize, paper.width, bottom)
+		bottom += messageSize * 1.35
+	}
+	if len(messageLines) > 0 {
+		bottom += messageSize
+	}
+
+	// QR Code : le plus grand carré possible dans l'espace restant
+	side := min(textWidth, y-bottom-titleSize*0.5)
+	unit := side / float64(len(modules))
+	left := (paper.width - side) / 2
+	top := bottom + (y-bottom-side)/2 + side
+
+	content.WriteString("0 g\n")
+	for row, line := range modules {
+		for x := 0; x < len(line); x++ {
+			if !line[x] {
+				continue
+			}
+			start := x
+			for x < len(line) && line[x] {
+				x++
+			}
+			fmt.Fprintf(&content, "%.3f %.3f %.3f %.3f re\n", left+float64(start)*unit, top-float64(row+1)*unit, float64(x-start)*unit, unit)
+		}
+	}
+	content.WriteString("f\n")
+
+	var logo *image.NRGBA
+	if poster.Code.Logo != nil {
+		x, yOffset, logoSide := poster.Code.logoBox(len(modules), side)
+		fmt.Fprintf(&content, "1 g %.3f %.3f %.3f %.3f re f\n", left+x, top-yOffset-logoSide, logoSide, logoSide)
+
+		logo = storage.Resize(poster.Code.Logo, 512)
+		bounds := logo.Bounds()
+		inner := logoSide * 0.84
+		width, height := inner, inner
+		if bounds.Dx() > bounds.Dy() {
+			height = inner * float64(bounds.Dy()) / float64(bounds.Dx())
+		} else {
+			width = inner * float64(bounds.Dx()) / float64(bounds.Dy())
+		}
+		fmt.Fprintf(&content, "q %.3f 0 0 %.3f %.3f %.3f cm /Im1 Do Q\n",
+			width, height, left+x+(logoSide-width)/2, top-yOffset-logoSide+(logoSide-height)/2)
+	}
+
+	return writePDF(paper.width, paper.height, poster.Title, content.Bytes(), logo)
+}
+
+// Assemblage du fichier : objets numérotés, table des références croisées
+func writePDF(width, height float64, title string, content []byte, logo *image.NRGBA) ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string, stream []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s", len(offsets), body)
+		if stream != nil {
+			out.WriteString("\nstream\n")
+			out.Write(stream)
+			out.WriteString("\nendstream")
+		}
+		out.WriteString("\nendobj\n")
+	}
+
+	compressed, err := deflate(content)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := "/Font << /F1 5 0 R /F2 6 0 R >>"
+	if logo != nil {
+		resources += " /XObject << /Im1 8 0 R >>"
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	object("<< /Type /Pages /Kids [3 0 R] /Count 1 >>", nil)
+	object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << %s >> /Contents 4 0 R >>", width, height, resources), nil)
+	object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(compressed)), compressed)
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>", nil)
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>", nil)
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Waitify) >>", pdfString(title)), nil)
+
+	if logo != nil {
+		bounds := logo.Bounds()
+		rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+		alpha := make([]byte, 0, bounds.Dx()*bounds.Dy())
+		for i := 0; i < len(logo.Pix); i += 4 {
+			rgb = append(rgb, logo.Pix[i], logo.Pix[i+1], logo.Pix[i+2])
+			alpha = append(alpha, logo.Pix[i+3])
+		}
+		rgbStream, err := deflate(rgb)
+		if err != nil {
+			return nil, err
+		}
+		alphaStream, err := deflate(alpha)
+		if err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /SMask 9 0 R /Length %d >>",
+			bounds.Dx(), bounds.Dy(), len(rgbStream)), rgbStream)
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>",
+			bounds.Dx(), bounds.Dy(), len(alphaStream)), alphaStream)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 7 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	writer := zlib.NewWriter(&out)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("[qrPoster.go -> deflate()] -> %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("[qrPoster.go -> deflate()] -> %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func writeCenteredText(out *bytes.Buffer, font, text string, widths []int, size, pageWidth, y float64) {
+	if text == "" {
+		return
+	}
+	x := (pageWidth - textWidth(text, widths, size)) / 2
+	fmt.Fprintf(out, "BT /%s %.2f Tf %.3f %.3f Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+/*
+Découper un texte en lignes de `maxWidth` points au plus (coupure aux espaces et retours à la ligne)
+Au-delà de `maxLines`, la dernière ligne est tronquée avec "..."
+*/
+func wrapText(text string, widths []int, size, maxWidth float64, maxLines int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := strings.TrimSpace(line + " " + word)
+			if line != "" && textWidth(candidate, widths, size) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line = candidate
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		last := []rune(lines[maxLines-1])
+		for len(last) > 0 && textWidth(string(last)+"...", widths, size) > maxWidth {
+			last = last[:len(last)-1]
+		}
+		lines[maxLines-1] = strings.TrimSpace(string(last)) + "..."
+	}
+
+	// Mot plus long qu'une ligne : tronqué
+	for i, line := range lines {
+		runes := []rune(line)
+		for len(runes) > 1 && textWidth(string(runes), widths, size) > maxWidth {
+			runes = runes[:len(runes)-1]
+		}
+		lines[i] = string(runes)
+	}
+	return lines
+}
+
+func textWidth(text string, widths []int, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		total += glyphWidth(r, widths)
+	}
+	return float64(total) * size / 1000
+}
+
+// Largeur d'un caractère (1/1000 de la taille) : caractères accentués mesurés comme leur lettre de base
+func glyphWidth(r rune, widths []int) int {
+	if base, ok := accentBase[r]; ok {
+		r = base
+	}
+	if r >= 32 && r < 127 {
+		return widths[r-32]
+	}
+	return 556
+}
+
+// Chaîne PDF encodée en WinAnsi (caractères absents remplacés par "?")
+func pdfString(text string) string {
+	var out strings.Builder
+	for _, r := range text {
+		var c byte
+		switch {
+		case r < 128:
+			c = byte(r)
+		case r >= 0xa0 && r <= 0xff:
+			c = byte(r)
+		default:
+			special, ok := winAnsiSpecials[r]
+			if !ok {
+				special = '?'
+			}
+			c = special
+		}
+		switch c {
+		case '(', ')', '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case '\n', '\r', '\t':
+			out.WriteByte(' ')
+		default:
+			if c < 32 {
+				continue
+			}
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// Caractères WinAnsi hors Latin-1 (plage 0x80-0x9f)
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99, 'œ': 0x9c, 'Œ': 0x8c, 'Ÿ': 0x9f,
+}
+
+var accentBase = func() map[rune]rune {
+	groups := map[rune]string{
+		'a': "àáâãäå", 'A': "ÀÁÂÃÄÅ", 'c': "ç", 'C': "Ç", 'e': "èéêë", 'E': "ÈÉÊË",
+		'i': "ìíîï", 'I': "ÌÍÎÏ", 'n': "ñ", 'N': "Ñ", 'o': "òóôõö", 'O': "ÒÓÔÕÖ",
+		'u': "ùúûü", 'U': "ÙÚÛÜ", 'y': "ýÿ", 'Y': "ÝŸ", '\'': "’‘", '"': "“”«»", '-': "–",
+	}
+	bases := make(map[rune]rune)
+	for base, accented := range groups {
+		for _, r := range accented {
+			bases[r] = base
+		}
+	}
+	return bases
+}()
+
+// Largeurs des caractères 32 à 126 (métriques Adobe des polices standard)
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = []int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
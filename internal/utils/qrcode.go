@@ -1,22 +1,152 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
 
+	"github.com/StevenYAMBOS/waitify-api/internal/storage"
 	qrcode "github.com/skip2/go-qrcode"
 )
 
+// Niveaux de correction d'erreur (part du code qui peut être abîmée ou masquée : 7 %, 15 %, 25 %, 30 %)
+var QRCodeLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Côté du logo superposé, en part du côté du code (hors marge) : environ 5 % de la surface, couvert par le niveau H
+const qrLogoRatio = 0.22
+
 // Modèle d'un QR Code
 type QRCode struct {
 	Content string
 	Size    int
+	Level   string      // L, M, Q, H (M par défaut)
+	Logo    image.Image // logo superposé au centre (niveau H imposé)
 }
 
-// Générer un QR Code
+// Générer un QR Code (PNG)
 func (code *QRCode) Generate() ([]byte, error) {
-	qrCode, err := qrcode.Encode(code.Content, qrcode.Medium, code.Size)
+	qr, err := code.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, code.Size, code.Size))
+	draw.Draw(img, img.Bounds(), qr.Image(code.Size), image.Point{}, draw.Src)
+
+	if code.Logo != nil {
+		x, y, side := code.logoBox(len(qr.Bitmap()), float64(code.Size))
+		box := image.Rect(int(x), int(y), int(x+side), int(y+side))
+		draw.Draw(img, box, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+		logo := storage.Resize(code.Logo, int(side*0.84))
+		bounds := logo.Bounds()
+		offset := image.Pt(box.Min.X+(box.Dx()-bounds.Dx())/2, box.Min.Y+(box.Dy()-bounds.Dy())/2)
+		draw.Draw(img, bounds.Add(offset), logo, image.Point{}, draw.Over)
+	}
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		return nil, fmt.Errorf("[qrcode.go -> Generate()] -> %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+/*
+QR Code vectoriel (SVG) : une unité par module, `Size` pixels à l'affichage
+Les modules noirs consécutifs d'une ligne forment un seul rectangle
+*/
+func (code *QRCode) SVG() ([]byte, error) {
+	qr, err := code.encode()
+	if err != nil {
+		return nil, err
+	}
+	modules := qr.Bitmap()
+	n := len(modules)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`, n, n, code.Size, code.Size)
+	fmt.Fprintf(&out, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range modules {
+		for x := 0; x < n; x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < n && row[x] {
+				x++
+			}
+			fmt.Fprintf(&out, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	out.WriteString(`"/>`)
+
+	if code.Logo != nil {
+		x, y, side := code.logoBox(n, float64(n))
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, storage.Resize(code.Logo, 256)); err != nil {
+			return nil, fmt.Errorf("[qrcode.go -> SVG()] -> %w", err)
+		}
+		padding := side * 0.08
+		fmt.Fprintf(&out, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="#fff"/>`, x, y, side, side)
+		fmt.Fprintf(&out, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+			x+padding, y+padding, side-2*padding, side-2*padding, base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	out.WriteString("</svg>")
+	return out.Bytes(), nil
+}
+
+// Modules du code, marge de 4 modules comprise (true = noir)
+func (code *QRCode) Modules() ([][]bool, error) {
+	qr, err := code.encode()
+	if err != nil {
+		return nil, err
+	}
+	return qr.Bitmap(), nil
+}
+
+func (code *QRCode) encode() (*qrcode.QRCode, error) {
+	level, ok := QRCodeLevels[strings.ToUpper(code.Level)]
+	if code.Level == "" {
+		level, ok = qrcode.Medium, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("[qrcode.go -> encode()] -> Niveau de correction inconnu %q", code.Level)
+	}
+	// Le logo masque le centre du code : seul le niveau H le tolère à coup sûr
+	if code.Logo != nil {
+		level = qrcode.Highest
+	}
+
+	qr, err := qrcode.New(code.Content, level)
 	if err != nil {
 		return nil, fmt.Errorf("Erreur lors de la génération du QR Code : %v", err)
 	}
-	return qrCode, nil
+	return qr, nil
+}
+
+/*
+Emplacement du logo (coin haut gauche et côté) pour un code de `modules` modules dessiné sur `size` unités
+Le côté est arrondi au module : le logo ne coupe jamais un module en deux
+*/
+func (code *QRCode) logoBox(modules int, size float64) (x, y, side float64) {
+	unit := size / float64(modules)
+	inner := modules - 8 // marge de 4 modules de chaque côté
+	count := int(float64(inner) * qrLogoRatio)
+	if (modules-count)%2 != 0 {
+		count++
+	}
+	side = float64(count) * unit
+	x = float64(modules-count) / 2 * unit
+	return x, x, side
 }
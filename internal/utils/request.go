@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Adresse IP du client, sans le port (cf. ClientIPMiddleware derrière un load balancer)
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ETag d'une ressource versionnée par sa date de modification (updated_at, à la microseconde comme PostgreSQL)
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+/*
+Versions acceptées par l'en-tête If-Match (liste d'ETag séparés par des virgules)
+`conditional` est faux sans en-tête ou avec "*" : la modification n'est pas conditionnée
+Un ETag illisible ne correspond à aucune version
+*/
+func IfMatchVersions(r *http.Request) (versions []time.Time, conditional bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue // comparaison forte exigée par If-Match
+		}
+		micros, err := strconv.ParseInt(strings.Trim(tag, `"`), 36, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, time.UnixMicro(micros))
+	}
+	return versions, true
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	first := time.Date(2025, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	second := first.Add(time.Microsecond)
+
+	tests := []struct {
+		name            string
+		header          string
+		wantVersions    []time.Time
+		wantConditional bool
+	}{
+		{"sans en-tête", "", nil, false},
+		{"étoile", "*", nil, false},
+		{"un ETag", ETag(first), []time.Time{first}, true},
+		{"plusieurs ETag", ETag(first) + ", " + ETag(second), []time.Time{first, second}, true},
+		{"ETag faible ignoré", "W/" + ETag(first) + ", " + ETag(second), []time.Time{second}, true},
+		{"ETag illisible", `"???"`, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPatch, "/business/1", nil)
+			if test.header != "" {
+				request.Header.Set("If-Match", test.header)
+			}
+			versions, conditional := IfMatchVersions(request)
+			if conditional != test.wantConditional {
+				t.Fatalf("conditionnelle : %v, attendu %v", conditional, test.wantConditional)
+			}
+			if len(versions) != len(test.wantVersions) {
+				t.Fatalf("versions %v, attendu %v", versions, test.wantVersions)
+			}
+			for i, version := range versions {
+				if !version.Equal(test.wantVersions[i]) {
+					t.Fatalf("version %d : %v, attendu %v", i, version, test.wantVersions[i])
+				}
+			}
+		})
+	}
+}
+
+// Deux dates différentes à la microseconde près (précision de PostgreSQL) ont des ETag différents
+func TestETag(t *testing.T) {
+	at := time.Date(2025, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	tests := []struct {
+		name string
+		a, b time.Time
+		same bool
+	}{
+		{"même date", at, at, true},
+		{"autre fuseau", at, at.In(time.FixedZone("UTC+2", 2*3600)), true},
+		{"nanosecondes ignorées", at, at.Add(999 * time.Nanosecond), true},
+		{"une microseconde d'écart", at, at.Add(time.Microsecond), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if same := ETag(test.a) == ETag(test.b); same != test.same {
+				t.Fatalf("%s et %s identiques : %v", ETag(test.a), ETag(test.b), same)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/i18n"
+	"github.com/StevenYAMBOS/waitify-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// Réponse JSON
+func WriteJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		slog.Error("Erreur lors de l'encodage de la réponse", "origin", "response.go -> WriteJSON()", "error", err)
+	}
+}
+
+// Traduire un message du catalogue dans la langue de la requête
+func T(r *http.Request, key string, args ...any) string {
+	return i18n.T(i18n.FromContext(r.Context()), key, args...)
+}
+
+/*
+Réponse d'erreur au format {"error": {...}}
+`messageKey` et le `Message` de chaque détail sont des clés du catalogue i18n, traduites dans la langue de la requête
+*/
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, messageKey string, details ...models.FieldError) {
+	for i := range details {
+		details[i].Message = T(r, details[i].Message, details[i].Args...)
+	}
+
+	WriteJSON(w, status, models.ErrorResponse{
+		Error: models.APIError{
+			Code:      code,
+			Message:   T(r, messageKey),
+			Details:   details,
+			RequestID: RequestIDFromContext(r.Context()),
+		},
+	})
+}
+
+/*
+Décoder le corps JSON de la requête dans `dst`
+- Champ inconnu ou plusieurs objets JSON -> 400
+- Corps plus gros que la limite de la route (BodyLimitMiddleware) -> 413
+Retourne false si une réponse d'erreur a déjà été envoyée
+*/
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("plusieurs valeurs JSON dans le corps de la requête")
+	}
+	if err != nil {
+		WriteBodyError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// Erreur de lecture du corps de la requête (JSON ou formulaire multipart)
+func WriteBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.InfoContext(r.Context(), "Mauvais corps de requête", "error", err)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		WriteError(w, r, http.StatusRequestEntityTooLarge, models.ErrCodePayloadTooLarge, "error.payload_too_large")
+		return
+	}
+
+	// Le package encoding/json ne fournit pas d'erreur typée pour les champs inconnus
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidBody, "error.invalid_body",
+			models.FieldError{Field: strings.Trim(field, `"`), Code: "unknown_field", Message: "error.unknown_field"})
+		return
+	}
+
+	WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidBody, "error.invalid_body")
+}
+
+// Erreur interne : on journalise la cause mais on ne l'expose jamais au client
+func WriteInternalError(w http.ResponseWriter, r *http.Request, context string, err error) {
+	slog.ErrorContext(r.Context(), "Erreur interne", "origin", context, "error", err)
+	WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "error.internal")
+}
+
+// Champs concernés par les contraintes de la base de données (cf. DATABASE.md)
+var constraintFields = map[string]string{
+	"users_email_key":                    "email",
+	"check_email_format":                 "email",
+	"check_phone_number_format":          "phone_number",
+	"check_auth_provider":                "auth_provider",
+	"businesses_qr_code_token_key":       "qr_code_token",
+	"idx_businesses_qr_token":            "qr_code_token",
+	"check_business_type":                "business_type",
+	"check_service_time_positive":        "average_service_time",
+	"check_max_queue_reasonable":         "max_queue_size",
+	"check_timeout_reasonable":           "client_timeout_minutes",
+	"check_phone_number_format_business": "phone_number",
+	"check_phone_format":                 "phone",
+	"check_position_positive":            "position",
+	"check_status_valid":                 "status",
+	"check_default_language":             "default_language",
+	"check_language_valid":               "language",
+	"businesses_userid_fkey":             "UserId",
+	"queue_entries_businessid_fkey":      "business_id",
+}
+
+/*
+Traduire une erreur de la base de données en réponse HTTP
+- Violation d'unicité -> 409
+- Violation de contrainte (CHECK, NOT NULL, clé étrangère) -> 422
+- Paramètre au mauvais format (UUID invalide...) -> 400
+- Aucune ligne -> 404
+- Tout le reste -> 500 sans détail
+*/
+func WriteDBError(w http.ResponseWriter, r *http.Request, context string, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "error.not_found")
+		return
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		WriteInternalError(w, r, context, err)
+		return
+	}
+
+	slog.WarnContext(r.Context(), "Erreur base de données", "origin", context, "pg_code", string(pqErr.Code), "constraint", pqErr.Constraint, "error", pqErr)
+
+	field := constraintFields[pqErr.Constraint]
+	if field == "" {
+		field = pqErr.Column
+	}
+
+	var details []models.FieldError
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		if field != "" {
+			details = append(details, models.FieldError{Field: field, Code: "already_exists", Message: "error.value_taken"})
+		}
+		WriteError(w, r, http.StatusConflict, models.ErrCodeConflict, "error.already_exists", details...)
+	case "check_violation", "not_null_violation", "foreign_key_violation", "string_data_right_truncation":
+		if field != "" {
+			details = append(details, models.FieldError{Field: field, Code: "invalid", Message: "error.value_rejected"})
+		}
+		WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeUnprocessable, "error.constraint_violation", details...)
+	case "invalid_text_representation", "invalid_datetime_format":
+		WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidParameter, "error.invalid_parameter")
+	default:
+		WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "error.internal")
+	}
+}
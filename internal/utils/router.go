@@ -0,0 +1,22 @@
+package utils
+
+import "net/http"
+
+// Méthodes utilisées par les routes de l'API
+var routeMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+/*
+Méthodes pour lesquelles une route (autre que la route par défaut "/") correspond au chemin de la requête
+Sert au 405 (header Allow) et aux réponses preflight CORS
+*/
+func AllowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	var allowed []string
+	for _, method := range routeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/StevenYAMBOS/waitify-api/internal/config"
+)
+
+// Clé AES-256 des secrets chiffrés en base (secrets TOTP)
+var secretKey []byte
+
+/*
+Clé de chiffrement des secrets stockés en base
+AUTH_TOTP_ENCRYPTION_KEY (32 octets en base64) si fournie, sinon dérivée de JWT_SECRET :
+changer JWT_SECRET rend alors illisibles les secrets déjà enregistrés
+*/
+func InitSecretBox(cfg *config.Config) error {
+	if cfg.Auth.TOTPEncryptionKey == "" {
+		sum := sha256.Sum256([]byte("waitify-totp:" + cfg.JWT.Secret))
+		secretKey = sum[:]
+		return nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.Auth.TOTPEncryptionKey)
+	if err != nil || len(key) != 32 {
+		return errors.New("[secretbox.go -> InitSecretBox()] -> AUTH_TOTP_ENCRYPTION_KEY doit contenir 32 octets encodés en base64")
+	}
+	secretKey = key
+	return nil
+}
+
+// Chiffrer une valeur (AES-GCM, nonce aléatoire en préfixe, résultat en base64)
+func EncryptSecret(plaintext string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("[secretbox.go -> EncryptSecret()] -> %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Déchiffrer une valeur produite par EncryptSecret
+func DecryptSecret(ciphertext string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("[secretbox.go -> DecryptSecret()] -> Valeur chiffrée invalide")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("[secretbox.go -> DecryptSecret()] -> Déchiffrement impossible (clé modifiée ?) : %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func secretCipher() (cipher.AEAD, error) {
+	if secretKey == nil {
+		return nil, errors.New("[secretbox.go -> secretCipher()] -> Clé de chiffrement non initialisée")
+	}
+
+	block, err := aes.NewCipher(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("[secretbox.go -> secretCipher()] -> %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+/*
+Token aléatoire envoyé par email (vérification, réinitialisation du mot de passe)
+Retourne le token à envoyer (256 bits, base64 URL) et son empreinte SHA-256 à stocker en base :
+une fuite de la table ne permet pas d'utiliser les liens
+*/
+func GenerateOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("[tokens.go -> GenerateOpaqueToken()] -> Génération aléatoire impossible : %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashOpaqueToken(token), nil
+}
+
+// Empreinte SHA-256 (hexadécimal) d'un token reçu, à comparer avec celle stockée en base
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Paramètres TOTP compatibles avec toutes les applications (Google Authenticator, Authy, 1Password...)
+const (
+	totpPeriod    = 30 * time.Second
+	totpDigits    = 6
+	totpSkew      = 1 // pas de 30 s acceptés avant et après (horloge du téléphone décalée)
+	totpIssuer    = "Waitify"
+	recoveryCodes = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Secret TOTP aléatoire (160 bits, base32 sans padding), à saisir ou scanner dans l'application
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("[totp.go -> GenerateTOTPSecret()] -> Génération aléatoire impossible : %w", err)
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// URI "otpauth://" encodée dans le QR code d'enrôlement
+func TOTPURI(account, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", totpIssuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(totpDigits))
+	values.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+/*
+Vérifier un code TOTP (RFC 6238) à l'instant `now`
+Retourne le pas de temps du code accepté : il doit être enregistré pour refuser une seconde utilisation du même code
+*/
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Code à 6 chiffres pour un pas de temps (HOTP, RFC 4226)
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+/*
+Codes de secours à usage unique (format "xxxxx-xxxxx"), affichés une seule fois à l'utilisateur
+Seule leur empreinte est stockée (HashOpaqueToken)
+*/
+func GenerateRecoveryCodes() ([]string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789" // sans caractères ambigus (0/o, 1/l/i)
+
+	codes := make([]string, 0, recoveryCodes)
+	buf := make([]byte, 1)
+	for range recoveryCodes {
+		var code strings.Builder
+		for code.Len() < 11 {
+			if code.Len() == 5 {
+				code.WriteByte('-')
+			}
+			// Tirage sans biais : les octets au-delà du dernier multiple de len(alphabet) sont ignorés
+			for {
+				if _, err := rand.Read(buf); err != nil {
+					return nil, fmt.Errorf("[totp.go -> GenerateRecoveryCodes()] -> Génération aléatoire impossible : %w", err)
+				}
+				if int(buf[0]) < 256-256%len(alphabet) {
+					break
+				}
+			}
+			code.WriteByte(alphabet[int(buf[0])%len(alphabet)])
+		}
+		codes = append(codes, code.String())
+	}
+	return codes, nil
+}
+
+// Forme normalisée d'un code de secours saisi (casse et espaces ignorés)
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
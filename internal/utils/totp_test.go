@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// Secret des vecteurs de test de la RFC 6238 ("12345678901234567890", SHA-1)
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Vecteurs de la RFC 6238 (annexe B), limités aux 6 derniers chiffres
+func TestTOTPCode(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, test := range tests {
+		if got := totpCode(key, test.unix/30); got != test.want {
+			t.Errorf("t=%d : %s, attendu %s", test.unix, got, test.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / 30
+	key, _ := totpEncoding.DecodeString(rfc6238Secret)
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"code du pas actuel", rfc6238Secret, "005924", step, true},
+		{"espaces ignorés", rfc6238Secret, " 005 924 ", step, true},
+		{"secret en minuscules", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "005924", step, true},
+		{"pas précédent (horloge en retard)", rfc6238Secret, totpCode(key, step-1), step - 1, true},
+		{"pas suivant (horloge en avance)", rfc6238Secret, totpCode(key, step+1), step + 1, true},
+		{"deux pas d'écart", rfc6238Secret, totpCode(key, step-2), 0, false},
+		{"mauvais code", rfc6238Secret, "123456", 0, false},
+		{"trop court", rfc6238Secret, "05924", 0, false},
+		{"trop long", rfc6238Secret, "0059240", 0, false},
+		{"secret invalide", "pas du base32 !", "005924", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(test.secret, test.code, now)
+			if ok != test.wantOK || gotStep != test.wantStep {
+				t.Fatalf("(%d, %v), attendu (%d, %v)", gotStep, ok, test.wantStep, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q : %d octets, erreur %v", secret, len(key), err)
+	}
+	if _, ok := ValidateTOTP(secret, totpCode(key, time.Now().Unix()/30), time.Now()); !ok {
+		t.Fatal("code du secret généré refusé")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodes {
+		t.Fatalf("%d codes, %d attendus", len(codes), recoveryCodes)
+	}
+	format := regexp.MustCompile(`^[a-hjkmnp-z2-9]{5}-[a-hjkmnp-z2-9]{5}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q mal formé", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q en double", code)
+		}
+		seen[code] = true
+		if NormalizeRecoveryCode(code) != code {
+			t.Errorf("code %q modifié par la normalisation", code)
+		}
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"abcde-fghjk", "abcde-fghjk"},
+		{"ABCDE-FGHJK", "abcde-fghjk"},
+		{"  abcde-fghjk\n", "abcde-fghjk"},
+		{"abc de-fgh jk", "abcde-fghjk"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := NormalizeRecoveryCode(test.code); got != test.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, attendu %q", test.code, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Tâche de fond exécutée à intervalle régulier
+type Worker struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// État d'une tâche de fond
+type Status struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	LastRunAt time.Time `json:"last_run_at"`
+	LastError string    `json:"last_error,omitempty"`
+	Interval  string    `json:"interval"`
+
+	interval time.Duration
+}
+
+var (
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	statuses = map[string]*Status{}
+)
+
+// Lancer une tâche de fond, elle s'arrête quand `ctx` est annulé
+func Start(ctx context.Context, worker Worker) {
+	mu.Lock()
+	statuses[worker.Name] = &Status{Name: worker.Name, Running: true, Interval: worker.Interval.String(), interval: worker.Interval}
+	mu.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer setRunning(worker.Name, false)
+
+		ticker := time.NewTicker(worker.Interval)
+		defer ticker.Stop()
+
+		for {
+			runOnce(ctx, worker)
+
+			select {
+			case <-ctx.Done():
+				slog.Info("Arrêt de la tâche de fond", "worker", worker.Name)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Exécuter la tâche une fois et enregistrer son résultat
+func runOnce(ctx context.Context, worker Worker) {
+	err := safeRun(ctx, worker)
+
+	mu.Lock()
+	defer mu.Unlock()
+	status := statuses[worker.Name]
+	status.LastRunAt = time.Now()
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+		slog.Error("Erreur dans la tâche de fond", "worker", worker.Name, "error", err)
+	}
+}
+
+// Une panique dans une tâche de fond ne doit pas arrêter le serveur
+func safeRun(ctx context.Context, worker Worker) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panique : %v", recovered)
+		}
+	}()
+	return worker.Run(ctx)
+}
+
+/*
+Tâche vivante : toujours lancée et passée il y a moins de 3 intervalles
+(une tâche bloquée ne met plus à jour LastRunAt)
+*/
+func (s Status) Alive(now time.Time) bool {
+	if !s.Running {
+		return false
+	}
+	return s.LastRunAt.IsZero() || now.Sub(s.LastRunAt) < 3*s.interval
+}
+
+func setRunning(name string, running bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	statuses[name].Running = running
+}
+
+// Attendre l'arrêt de toutes les tâches de fond (ou l'expiration de `ctx`)
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// État de toutes les tâches de fond
+func Statuses() []Status {
+	mu.Lock()
+	defer mu.Unlock()
+
+	result := make([]Status, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	return result
+}